@@ -0,0 +1,183 @@
+// Package blob describes file contents which can't be reasonably reviewed as
+// text: binary files, Git LFS pointers and files exceeding a size limit.
+package blob
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	// Register decoders of common image formats for image.DecodeConfig().
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Kind describes how file contents should be reviewed.
+type Kind uint8
+
+const (
+	KindText Kind = iota
+	KindBinary
+	KindLFS
+	KindOversized
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindText:
+		return "text"
+	case KindBinary:
+		return "binary"
+	case KindLFS:
+		return "lfs"
+	case KindOversized:
+		return "oversized"
+	default:
+		return "<unknown>"
+	}
+}
+
+const (
+	// sniffLen is the length of the content prefix inspected to detect
+	// binary contents. It's the same as git uses.
+	sniffLen = 8000
+
+	// maxPointerLen is the maximum size of Git LFS pointer file.
+	maxPointerLen = 1024
+
+	lfsVersion = "version https://git-lfs.github.com/spec/v1"
+)
+
+// Pointer holds object reference stored in a Git LFS pointer file.
+type Pointer struct {
+	OID  string
+	Size int64
+}
+
+// Image holds format and dimensions of an image.
+type Image struct {
+	Format string
+	Width  int
+	Height int
+}
+
+// Info describes file contents.
+type Info struct {
+	Kind Kind
+	Size int64
+
+	// Fields below are set for non-text contents only.
+	SHA256  string
+	Pointer *Pointer
+	Image   *Image
+}
+
+// Stat describes contents of the file at given path. Text contents larger
+// than maxSize bytes are reported as oversized; zero maxSize means no limit.
+func Stat(path string, maxSize int64) (info Info, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return info, err
+	}
+	defer f.Close()
+
+	s, err := f.Stat()
+	if err != nil {
+		return info, err
+	}
+	info.Size = s.Size()
+
+	prefix := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, prefix)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return info, err
+	}
+	prefix = prefix[:n]
+
+	switch {
+	case info.Size <= maxPointerLen && isPointer(prefix):
+		p, err := ParsePointer(prefix)
+		if err != nil {
+			return info, err
+		}
+		info.Kind = KindLFS
+		info.Pointer = &p
+	case IsBinary(prefix):
+		info.Kind = KindBinary
+	case maxSize > 0 && info.Size > maxSize:
+		info.Kind = KindOversized
+	default:
+		return info, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return info, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return info, err
+	}
+	info.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	if info.Kind == KindBinary {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return info, err
+		}
+		c, format, err := image.DecodeConfig(bufio.NewReader(f))
+		if err == nil {
+			info.Image = &Image{
+				Format: format,
+				Width:  c.Width,
+				Height: c.Height,
+			}
+		}
+	}
+	return info, nil
+}
+
+// IsBinary reports whether src looks like a binary content. It uses the same
+// heuristics as git does: binary content has a zero byte within the first
+// few kilobytes.
+func IsBinary(src []byte) bool {
+	if len(src) > sniffLen {
+		src = src[:sniffLen]
+	}
+	return bytes.IndexByte(src, 0) != -1
+}
+
+func isPointer(src []byte) bool {
+	return bytes.HasPrefix(src, []byte(lfsVersion+"\n"))
+}
+
+// ParsePointer parses contents of a Git LFS pointer file.
+func ParsePointer(src []byte) (p Pointer, err error) {
+	if !isPointer(src) {
+		return p, fmt.Errorf("blob: not a git lfs pointer")
+	}
+	for _, line := range strings.Split(string(src), "\n") {
+		key, value := line, ""
+		if i := strings.IndexByte(line, ' '); i != -1 {
+			key, value = line[:i], line[i+1:]
+		}
+		switch key {
+		case "oid":
+			p.OID = value
+		case "size":
+			p.Size, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return p, fmt.Errorf("blob: malformed git lfs pointer size: %v", err)
+			}
+		}
+	}
+	if p.OID == "" {
+		return p, fmt.Errorf("blob: git lfs pointer has no oid")
+	}
+	return p, nil
+}
@@ -0,0 +1,209 @@
+package blob
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const pointer = "version https://git-lfs.github.com/spec/v1\n" +
+	"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+	"size 12345\n"
+
+func TestStat(t *testing.T) {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewGray(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name    string
+		content []byte
+		maxSize int64
+		exp     Info
+	}{
+		{
+			name:    "text",
+			content: []byte("hello\n"),
+			exp: Info{
+				Kind: KindText,
+				Size: 6,
+			},
+		},
+		{
+			name:    "binary",
+			content: []byte("\x00\x01"),
+			exp: Info{
+				Kind:   KindBinary,
+				Size:   2,
+				SHA256: "b413f47d13ee2fe6c845b2ee141af81de858df4ec549a58b7970bb96645bc8d2",
+			},
+		},
+		{
+			name:    "image",
+			content: img.Bytes(),
+			exp: Info{
+				Kind: KindBinary,
+				Size: int64(img.Len()),
+				Image: &Image{
+					Format: "png",
+					Width:  3,
+					Height: 2,
+				},
+			},
+		},
+		{
+			name:    "lfs",
+			content: []byte(pointer),
+			exp: Info{
+				Kind: KindLFS,
+				Size: int64(len(pointer)),
+				Pointer: &Pointer{
+					OID:  "sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+					Size: 12345,
+				},
+			},
+		},
+		{
+			name:    "oversized",
+			content: []byte("hello\n"),
+			maxSize: 5,
+			exp: Info{
+				Kind:   KindOversized,
+				Size:   6,
+				SHA256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := ioutil.WriteFile(path, test.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			act, err := Stat(path, test.maxSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.exp.Image != nil || test.exp.Pointer != nil {
+				// Hash is not interesting here.
+				test.exp.SHA256 = act.SHA256
+			}
+			if !cmp.Equal(act, test.exp) {
+				t.Errorf("unexpected info:\n%s", cmp.Diff(test.exp, act))
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		base, head *Info
+		exp        []string
+	}{
+		{
+			name: "binary changed",
+			base: &Info{
+				Kind:   KindBinary,
+				Size:   2048,
+				SHA256: "b413f47d13ee2fe6c845b2ee141af81de858df4ec549a58b7970bb96645bc8d2",
+				Image:  &Image{"png", 3, 2},
+			},
+			head: &Info{
+				Kind:   KindBinary,
+				Size:   2048,
+				SHA256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+				Image:  &Image{"png", 4, 2},
+			},
+			exp: []string{
+				"Binary file is not shown as text.",
+				"  size:   2.0 KiB",
+				"  sha256: b413f47d13ee → 5891b5b522d5",
+				"  image:  png 3x2 → png 4x2",
+			},
+		},
+		{
+			name: "lfs added",
+			head: &Info{
+				Kind: KindLFS,
+				Size: 130,
+				Pointer: &Pointer{
+					OID:  "sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+					Size: 12345,
+				},
+			},
+			exp: []string{
+				"Git LFS object is not shown as text.",
+				"  size:     none → 130 B",
+				"  lfs oid:  none → sha256:4d7a214614ab",
+				"  lfs size: none → 12.1 KiB",
+			},
+		},
+		{
+			name: "became binary",
+			base: &Info{
+				Kind: KindText,
+				Size: 10,
+			},
+			head: &Info{
+				Kind:   KindBinary,
+				Size:   2,
+				SHA256: "b413f47d13ee2fe6c845b2ee141af81de858df4ec549a58b7970bb96645bc8d2",
+			},
+			exp: []string{
+				"Binary file is not shown as text.",
+				"  size:   10 B → 2 B",
+				"  sha256: - → b413f47d13ee",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			Summary(&buf, test.base, test.head)
+			act := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if !cmp.Equal(act, test.exp) {
+				t.Errorf("unexpected summary:\n%s", cmp.Diff(test.exp, act))
+			}
+		})
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		src  string
+		err  bool
+	}{
+		{
+			name: "valid",
+			src:  pointer,
+		},
+		{
+			name: "no version",
+			src:  "oid sha256:abc\nsize 1\n",
+			err:  true,
+		},
+		{
+			name: "no oid",
+			src:  "version https://git-lfs.github.com/spec/v1\nsize 1\n",
+			err:  true,
+		},
+		{
+			name: "bad size",
+			src:  "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize x\n",
+			err:  true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParsePointer([]byte(test.src))
+			if test.err != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
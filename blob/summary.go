@@ -0,0 +1,107 @@
+package blob
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Summary writes description of changes between base and head contents
+// instead of a textual diff. Nil info means that the version doesn't exist.
+func Summary(w io.Writer, base, head *Info) {
+	kind := KindText
+	for _, info := range []*Info{head, base} {
+		if info != nil && info.Kind != KindText {
+			kind = info.Kind
+			break
+		}
+	}
+	switch kind {
+	case KindLFS:
+		fmt.Fprintln(w, "Git LFS object is not shown as text.")
+	case KindOversized:
+		fmt.Fprintln(w, "File exceeds the size limit and is not shown as text.")
+	default:
+		fmt.Fprintln(w, "Binary file is not shown as text.")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	row := func(name string, fn func(*Info) string) {
+		a, okA := value(base, fn)
+		b, okB := value(head, fn)
+		if !okA && !okB {
+			return
+		}
+		if a != b {
+			a += " → " + b
+		}
+		fmt.Fprintf(tw, "  %s:\t%s\n", name, a)
+	}
+	row("size", func(x *Info) string {
+		return FormatSize(x.Size)
+	})
+	row("sha256", func(x *Info) string {
+		return short(x.SHA256)
+	})
+	row("image", func(x *Info) string {
+		if x.Image == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s %dx%d", x.Image.Format, x.Image.Width, x.Image.Height)
+	})
+	row("lfs oid", func(x *Info) string {
+		if x.Pointer == nil {
+			return ""
+		}
+		return short(x.Pointer.OID)
+	})
+	row("lfs size", func(x *Info) string {
+		if x.Pointer == nil {
+			return ""
+		}
+		return FormatSize(x.Pointer.Size)
+	})
+	tw.Flush()
+}
+
+// value returns field value of info or "none" if the version doesn't exist.
+// It returns false if the field is not set.
+func value(info *Info, fn func(*Info) string) (string, bool) {
+	if info == nil {
+		return "none", false
+	}
+	if s := fn(info); s != "" {
+		return s, true
+	}
+	return "-", false
+}
+
+// short shortens hash keeping its algorithm prefix, if any.
+func short(s string) string {
+	const n = 12
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' {
+			return s[:i+1] + short(s[i+1:])
+		}
+	}
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// FormatSize returns human readable size of n bytes.
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	var (
+		x   = float64(n)
+		exp int
+	)
+	for x >= unit && exp < 4 {
+		x /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", x, "KMGT"[exp-1])
+}
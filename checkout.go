@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/gobwas/rw/color"
 	"github.com/gobwas/rw/vcs"
 )
 
@@ -22,7 +23,9 @@ func checkoutFileLine(name string, line int) file {
 	return file{name: name, line: line}
 }
 
-func (r *Review) checkout(ctx context.Context, review vcs.Review, editFiles ...file) (err error) {
+// checkout opens changed files in an editor within the checkout of the review.
+// Files which are opened or summarized are marked as reviewed in prog, if any.
+func (r *Review) checkout(ctx context.Context, review vcs.Review, prog *progress, editFiles ...file) (err error) {
 	headDir, cleanup, err := review.Checkout(ctx)
 	if err != nil {
 		return err
@@ -47,22 +50,28 @@ func (r *Review) checkout(ctx context.Context, review vcs.Review, editFiles ...f
 	if err != nil {
 		return err
 	}
-	headToBase := make(map[string]string)
+	// Paths of head and base versions of changed files by their names.
+	var (
+		changed    = make(map[string]vcs.ChangedFile)
+		headFiles  = make(map[string]string)
+		headToBase = make(map[string]string)
+	)
 	for _, file := range changedFiles {
-		log.Printf("processing base file for %q", file)
-		base, err := review.BaseFile(ctx, file)
+		log.Printf("processing base file for %q", file.Name)
+		base, err := baseFile(ctx, review, file)
 		if err != nil {
 			return err
 		}
-		roBase, err := tmp.createFile(base, "base", file, 0444)
+		// Base version of renamed file is placed under its head name to be
+		// paired with the head version.
+		roBase, err := tmp.createFile(base, "base", file.Name, 0444)
 		if err != nil {
 			return err
 		}
-		headFile := filepath.Join(headDir, file)
-		// Need to fixup deleted files here.
-		// FIXME: filepath.Join; review.SourceFile(ctx, file)?
-		if _, err := os.Stat(headFile); os.IsNotExist(err) {
-			// Need to restore full dir path.
+		headFile := filepath.Join(headDir, file.Name)
+		if file.Status == vcs.FileStatusDeleted {
+			// Deleted file doesn't exist in the checkout; give an editor an
+			// empty file to compare base version with.
 			if err := os.MkdirAll(filepath.Dir(headFile), 0755); err != nil {
 				return err
 			}
@@ -74,6 +83,8 @@ func (r *Review) checkout(ctx context.Context, review vcs.Review, editFiles ...f
 			f.Close()
 			log.Printf("touched file %s", f.Name())
 		}
+		changed[file.Name] = file
+		headFiles[file.Name] = headFile
 		//if r.Comments {
 		//	head, err := review.HeadFile(ctx, file)
 		//	if err != nil {
@@ -143,7 +154,7 @@ func (r *Review) checkout(ctx context.Context, review vcs.Review, editFiles ...f
 
 	//var cmds []string
 	for _, file := range editFiles {
-		headFile := filepath.Join(headDir, file.name)
+		headFile := headFiles[file.name]
 		baseFile := headToBase[headFile]
 		if baseFile == "" {
 			return fmt.Errorf(
@@ -151,7 +162,22 @@ func (r *Review) checkout(ctx context.Context, review vcs.Review, editFiles ...f
 				file,
 			)
 		}
-		err := r.launchEditor(ctx, reviewInfo{
+		summary := r.sizeSummary(changed[file.name])
+		if summary == nil {
+			summary, err = r.fileSummary(changed[file.name], baseFile, headFile)
+			if err != nil {
+				return err
+			}
+		}
+		if summary != nil {
+			color.Fprintf(os.Stdout, color.White, "%s\n", fileTitle(changed[file.name]))
+			os.Stdout.Write(summary)
+			if err := prog.MarkFile(ctx, file.name, true); err != nil {
+				return err
+			}
+			continue
+		}
+		err = r.launchEditor(ctx, reviewInfo{
 			HeadDir: headDir,
 			BaseDir: baseDir,
 
@@ -168,6 +194,9 @@ func (r *Review) checkout(ctx context.Context, review vcs.Review, editFiles ...f
 		if err != nil {
 			return err
 		}
+		if err := prog.MarkFile(ctx, file.name, true); err != nil {
+			return err
+		}
 	}
 	//return launch(ctx, "nvim", append([]string{
 	//	"-c", fmt.Sprintf("cd %s", headDir),
@@ -208,19 +237,14 @@ func (r *Review) checkout(ctx context.Context, review vcs.Review, editFiles ...f
 	return nil
 }
 
-func (r *Review) reviewCheckout(ctx context.Context, review vcs.Review) (err error) {
-	files, err := review.ChangedFiles(ctx)
-	if err != nil {
-		return err
-	}
-	xs, err := r.selectMultiple(ctx, "Pick files to review:", files)
+func (r *Review) reviewCheckout(ctx context.Context, review vcs.Review, prog *progress) (err error) {
+	files, err := r.pickFiles(ctx, review, prog)
 	if err != nil {
 		return err
 	}
-	files = pick(files, xs...)
 	var fs []file
-	for _, name := range files {
-		fs = append(fs, checkoutFile(name))
+	for _, f := range files {
+		fs = append(fs, checkoutFile(f.Name))
 	}
-	return r.checkout(ctx, review, fs...)
+	return r.checkout(ctx, review, prog, fs...)
 }
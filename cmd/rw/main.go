@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
@@ -19,7 +20,13 @@ import (
 	"github.com/gobwas/flagutil/parse/file/yaml"
 	"github.com/gobwas/flagutil/parse/pargs"
 	"github.com/gobwas/rw"
+	"github.com/gobwas/rw/gerrit"
+	"github.com/gobwas/rw/gitea"
 	"github.com/gobwas/rw/github"
+	"github.com/gobwas/rw/gitlab"
+	"github.com/gobwas/rw/local"
+	"github.com/gobwas/rw/mbox"
+	"github.com/gobwas/rw/vcs"
 )
 
 var _ interface {
@@ -31,10 +38,16 @@ type command struct {
 	cacheDir string
 	project  string
 	branch   string
+	provider string
 	commits  bool
 	debug    bool
 
 	github github.Client
+	gitlab gitlab.Client
+	gitea  gitea.Client
+	gerrit gerrit.Client
+	local  local.Client
+	mbox   mbox.Client
 	review rw.Review
 }
 
@@ -47,6 +60,10 @@ func (c *command) DefineFlags(fs *flag.FlagSet) {
 		"project", "",
 		"project to lookup review in",
 	)
+	fs.StringVar(&c.provider,
+		"provider", "github",
+		"vcs provider to use: github, gitlab, gitea, gerrit, local or mbox",
+	)
 	fs.BoolVar(&c.commits,
 		"commits", false,
 		"review commits instead of pull-requests",
@@ -71,6 +88,21 @@ func (c *command) DefineFlags(fs *flag.FlagSet) {
 	flagutil.Subset(fs, "github", func(fs *flag.FlagSet) {
 		github.DefineFlags(&c.github, fs)
 	})
+	flagutil.Subset(fs, "gitlab", func(fs *flag.FlagSet) {
+		gitlab.DefineFlags(&c.gitlab, fs)
+	})
+	flagutil.Subset(fs, "gitea", func(fs *flag.FlagSet) {
+		gitea.DefineFlags(&c.gitea, fs)
+	})
+	flagutil.Subset(fs, "gerrit", func(fs *flag.FlagSet) {
+		gerrit.DefineFlags(&c.gerrit, fs)
+	})
+	flagutil.Subset(fs, "local", func(fs *flag.FlagSet) {
+		local.DefineFlags(&c.local, fs)
+	})
+	flagutil.Subset(fs, "mbox", func(fs *flag.FlagSet) {
+		mbox.DefineFlags(&c.mbox, fs)
+	})
 }
 
 func (c *command) Run(ctx context.Context, args []string) error {
@@ -79,15 +111,12 @@ func (c *command) Run(ctx context.Context, args []string) error {
 		log.SetOutput(ioutil.Discard)
 	}
 
-	c.github.Project = c.project
-	c.github.Commits = c.commits
-	c.github.Branch = c.branch
-	c.github.CacheDir = c.cacheDir
-	if err := c.github.Init(ctx); err != nil {
+	provider, err := c.initProvider(ctx)
+	if err != nil {
 		return err
 	}
-
-	c.review.Provider = &c.github
+	c.review.Provider = provider
+	c.review.CacheDir = c.cacheDir
 	if err := c.review.Start(ctx); err != nil {
 		return err
 	}
@@ -95,6 +124,79 @@ func (c *command) Run(ctx context.Context, args []string) error {
 	return nil
 }
 
+func (c *command) initProvider(ctx context.Context) (vcs.Provider, error) {
+	switch c.provider {
+	case "github":
+		c.github.Project = c.project
+		c.github.Commits = c.commits
+		c.github.Branch = c.branch
+		c.github.CacheDir = c.cacheDir
+		if err := c.github.Init(ctx); err != nil {
+			return nil, err
+		}
+		return &c.github, nil
+
+	case "gitlab":
+		if c.commits {
+			return nil, fmt.Errorf("gitlab: reviewing commits is not supported")
+		}
+		c.gitlab.Project = c.project
+		c.gitlab.Branch = c.branch
+		c.gitlab.CacheDir = c.cacheDir
+		if err := c.gitlab.Init(ctx); err != nil {
+			return nil, err
+		}
+		return &c.gitlab, nil
+
+	case "gitea":
+		if c.commits {
+			return nil, fmt.Errorf("gitea: reviewing commits is not supported")
+		}
+		c.gitea.Project = c.project
+		c.gitea.Branch = c.branch
+		c.gitea.CacheDir = c.cacheDir
+		if err := c.gitea.Init(ctx); err != nil {
+			return nil, err
+		}
+		return &c.gitea, nil
+
+	case "gerrit":
+		if c.commits {
+			return nil, fmt.Errorf("gerrit: reviewing commits is not supported")
+		}
+		c.gerrit.Project = c.project
+		c.gerrit.Branch = c.branch
+		c.gerrit.CacheDir = c.cacheDir
+		if err := c.gerrit.Init(ctx); err != nil {
+			return nil, err
+		}
+		return &c.gerrit, nil
+
+	case "local":
+		c.local.Commits = c.commits
+		c.local.Branch = c.branch
+		if err := c.local.Init(ctx); err != nil {
+			return nil, err
+		}
+		return &c.local, nil
+
+	case "mbox":
+		if c.commits {
+			return nil, fmt.Errorf("mbox: reviewing commits is not supported")
+		}
+		c.mbox.Project = c.project
+		c.mbox.Branch = c.branch
+		c.mbox.CacheDir = c.cacheDir
+		if err := c.mbox.Init(ctx); err != nil {
+			return nil, err
+		}
+		return &c.mbox, nil
+
+	default:
+		return nil, fmt.Errorf("unknown vcs provider: %q", c.provider)
+	}
+}
+
 func main() {
 	rand.Seed(time.Now().UnixNano())
 
@@ -71,10 +71,83 @@ var colors = [...][]byte{
 	Yellow:  []byte("\u001b[33;1m"),
 	Blue:    []byte("\u001b[34;1m"),
 	Magenta: []byte("\u001b[35;1m"),
-	Cyan:    []byte("\u001b[35;1m"),
+	Cyan:    []byte("\u001b[36;1m"),
 	White:   []byte("\u001b[37;1m"),
 }
 
+var names = [...]string{
+	Black:   "black",
+	Red:     "red",
+	Green:   "green",
+	Yellow:  "yellow",
+	Blue:    "blue",
+	Magenta: "magenta",
+	Cyan:    "cyan",
+	White:   "white",
+	Grey:    "grey",
+}
+
+// Set implements flag.Value interface. It makes it possible to specify colors
+// by their names in flags or configuration files. Empty string and "none"
+// reset color to Unknown.
+func (c *Color) Set(s string) error {
+	switch s {
+	case "", "none":
+		*c = Unknown
+		return nil
+	case "gray":
+		*c = Grey
+		return nil
+	}
+	for i, name := range names {
+		if name != "" && name == s {
+			*c = Color(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown color: %q", s)
+}
+
+func (c Color) String() string {
+	if int(c) < len(names) && names[c] != "" {
+		return names[c]
+	}
+	return "none"
+}
+
+var backgrounds = [...][]byte{
+	0:       []byte("\u001b[49m"),
+	Grey:    []byte("\u001b[48;5;238m"),
+	Black:   []byte("\u001b[40m"),
+	Red:     []byte("\u001b[41m"),
+	Green:   []byte("\u001b[42m"),
+	Yellow:  []byte("\u001b[43m"),
+	Blue:    []byte("\u001b[44m"),
+	Magenta: []byte("\u001b[45m"),
+	Cyan:    []byte("\u001b[46m"),
+	White:   []byte("\u001b[47m"),
+}
+
+// Attribute represents text attributes which may be combined together and
+// with foreground and background colors.
+type Attribute uint8
+
+const (
+	Bold Attribute = 1 << iota
+	Underline
+	Reverse
+)
+
+var attributes = [...]struct {
+	attr  Attribute
+	set   string
+	reset string
+}{
+	{Bold, "1", "22"},
+	{Underline, "4", "24"},
+	{Reverse, "7", "27"},
+}
+
 func Fbegin(w io.Writer, color Color) (int, error) {
 	return w.Write(colors[color])
 }
@@ -82,6 +155,41 @@ func Freset(w io.Writer) (int, error) {
 	return w.Write(colors[0])
 }
 
+// Fbackground sets background color. Unlike Freset(), FresetBackground()
+// resets only the background leaving foreground color and attributes as is.
+func Fbackground(w io.Writer, color Color) (int, error) {
+	return w.Write(backgrounds[color])
+}
+func FresetBackground(w io.Writer) (int, error) {
+	return w.Write(backgrounds[0])
+}
+
+// Fattr enables given attributes. FresetAttr() disables them leaving colors
+// as is. That is, it is safe to emphasize some part of colored text.
+func Fattr(w io.Writer, a Attribute) (int, error) {
+	return w.Write(sgr(a, func(i int) string {
+		return attributes[i].set
+	}))
+}
+func FresetAttr(w io.Writer, a Attribute) (int, error) {
+	return w.Write(sgr(a, func(i int) string {
+		return attributes[i].reset
+	}))
+}
+
+func sgr(a Attribute, code func(int) string) []byte {
+	var codes []string
+	for i, x := range attributes {
+		if a&x.attr != 0 {
+			codes = append(codes, code(i))
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return []byte("\u001b[" + strings.Join(codes, ";") + "m")
+}
+
 func Sprint(color Color, args ...interface{}) string {
 	var sb strings.Builder
 	Fbegin(&sb, color)
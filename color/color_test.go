@@ -15,6 +15,23 @@ func TestFilter(t *testing.T) {
 			in:  []byte(Sprint(Grey, "hello")),
 			exp: []byte("hello"),
 		},
+		{
+			name: "attributes",
+			in: func() []byte {
+				var buf bytes.Buffer
+				Fbegin(&buf, Red)
+				buf.WriteString("a ")
+				Fattr(&buf, Bold|Reverse)
+				buf.WriteString("b")
+				FresetAttr(&buf, Bold|Reverse)
+				Fbackground(&buf, Grey)
+				buf.WriteString(" c")
+				FresetBackground(&buf)
+				Freset(&buf)
+				return buf.Bytes()
+			}(),
+			exp: []byte("a b c"),
+		},
 	} {
 		t.Run(test.name+"/bytes", func(t *testing.T) {
 			act := Filter(test.in)
@@ -38,3 +55,27 @@ func TestFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestColorSet(t *testing.T) {
+	for _, test := range []struct {
+		in  string
+		exp Color
+		err bool
+	}{
+		{in: "cyan", exp: Cyan},
+		{in: "gray", exp: Grey},
+		{in: "none", exp: Unknown},
+		{in: "purple", err: true},
+	} {
+		t.Run(test.in, func(t *testing.T) {
+			var act Color
+			err := act.Set(test.in)
+			if test.err != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if act != test.exp {
+				t.Fatalf("unexpected color: %s; want %s", act, test.exp)
+			}
+		})
+	}
+}
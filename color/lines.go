@@ -0,0 +1,46 @@
+package color
+
+import "bytes"
+
+// SplitLines splits p into lines. Color sequences which are active at the
+// end of a line are reset and then restored at the beginning of the next
+// line. That is, lines may be printed separately without colors leaking.
+func SplitLines(p []byte) (lines [][]byte) {
+	var (
+		state []byte
+		reset bytes.Buffer
+	)
+	Freset(&reset)
+	for len(p) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(p, '\n'); i != -1 {
+			line, p = p[:i], p[i+1:]
+		} else {
+			line, p = p, nil
+		}
+
+		ret := append(append(([]byte)(nil), state...), line...)
+		for rest := line; len(rest) > 0; {
+			i := bytes.IndexByte(rest, '\x1b')
+			if i == -1 {
+				break
+			}
+			j := bytes.IndexByte(rest[i:], 'm')
+			if j == -1 {
+				break
+			}
+			seq := rest[i : i+j+1]
+			if bytes.Equal(seq, reset.Bytes()) {
+				state = nil
+			} else {
+				state = append(state, seq...)
+			}
+			rest = rest[i+j+1:]
+		}
+		if len(state) > 0 {
+			ret = append(ret, reset.Bytes()...)
+		}
+		lines = append(lines, ret)
+	}
+	return lines
+}
@@ -0,0 +1,28 @@
+package color
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	var buf bytes.Buffer
+	Fbegin(&buf, Red)
+	buf.WriteString("foo\nbar")
+	Freset(&buf)
+	buf.WriteString("\nbaz\n")
+
+	lines := SplitLines(buf.Bytes())
+	if n := len(lines); n != 3 {
+		t.Fatalf("unexpected number of lines: %d; want 3", n)
+	}
+	for i, exp := range []string{
+		Sprint(Red, "foo"),
+		Sprint(Red, "bar"),
+		"baz",
+	} {
+		if act := string(lines[i]); act != exp {
+			t.Errorf("unexpected #%d line: %q; want %q", i, act, exp)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package rw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/vcs"
+)
+
+// errQuit is returned by review modes when user asks to quit immediately.
+var errQuit = errors.New("quit")
+
+// reviewCommits asks which commits of the review to review and reviews them
+// one by one. Pending review is finished once all commits are reviewed.
+func (r *Review) reviewCommits(ctx context.Context, review vcs.Review) error {
+	cl, ok := review.(vcs.CommitLister)
+	if !ok {
+		return fmt.Errorf("review of commits is not supported for %s", review)
+	}
+	commits, err := cl.Commits(ctx)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		fmt.Println("No commits to review.")
+		return nil
+	}
+	opts := make([]string, len(commits))
+	for i, c := range commits {
+		opts[i] = c.String()
+	}
+	xs, err := r.selectMultiple(ctx, "Pick commits to review:", opts)
+	if err != nil {
+		return err
+	}
+	for _, x := range xs {
+		c := commits[x]
+		fmt.Printf("Reviewing commit %s.\n", color.Sprint(color.White, c.String()))
+		err := r.review(ctx, c)
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if pr, ok := review.(vcs.PendingReview); ok {
+		return r.finishReview(ctx, pr)
+	}
+	return nil
+}
@@ -8,26 +8,31 @@ import (
 	"log"
 	"os"
 
+	"github.com/gobwas/rw/color"
 	"github.com/gobwas/rw/ed"
 	"github.com/gobwas/rw/vcs"
 )
 
-func (r *Review) reviewDiff(ctx context.Context, review vcs.Review) error {
-	files, err := review.ChangedFiles(ctx)
+func (r *Review) reviewDiff(ctx context.Context, review vcs.Review, prog *progress) error {
+	files, err := r.pickFiles(ctx, review, prog)
 	if err != nil {
 		return err
 	}
-	xs, err := r.selectMultiple(ctx, "Pick files to review:", files)
-	if err != nil {
-		return err
-	}
-	files = pick(files, xs...)
 
 	tmp := temp{
 		name: "rw",
 	}
-	for _, file := range files {
-		baseSrc, err := review.BaseFile(ctx, file)
+	for _, f := range files {
+		file := f.Name
+		if summary := r.sizeSummary(f); summary != nil {
+			color.Fprintf(os.Stdout, color.White, "%s\n", fileTitle(f))
+			os.Stdout.Write(summary)
+			if err := prog.MarkFile(ctx, file, true); err != nil {
+				return err
+			}
+			continue
+		}
+		baseSrc, err := baseFile(ctx, review, f)
 		if err != nil {
 			return err
 		}
@@ -36,7 +41,7 @@ func (r *Review) reviewDiff(ctx context.Context, review vcs.Review) error {
 			return err
 		}
 
-		headSrc, err := review.HeadFile(ctx, file)
+		headSrc, err := headFile(ctx, review, f)
 		if err != nil {
 			return err
 		}
@@ -82,6 +87,19 @@ func (r *Review) reviewDiff(ctx context.Context, review vcs.Review) error {
 			}
 		}
 
+		summary, err := r.fileSummary(f, roBase.Name(), roHead.Name())
+		if err != nil {
+			return err
+		}
+		if summary != nil {
+			color.Fprintf(os.Stdout, color.White, "%s\n", fileTitle(f))
+			os.Stdout.Write(summary)
+			if err := prog.MarkFile(ctx, file, true); err != nil {
+				return err
+			}
+			continue
+		}
+
 		//err = launch(ctx, "code", "--wait", "--diff", headFileEdit, baseFile)
 		err = r.launchEditor(ctx, reviewInfo{
 			HeadFile: fileInfo{Name: rwHead.Name()},
@@ -95,7 +113,7 @@ func (r *Review) reviewDiff(ctx context.Context, review vcs.Review) error {
 		// after single comments block. In that case will be produced two edits
 		// with same line range. For now it's okay, but maybe it might be glued.
 		var edits []ed.Command
-		err = diff(ctx, roHead.Name(), rwHead.Name(), func(cmd ed.Command) {
+		err = r.diff(ctx, roHead.Name(), rwHead.Name(), func(cmd ed.Command) {
 			if comments != nil {
 				applyEdit(comments, cmd, appendEditFunc(&edits))
 			} else {
@@ -116,6 +134,9 @@ func (r *Review) reviewDiff(ctx context.Context, review vcs.Review) error {
 				return err
 			}
 		}
+		if err := prog.MarkFile(ctx, file, true); err != nil {
+			return err
+		}
 	}
 	return nil
 }
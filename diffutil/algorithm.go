@@ -0,0 +1,269 @@
+package diffutil
+
+import "sort"
+
+// myers implements the Myers O(ND) difference algorithm using linear space:
+// it finds the middle snake of the shortest edit path and recurses into the
+// regions before and after it.
+func myers(a, b []int) []Match {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	// Middle snake is found in at most (n+m+1)/2 steps; each step reads
+	// diagonals adjacent to the ones it writes.
+	off := (len(a)+len(b)+1)/2 + 1
+	s := &myersState{
+		vf:  make([]int, 2*off+1),
+		vb:  make([]int, 2*off+1),
+		off: off,
+	}
+	s.compare(a, b, 0, 0)
+	return s.ms
+}
+
+// myersState holds buffers shared between recursive calls of myers().
+type myersState struct {
+	vf  []int // Furthest x of forward paths indexed by diagonal.
+	vb  []int // Furthest x of reverse paths indexed by diagonal.
+	off int   // Index of zero diagonal.
+	ms  []Match
+}
+
+// compare appends blocks of equal elements of a and b to s.ms. Offsets ao
+// and bo are positions of a and b within the original sequences.
+func (s *myersState) compare(a, b []int, ao, bo int) {
+	var pre, suf int
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	if pre > 0 {
+		s.ms = append(s.ms, Match{ao, bo, pre})
+	}
+	a, b = a[pre:], b[pre:]
+	ao, bo = ao+pre, bo+pre
+	for suf < len(a) && suf < len(b) && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	a, b = a[:len(a)-suf], b[:len(b)-suf]
+	if len(a) > 0 && len(b) > 0 {
+		// Sequences differ at both ends here, so edit distance is at least
+		// two and both regions around the middle snake are smaller.
+		x, y, u, v := s.middleSnake(a, b)
+		s.compare(a[:x], b[:y], ao, bo)
+		if u > x {
+			s.ms = append(s.ms, Match{ao + x, bo + y, u - x})
+		}
+		s.compare(a[u:], b[v:], ao+u, bo+v)
+	}
+	if suf > 0 {
+		s.ms = append(s.ms, Match{ao + len(a), bo + len(b), suf})
+	}
+}
+
+// middleSnake returns start (x, y) and end (u, v) of the middle snake of the
+// shortest edit path transforming a into b. Forward and reverse paths are
+// searched simultaneously until they overlap. Reverse paths are searched on
+// reversed sequences, so their diagonal c corresponds to diagonal n-m-c of
+// forward paths.
+func (s *myersState) middleSnake(a, b []int) (x, y, u, v int) {
+	var (
+		n     = len(a)
+		m     = len(b)
+		delta = n - m
+		odd   = delta&1 != 0
+		off   = s.off
+		vf    = s.vf
+		vb    = s.vb
+	)
+	vf[off+1] = 0
+	vb[off+1] = 0
+	for d := 0; ; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < n && v < m && a[u] == b[v] {
+				u++
+				v++
+			}
+			vf[off+k] = u
+			if c := delta - k; odd && -(d-1) <= c && c <= d-1 && u+vb[off+c] >= n {
+				return x, y, u, v
+			}
+		}
+		for c := -d; c <= d; c += 2 {
+			var p int
+			if c == -d || (c != d && vb[off+c-1] < vb[off+c+1]) {
+				p = vb[off+c+1]
+			} else {
+				p = vb[off+c-1] + 1
+			}
+			var (
+				q  = p - c
+				sp = p
+				sq = q
+			)
+			for p < n && q < m && a[n-1-p] == b[m-1-q] {
+				p++
+				q++
+			}
+			vb[off+c] = p
+			if k := delta - c; !odd && -d <= k && k <= d && vf[off+k]+p >= n {
+				return n - p, m - q, n - sp, m - sq
+			}
+		}
+	}
+}
+
+// patience implements the patience diff algorithm. It matches lines which
+// are unique in both sequences and recurses into the gaps between them.
+// It falls back to myers() if there are no unique common lines.
+func patience(a, b []int) (ms []Match) {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	type occurrence struct {
+		a, b   int
+		na, nb int
+	}
+	occ := make(map[int]*occurrence)
+	for i, x := range a {
+		o := occ[x]
+		if o == nil {
+			o = new(occurrence)
+			occ[x] = o
+		}
+		o.a = i
+		o.na++
+	}
+	var pairs []Match
+	for j, y := range b {
+		if o := occ[y]; o != nil {
+			o.b = j
+			o.nb++
+		}
+	}
+	for j, y := range b {
+		if o := occ[y]; o != nil && o.na == 1 && o.nb == 1 {
+			pairs = append(pairs, Match{o.a, j, 1})
+		}
+	}
+	if len(pairs) == 0 {
+		return myers(a, b)
+	}
+	// Pairs are ordered by b; select the longest subsequence increasing by a
+	// and recurse into the gaps between selected pairs.
+	var pa, pb int
+	for _, p := range lis(pairs) {
+		ms = appendShifted(ms, patience(a[pa:p.A], b[pb:p.B]), pa, pb)
+		ms = append(ms, p)
+		pa, pb = p.A+1, p.B+1
+	}
+	return appendShifted(ms, patience(a[pa:], b[pb:]), pa, pb)
+}
+
+// lis returns longest subsequence of ms increasing by A using patience
+// sorting.
+func lis(ms []Match) []Match {
+	var (
+		tops []int // Indexes of ms at the top of each pile.
+		back = make([]int, len(ms))
+	)
+	for i, m := range ms {
+		j := sort.Search(len(tops), func(j int) bool {
+			return ms[tops[j]].A > m.A
+		})
+		if j > 0 {
+			back[i] = tops[j-1]
+		} else {
+			back[i] = -1
+		}
+		if j == len(tops) {
+			tops = append(tops, i)
+		} else {
+			tops[j] = i
+		}
+	}
+	ret := make([]Match, len(tops))
+	for i, j := len(tops)-1, tops[len(tops)-1]; i >= 0; i, j = i-1, back[j] {
+		ret[i] = ms[j]
+	}
+	return ret
+}
+
+// maxChain is the maximum number of occurrences of a line to be considered
+// by histogram() as a match candidate.
+const maxChain = 64
+
+// histogram implements the histogram diff algorithm. It finds the longest
+// common block containing the least frequent line and recurses into the
+// regions before and after it. It falls back to myers() if all common lines
+// are too frequent.
+func histogram(a, b []int) (ms []Match) {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	occ := make(map[int][]int)
+	for i, x := range a {
+		occ[x] = append(occ[x], i)
+	}
+	var (
+		best      Match
+		bestCount = maxChain + 1
+		common    bool
+	)
+	for j := 0; j < len(b); {
+		ps := occ[b[j]]
+		if len(ps) == 0 {
+			j++
+			continue
+		}
+		common = true
+		if len(ps) > bestCount {
+			j++
+			continue
+		}
+		next := j + 1
+		for _, i := range ps {
+			s, t := i, j
+			for s > 0 && t > 0 && a[s-1] == b[t-1] {
+				s--
+				t--
+			}
+			e, f := i+1, j+1
+			for e < len(a) && f < len(b) && a[e] == b[f] {
+				e++
+				f++
+			}
+			var (
+				n = e - s
+				c = len(ps)
+			)
+			if c < bestCount || (c == bestCount && n > best.N) {
+				best = Match{s, t, n}
+				bestCount = c
+			}
+			if f > next {
+				next = f
+			}
+		}
+		j = next
+	}
+	if best.N == 0 {
+		if common {
+			return myers(a, b)
+		}
+		return nil
+	}
+	ms = appendShifted(ms, histogram(a[:best.A], b[:best.B]), 0, 0)
+	ms = append(ms, best)
+	var (
+		ae = best.A + best.N
+		be = best.B + best.N
+	)
+	return appendShifted(ms, histogram(a[ae:], b[be:]), ae, be)
+}
@@ -0,0 +1,226 @@
+// Package diffutil implements line based diff algorithms producing ed
+// commands.
+package diffutil
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gobwas/rw/ed"
+)
+
+type Algorithm uint8
+
+const (
+	Myers Algorithm = iota
+	Patience
+	Histogram
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case Myers:
+		return "myers"
+	case Patience:
+		return "patience"
+	case Histogram:
+		return "histogram"
+	default:
+		return "<unknown>"
+	}
+}
+
+// Ed computes difference between a and b and calls fn with each ed command
+// needed to transform a into b. Commands are passed in the same order as
+// `diff --ed` prints them, that is, from the end of a to its beginning.
+//
+// It returns error if difference can't be computed due to an internal
+// failure. No commands are passed to fn in that case.
+func Ed(a, b []byte, alg Algorithm, fn func(ed.Command)) error {
+	var (
+		x = Lines(a)
+		y = Lines(b)
+	)
+	ms, err := matches(x, y, alg)
+	if err != nil {
+		return err
+	}
+	for i := len(ms) - 1; i > 0; i-- {
+		var (
+			prev = ms[i-1]
+			next = ms[i]
+
+			aLo = prev.A + prev.N
+			aHi = next.A
+			bLo = prev.B + prev.N
+			bHi = next.B
+		)
+		switch {
+		case aLo == aHi && bLo == bHi:
+			continue
+		case aLo == aHi:
+			fn(ed.Command{
+				Start: aLo,
+				End:   aLo,
+				Mode:  ed.ModeAdd,
+				Text:  join(y[bLo:bHi]),
+			})
+		case bLo == bHi:
+			fn(ed.Command{
+				Start: aLo + 1,
+				End:   aHi,
+				Mode:  ed.ModeDelete,
+			})
+		default:
+			fn(ed.Command{
+				Start: aLo + 1,
+				End:   aHi,
+				Mode:  ed.ModeChange,
+				Text:  join(y[bLo:bHi]),
+			})
+		}
+	}
+	return nil
+}
+
+// matches is like Matches() but returns error instead of panicking if any
+// of the algorithms fails.
+func matches(a, b [][]byte, alg Algorithm) (ms []Match, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("diffutil: %s failed: %v", alg, p)
+		}
+	}()
+	return Matches(a, b, alg), nil
+}
+
+// Lines splits text into lines. Each line keeps its trailing newline.
+func Lines(text []byte) (lines [][]byte) {
+	for len(text) > 0 {
+		i := bytes.IndexByte(text, '\n')
+		if i == -1 {
+			i = len(text) - 1
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+	return lines
+}
+
+func join(lines [][]byte) []byte {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.Write(line)
+	}
+	if n := buf.Len(); n > 0 && buf.Bytes()[n-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// Match represents a block of N equal lines starting at A in the first
+// sequence and at B in the second one.
+type Match struct {
+	A, B, N int
+}
+
+// Matches returns blocks of equal lines of a and b using given algorithm.
+// Returned blocks are ordered. The first block always starts at the
+// beginnings of the sequences and the last one is a zero length block at
+// their ends.
+func Matches(a, b [][]byte, alg Algorithm) []Match {
+	x, y := intern(a, b)
+
+	// Trim common prefix and suffix since they are matched by any algorithm.
+	var pre, suf int
+	for pre < len(x) && pre < len(y) && x[pre] == y[pre] {
+		pre++
+	}
+	for suf < len(x)-pre && suf < len(y)-pre && x[len(x)-1-suf] == y[len(y)-1-suf] {
+		suf++
+	}
+	ms := []Match{{0, 0, 0}}
+	if pre > 0 {
+		ms = append(ms, Match{0, 0, pre})
+	}
+	var (
+		xs = x[pre : len(x)-suf]
+		ys = y[pre : len(y)-suf]
+	)
+	var inner []Match
+	switch alg {
+	case Patience:
+		inner = patience(xs, ys)
+	case Histogram:
+		inner = histogram(xs, ys)
+	default:
+		inner = myers(xs, ys)
+	}
+	ms = appendShifted(ms, inner, pre, pre)
+	if suf > 0 {
+		ms = append(ms, Match{len(x) - suf, len(y) - suf, suf})
+	}
+	ms = append(ms, Match{len(x), len(y), 0})
+
+	return normalize(ms)
+}
+
+// intern replaces lines with integer identifiers.
+func intern(a, b [][]byte) (x, y []int) {
+	ids := make(map[string]int)
+	id := func(line []byte) int {
+		if n, has := ids[string(line)]; has {
+			return n
+		}
+		n := len(ids)
+		ids[string(line)] = n
+		return n
+	}
+	x = make([]int, len(a))
+	for i, line := range a {
+		x[i] = id(line)
+	}
+	y = make([]int, len(b))
+	for i, line := range b {
+		y[i] = id(line)
+	}
+	return x, y
+}
+
+func appendShifted(ms, xs []Match, a, b int) []Match {
+	for _, m := range xs {
+		ms = append(ms, Match{m.A + a, m.B + b, m.N})
+	}
+	return ms
+}
+
+// normalize joins adjacent blocks and removes empty ones except the first and
+// the last ones.
+func normalize(ms []Match) []Match {
+	ret := ms[:1]
+	for _, m := range ms[1 : len(ms)-1] {
+		if m.N == 0 {
+			continue
+		}
+		last := &ret[len(ret)-1]
+		if last.A+last.N == m.A && last.B+last.N == m.B {
+			last.N += m.N
+			continue
+		}
+		ret = append(ret, m)
+	}
+	return append(ret, ms[len(ms)-1])
+}
+
+// MapLine returns number of the line in the second sequence which is equal to
+// the given line of the first one according to blocks ms. Line numbers are
+// 1-based. It returns false if the line was changed or deleted.
+func MapLine(ms []Match, line int) (int, bool) {
+	i := line - 1
+	for _, m := range ms {
+		if m.A <= i && i < m.A+m.N {
+			return m.B + i - m.A + 1, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,189 @@
+package diffutil
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/ed"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEd(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		a, b string
+		exp  []ed.Command
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+		},
+		{
+			name: "add",
+			a:    "a\nc\n",
+			b:    "a\nb\nc\n",
+			exp: []ed.Command{
+				{Start: 1, End: 1, Mode: ed.ModeAdd, Text: []byte("b\n")},
+			},
+		},
+		{
+			name: "add first",
+			a:    "b\n",
+			b:    "a\nb\n",
+			exp: []ed.Command{
+				{Start: 0, End: 0, Mode: ed.ModeAdd, Text: []byte("a\n")},
+			},
+		},
+		{
+			name: "delete",
+			a:    "a\nb\nc\nd\n",
+			b:    "a\nd\n",
+			exp: []ed.Command{
+				{Start: 2, End: 3, Mode: ed.ModeDelete},
+			},
+		},
+		{
+			name: "change",
+			a:    "a\nb\nc\nd\ne\n",
+			b:    "A\nb\nc\nD\nE\n",
+			exp: []ed.Command{
+				{Start: 4, End: 5, Mode: ed.ModeChange, Text: []byte("D\nE\n")},
+				{Start: 1, End: 1, Mode: ed.ModeChange, Text: []byte("A\n")},
+			},
+		},
+	} {
+		for _, alg := range []Algorithm{Myers, Patience, Histogram} {
+			t.Run(test.name+"/"+alg.String(), func(t *testing.T) {
+				var act []ed.Command
+				Ed([]byte(test.a), []byte(test.b), alg, func(cmd ed.Command) {
+					act = append(act, cmd)
+				})
+				if !cmp.Equal(act, test.exp) {
+					t.Fatalf("unexpected commands:\n%s", cmp.Diff(test.exp, act))
+				}
+			})
+		}
+	}
+}
+
+func TestEdRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	text := func() []byte {
+		var sb strings.Builder
+		for i, n := 0, r.Intn(30); i < n; i++ {
+			sb.WriteByte(byte('a' + r.Intn(5)))
+			sb.WriteByte('\n')
+		}
+		return []byte(sb.String())
+	}
+	for i := 0; i < 1000; i++ {
+		a, b := text(), text()
+		var n int
+		for _, m := range Matches(Lines(a), Lines(b), Myers) {
+			n += m.N
+		}
+		if exp := lcs(Lines(a), Lines(b)); n != exp {
+			t.Fatalf("myers: non-minimal diff: %d common lines; want %d", n, exp)
+		}
+		for _, alg := range []Algorithm{Myers, Patience, Histogram} {
+			act := apply(a, alg, b)
+			if !bytes.Equal(act, b) {
+				t.Fatalf(
+					"%s: unexpected result of applying diff:\n%s\nto:\n%s\ngot:\n%s",
+					alg, b, a, act,
+				)
+			}
+		}
+	}
+}
+
+func TestMyersRewrite(t *testing.T) {
+	var a, b [][]byte
+	for i := 0; i < 5000; i++ {
+		a = append(a, []byte(fmt.Sprintf("old %d\n", i)))
+		b = append(b, []byte(fmt.Sprintf("new %d\n", i)))
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ms := Matches(a, b, Myers)
+	runtime.ReadMemStats(&after)
+
+	if len(ms) != 2 {
+		t.Errorf("unexpected matches of rewritten text: %v", ms)
+	}
+	// Quadratic implementation needs hundreds of megabytes here.
+	if n := after.TotalAlloc - before.TotalAlloc; n > 8<<20 {
+		t.Errorf("too many bytes allocated: %d", n)
+	}
+}
+
+func TestMapLine(t *testing.T) {
+	var (
+		a  = "one\ntwo\nthree\nfour\n"
+		b  = "zero\none\nTWO\nthree\nfour\n"
+		ms = Matches(Lines([]byte(a)), Lines([]byte(b)), Myers)
+	)
+	for _, test := range []struct {
+		line int
+		exp  int
+		ok   bool
+	}{
+		{line: 1, exp: 2, ok: true},
+		{line: 2, ok: false},
+		{line: 3, exp: 4, ok: true},
+		{line: 4, exp: 5, ok: true},
+		{line: 5, ok: false},
+	} {
+		act, ok := MapLine(ms, test.line)
+		if act != test.exp || ok != test.ok {
+			t.Errorf(
+				"MapLine(%d) = %d, %t; want %d, %t",
+				test.line, act, ok, test.exp, test.ok,
+			)
+		}
+	}
+}
+
+func apply(a []byte, alg Algorithm, b []byte) []byte {
+	lines := Lines(a)
+	Ed(a, b, alg, func(cmd ed.Command) {
+		// NOTE: commands are sent from the end of the text to its beginning.
+		var (
+			lo = cmd.Start - 1
+			hi = cmd.End
+		)
+		if cmd.Mode == ed.ModeAdd {
+			lo, hi = cmd.Start, cmd.Start
+		}
+		var rest [][]byte
+		rest = append(rest, Lines(cmd.Text)...)
+		rest = append(rest, lines[hi:]...)
+		lines = append(lines[:lo], rest...)
+	})
+	return bytes.Join(lines, nil)
+}
+
+func lcs(a, b [][]byte) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case bytes.Equal(a[i], b[j]):
+				dp[i][j] = dp[i+1][j+1] + 1
+			case dp[i+1][j] > dp[i][j+1]:
+				dp[i][j] = dp[i+1][j]
+			default:
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+	return dp[0][0]
+}
@@ -0,0 +1,106 @@
+package ed
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Apply applies commands to the text read from src and writes result to dst.
+// Commands must not overlap, but they may be given in any order.
+func Apply(dst io.Writer, src io.Reader, cmds []Command) error {
+	cmds = append(([]Command)(nil), cmds...)
+	sort.SliceStable(cmds, func(i, j int) bool {
+		pi := insertPoint(cmds[i])
+		pj := insertPoint(cmds[j])
+		if pi == pj {
+			// Lines added before the line must precede its change.
+			return cmds[i].Mode == ModeAdd && cmds[j].Mode != ModeAdd
+		}
+		return pi < pj
+	})
+	var (
+		br = bufio.NewReader(src)
+		bw = bufio.NewWriter(dst)
+
+		// line is a number of the next line to be read from src.
+		line = 1
+		eof  bool
+	)
+	copyLine := func(write bool) error {
+		if eof {
+			return io.ErrUnexpectedEOF
+		}
+		bts, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			eof = true
+			if len(bts) == 0 {
+				return io.ErrUnexpectedEOF
+			}
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		line++
+		if write {
+			_, err = bw.Write(bts)
+		}
+		return err
+	}
+	for _, cmd := range cmds {
+		var until int // Last line to be copied before command is applied.
+		switch cmd.Mode {
+		case ModeAdd:
+			until = cmd.Start
+		case ModeChange, ModeDelete:
+			until = cmd.Start - 1
+		default:
+			return fmt.Errorf("ed: apply: unexpected command mode: %s", cmd.Mode)
+		}
+		if until < line-1 {
+			return fmt.Errorf(
+				"ed: apply: %s command at %d,%d overlaps with previous one",
+				cmd.Mode, cmd.Start, cmd.End,
+			)
+		}
+		for line <= until {
+			if err := copyLine(true); err != nil {
+				return applyError(cmd, err)
+			}
+		}
+		if cmd.Mode != ModeAdd {
+			for line <= cmd.End {
+				if err := copyLine(false); err != nil {
+					return applyError(cmd, err)
+				}
+			}
+		}
+		if _, err := bw.Write(cmd.Text); err != nil {
+			return err
+		}
+	}
+	if _, err := io.Copy(bw, br); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// insertPoint returns number of the first line affected by the command.
+func insertPoint(cmd Command) int {
+	if cmd.Mode == ModeAdd {
+		return cmd.Start + 1
+	}
+	return cmd.Start
+}
+
+func applyError(cmd Command, err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return fmt.Errorf(
+			"ed: apply: %s command at %d,%d is out of text bounds",
+			cmd.Mode, cmd.Start, cmd.End,
+		)
+	}
+	return err
+}
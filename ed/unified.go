@@ -0,0 +1,357 @@
+package ed
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileDiff represents unified diff of a single file.
+type FileDiff struct {
+	// Header holds extended header lines such as "diff --git" or "index".
+	Header  []string
+	OldName string
+	NewName string
+	Hunks   []Hunk
+}
+
+// Hunk represents a single "@@ -a,b +c,d @@" hunk of the unified diff.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Section  string
+	Lines    []Line
+}
+
+// Line represents a line of the hunk.
+type Line struct {
+	// Op is one of ' ', '-' or '+'.
+	Op byte
+	// Text holds line contents including trailing newline. Text has no
+	// trailing newline when diff has "\ No newline at end of file" marker
+	// after the line.
+	Text []byte
+}
+
+const noNewline = `\ No newline at end of file`
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// ParseUnified parses unified diff provided by r. It supports both plain
+// unified diffs and the ones produced by git.
+func ParseUnified(r io.Reader) (fs []*FileDiff, err error) {
+	var (
+		br = bufio.NewReader(r)
+
+		f *FileDiff
+		h *Hunk
+
+		oldLeft int
+		newLeft int
+	)
+	for n := 1; ; n++ {
+		bts, err := br.ReadBytes('\n')
+		if err == io.EOF && len(bts) > 0 {
+			err = nil
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line := string(bytes.TrimRight(bts, "\r\n"))
+
+		if h != nil && strings.HasPrefix(line, `\`) {
+			// No newline marker relates to the previous line.
+			if i := len(h.Lines) - 1; i >= 0 {
+				h.Lines[i].Text = bytes.TrimSuffix(h.Lines[i].Text, []byte{'\n'})
+			}
+			continue
+		}
+		if h != nil && (oldLeft > 0 || newLeft > 0) {
+			op := byte(' ')
+			if len(line) > 0 {
+				op = line[0]
+				bts = bts[1:]
+			}
+			switch op {
+			case ' ':
+				oldLeft--
+				newLeft--
+			case '-':
+				oldLeft--
+			case '+':
+				newLeft--
+			default:
+				return nil, fmt.Errorf("ed: unified diff: line %d: unexpected hunk line: %q", n, line)
+			}
+			h.Lines = append(h.Lines, Line{
+				Op:   op,
+				Text: append(([]byte)(nil), bts...),
+			})
+			continue
+		}
+		h = nil
+
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			f = &FileDiff{
+				Header: []string{line},
+			}
+			fs = append(fs, f)
+
+		case strings.HasPrefix(line, "--- "):
+			if f == nil || len(f.Hunks) > 0 || f.OldName != "" {
+				f = new(FileDiff)
+				fs = append(fs, f)
+			}
+			f.OldName = fileName(line[4:])
+
+		case strings.HasPrefix(line, "+++ ") && f != nil:
+			f.NewName = fileName(line[4:])
+
+		case strings.HasPrefix(line, "@@ ") && f != nil:
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("ed: unified diff: line %d: malformed hunk header: %q", n, line)
+			}
+			f.Hunks = append(f.Hunks, Hunk{
+				OldStart: atoi(m[1], 0),
+				OldLines: atoi(m[2], 1),
+				NewStart: atoi(m[3], 0),
+				NewLines: atoi(m[4], 1),
+				Section:  m[5],
+			})
+			h = &f.Hunks[len(f.Hunks)-1]
+			oldLeft = h.OldLines
+			newLeft = h.NewLines
+
+		case f != nil && len(f.Hunks) == 0:
+			f.Header = append(f.Header, line)
+		}
+	}
+	return fs, nil
+}
+
+func fileName(s string) string {
+	// Plain diff may have timestamp separated by tab after the file name.
+	if i := strings.IndexByte(s, '\t'); i != -1 {
+		s = s[:i]
+	}
+	return s
+}
+
+func atoi(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// Commands returns ed commands equivalent to the file diff. Commands are
+// returned in the same order as `diff --ed` prints them, that is, from the
+// end of the file to its beginning.
+func (f *FileDiff) Commands() []Command {
+	var cmds []Command
+	for _, h := range f.Hunks {
+		// line is a number of the next old line.
+		line := h.OldStart
+		if h.OldLines == 0 {
+			line++
+		}
+		var (
+			start = line
+			del   int
+			add   []byte
+		)
+		flush := func() {
+			switch {
+			case del == 0 && add == nil:
+				return
+			case del == 0:
+				cmds = append(cmds, Command{
+					Start: start - 1,
+					End:   start - 1,
+					Mode:  ModeAdd,
+					Text:  add,
+				})
+			case add == nil:
+				cmds = append(cmds, Command{
+					Start: start,
+					End:   start + del - 1,
+					Mode:  ModeDelete,
+				})
+			default:
+				cmds = append(cmds, Command{
+					Start: start,
+					End:   start + del - 1,
+					Mode:  ModeChange,
+					Text:  add,
+				})
+			}
+			del, add = 0, nil
+		}
+		for _, x := range h.Lines {
+			switch x.Op {
+			case ' ':
+				flush()
+				line++
+				start = line
+			case '-':
+				del++
+				line++
+			case '+':
+				add = append(add, x.Text...)
+			}
+		}
+		flush()
+	}
+	for i, j := 0, len(cmds)-1; i < j; i, j = i+1, j-1 {
+		cmds[i], cmds[j] = cmds[j], cmds[i]
+	}
+	return cmds
+}
+
+// WriteUnified writes unified diff with given number of context lines which
+// describes applying of cmds to the old text.
+func WriteUnified(w io.Writer, oldName, newName string, old []byte, cmds []Command, context int) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+	type edit struct {
+		lo, hi int // Range of old lines; zero-based, exclusive.
+		text   [][]byte
+	}
+	var (
+		lines = splitLines(old)
+		edits = make([]edit, len(cmds))
+	)
+	for i, cmd := range cmds {
+		e := edit{
+			text: splitLines(cmd.Text),
+		}
+		switch cmd.Mode {
+		case ModeAdd:
+			e.lo, e.hi = cmd.Start, cmd.Start
+		case ModeChange, ModeDelete:
+			e.lo, e.hi = cmd.Start-1, cmd.End
+		default:
+			return fmt.Errorf("ed: unified diff: unexpected command mode: %s", cmd.Mode)
+		}
+		if e.lo < 0 || e.hi > len(lines) {
+			return fmt.Errorf(
+				"ed: unified diff: %s command at %d,%d is out of text bounds",
+				cmd.Mode, cmd.Start, cmd.End,
+			)
+		}
+		edits[i] = e
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].lo == edits[j].lo {
+			// Lines added before the line must precede its change.
+			return edits[i].lo == edits[i].hi && edits[j].lo != edits[j].hi
+		}
+		return edits[i].lo < edits[j].lo
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "--- %s\n+++ %s\n", oldName, newName)
+
+	writeLine := func(op byte, line []byte) {
+		bw.WriteByte(op)
+		bw.Write(line)
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			bw.WriteString("\n" + noNewline + "\n")
+		}
+	}
+	var delta int // Difference between new and old line numbers.
+	for i := 0; i < len(edits); {
+		// Join edits which contexts intersect into a single hunk.
+		j := i + 1
+		for j < len(edits) && edits[j].lo-edits[j-1].hi <= 2*context {
+			j++
+		}
+		var (
+			first = edits[i]
+			last  = edits[j-1]
+			begin = max(0, first.lo-context)
+			end   = min(len(lines), last.hi+context)
+
+			oldLines = end - begin
+			newLines = oldLines
+		)
+		for _, e := range edits[i:j] {
+			newLines += len(e.text) - (e.hi - e.lo)
+		}
+		fmt.Fprintf(bw, "@@ -%s +%s @@\n",
+			formatRange(begin+1, oldLines),
+			formatRange(begin+delta+1, newLines),
+		)
+		pos := begin
+		for _, e := range edits[i:j] {
+			for ; pos < e.lo; pos++ {
+				writeLine(' ', lines[pos])
+			}
+			for ; pos < e.hi; pos++ {
+				writeLine('-', lines[pos])
+			}
+			for _, line := range e.text {
+				writeLine('+', line)
+			}
+		}
+		for ; pos < end; pos++ {
+			writeLine(' ', lines[pos])
+		}
+		delta += newLines - oldLines
+		i = j
+	}
+	return bw.Flush()
+}
+
+func formatRange(start, n int) string {
+	switch n {
+	case 0:
+		// Empty range starts at the line before.
+		return strconv.Itoa(start-1) + ",0"
+	case 1:
+		return strconv.Itoa(start)
+	default:
+		return strconv.Itoa(start) + "," + strconv.Itoa(n)
+	}
+}
+
+// splitLines splits text into lines keeping trailing newlines.
+func splitLines(text []byte) (lines [][]byte) {
+	for len(text) > 0 {
+		i := bytes.IndexByte(text, '\n')
+		if i == -1 {
+			i = len(text) - 1
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+	return lines
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
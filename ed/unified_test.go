@@ -0,0 +1,226 @@
+package ed
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testUnified = `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,4 +1,4 @@ func main() {
+ one
+-two
++TWO
+ three
+ four
+@@ -9,2 +9,3 @@
+ nine
+-ten
+\ No newline at end of file
++ten
++eleven
+\ No newline at end of file
+--- b.txt	2021-11-01 10:00:00
++++ b.txt	2021-11-02 10:00:00
+@@ -0,0 +1 @@
++new
+`
+
+func TestParseUnified(t *testing.T) {
+	fs, err := ParseUnified(strings.NewReader(testUnified))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*FileDiff{
+		{
+			Header: []string{
+				"diff --git a/a.txt b/a.txt",
+				"index 1111111..2222222 100644",
+			},
+			OldName: "a/a.txt",
+			NewName: "b/a.txt",
+			Hunks: []Hunk{
+				{
+					OldStart: 1, OldLines: 4,
+					NewStart: 1, NewLines: 4,
+					Section: "func main() {",
+					Lines: []Line{
+						{' ', []byte("one\n")},
+						{'-', []byte("two\n")},
+						{'+', []byte("TWO\n")},
+						{' ', []byte("three\n")},
+						{' ', []byte("four\n")},
+					},
+				},
+				{
+					OldStart: 9, OldLines: 2,
+					NewStart: 9, NewLines: 3,
+					Lines: []Line{
+						{' ', []byte("nine\n")},
+						{'-', []byte("ten")},
+						{'+', []byte("ten\n")},
+						{'+', []byte("eleven")},
+					},
+				},
+			},
+		},
+		{
+			OldName: "b.txt",
+			NewName: "b.txt",
+			Hunks: []Hunk{
+				{
+					OldStart: 0, OldLines: 0,
+					NewStart: 1, NewLines: 1,
+					Lines: []Line{
+						{'+', []byte("new\n")},
+					},
+				},
+			},
+		},
+	}
+	if !cmp.Equal(fs, exp) {
+		t.Fatalf("unexpected file diffs:\n%s", cmp.Diff(exp, fs))
+	}
+
+	var (
+		act     = [][]Command{fs[0].Commands(), fs[1].Commands()}
+		expCmds = [][]Command{
+			{
+				{Start: 10, End: 10, Mode: ModeChange, Text: []byte("ten\neleven")},
+				{Start: 2, End: 2, Mode: ModeChange, Text: []byte("TWO\n")},
+			},
+			{
+				{Start: 0, End: 0, Mode: ModeAdd, Text: []byte("new\n")},
+			},
+		}
+	)
+	if !cmp.Equal(act, expCmds) {
+		t.Fatalf("unexpected commands:\n%s", cmp.Diff(expCmds, act))
+	}
+}
+
+func TestWriteUnified(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10"
+	cmds := []Command{
+		{Start: 10, End: 10, Mode: ModeChange, Text: []byte("10\n")},
+		{Start: 6, End: 6, Mode: ModeDelete},
+		{Start: 1, End: 1, Mode: ModeAdd, Text: []byte("1.5\n")},
+	}
+	var buf bytes.Buffer
+	err := WriteUnified(&buf, "a/x", "b/x", []byte(old), cmds, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `--- a/x
++++ b/x
+@@ -1,2 +1,3 @@
+ 1
++1.5
+ 2
+@@ -5,3 +6,2 @@
+ 5
+-6
+ 7
+@@ -9,2 +9,2 @@
+ 9
+-10
+\ No newline at end of file
++10
+`
+	if act := buf.String(); act != exp {
+		t.Fatalf("unexpected diff:\n%s", cmp.Diff(exp, act))
+	}
+
+	// Check that written diff can be parsed and applied back.
+	fs, err := ParseUnified(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res bytes.Buffer
+	if err := Apply(&res, strings.NewReader(old), fs[0].Commands()); err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := res.String(), "1\n1.5\n2\n3\n4\n5\n7\n8\n9\n10\n"; act != exp {
+		t.Fatalf("unexpected result: %q; want %q", act, exp)
+	}
+}
+
+func TestApply(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		text string
+		cmds []Command
+		exp  string
+		err  bool
+	}{
+		{
+			name: "empty",
+			text: "a\nb\n",
+			exp:  "a\nb\n",
+		},
+		{
+			name: "add first",
+			text: "b\n",
+			cmds: []Command{
+				{Start: 0, End: 0, Mode: ModeAdd, Text: []byte("a\n")},
+			},
+			exp: "a\nb\n",
+		},
+		{
+			name: "add and change",
+			text: "a\nb\nc\n",
+			cmds: []Command{
+				{Start: 2, End: 3, Mode: ModeChange, Text: []byte("B\nC\n")},
+				{Start: 1, End: 1, Mode: ModeAdd, Text: []byte("x\n")},
+			},
+			exp: "a\nx\nB\nC\n",
+		},
+		{
+			name: "delete last",
+			text: "a\nb",
+			cmds: []Command{
+				{Start: 2, End: 2, Mode: ModeDelete},
+			},
+			exp: "a\n",
+		},
+		{
+			name: "out of bounds",
+			text: "a\n",
+			cmds: []Command{
+				{Start: 2, End: 3, Mode: ModeDelete},
+			},
+			err: true,
+		},
+		{
+			name: "overlap",
+			text: "a\nb\nc\n",
+			cmds: []Command{
+				{Start: 1, End: 2, Mode: ModeDelete},
+				{Start: 2, End: 3, Mode: ModeDelete},
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Apply(&buf, strings.NewReader(test.text), test.cmds)
+			if test.err {
+				if err == nil {
+					t.Fatalf("want error; got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if act := buf.String(); act != test.exp {
+				t.Fatalf("unexpected result: %q; want %q", act, test.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package rw
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/rw/blob"
+	"github.com/gobwas/rw/vcs"
+)
+
+// baseFile returns base version of the changed file. Added file has empty
+// base version.
+func baseFile(ctx context.Context, review vcs.Review, f vcs.ChangedFile) (io.ReadCloser, error) {
+	if f.Status == vcs.FileStatusAdded {
+		return ioutil.NopCloser(strings.NewReader("")), nil
+	}
+	return review.BaseFile(ctx, f.BaseName())
+}
+
+// headFile returns head version of the changed file. Deleted file has empty
+// head version.
+func headFile(ctx context.Context, review vcs.Review, f vcs.ChangedFile) (io.ReadCloser, error) {
+	if f.Status == vcs.FileStatusDeleted {
+		return ioutil.NopCloser(strings.NewReader("")), nil
+	}
+	return review.HeadFile(ctx, f.Name)
+}
+
+// diffPaths returns paths of base and head versions of the file as they are
+// shown in a diff header.
+func diffPaths(f vcs.ChangedFile) (a, b string) {
+	a = filepath.Join("a", f.BaseName())
+	b = filepath.Join("b", f.Name)
+	switch f.Status {
+	case vcs.FileStatusAdded:
+		a = "/dev/null"
+	case vcs.FileStatusDeleted:
+		b = "/dev/null"
+	}
+	return a, b
+}
+
+// fileTitle returns name of the file prefixed by its base name if the file
+// was renamed or copied.
+func fileTitle(f vcs.ChangedFile) string {
+	if f.OldName != "" {
+		return f.OldName + " → " + f.Name
+	}
+	return f.Name
+}
+
+// fileLabel returns file title followed by its status and stats.
+func fileLabel(f vcs.ChangedFile) string {
+	var info []string
+	if f.Status != vcs.FileStatusModified {
+		info = append(info, f.Status.String())
+	}
+	if f.Binary {
+		info = append(info, "binary")
+	} else {
+		info = append(info, fmt.Sprintf("+%d -%d", f.Added, f.Deleted))
+	}
+	return fmt.Sprintf("%s (%s)", fileTitle(f), strings.Join(info, ", "))
+}
+
+// sizeSummary returns summary of changes of the file if any of its versions is
+// known to exceed the size limit, so the file doesn't have to be read at all.
+// It returns nil if sizes are within the limit or text review is forced.
+func (r *Review) sizeSummary(f vcs.ChangedFile) []byte {
+	if r.Text || r.MaxFileSize <= 0 {
+		return nil
+	}
+	if f.BaseSize <= r.MaxFileSize && f.HeadSize <= r.MaxFileSize {
+		return nil
+	}
+	var infos [2]*blob.Info
+	for i, size := range [2]int64{f.BaseSize, f.HeadSize} {
+		if (i == 0 && f.Status == vcs.FileStatusAdded) || (i == 1 && f.Status == vcs.FileStatusDeleted) {
+			continue
+		}
+		info := blob.Info{
+			Size: size,
+		}
+		if size > r.MaxFileSize {
+			info.Kind = blob.KindOversized
+		}
+		infos[i] = &info
+	}
+	var buf bytes.Buffer
+	blob.Summary(&buf, infos[0], infos[1])
+	return buf.Bytes()
+}
+
+// fileSummary returns summary of changes of the file if any of its versions
+// can't be reviewed as text. Versions are read from basePath and headPath. It
+// returns nil if the file is reviewable as text or text review is forced.
+func (r *Review) fileSummary(f vcs.ChangedFile, basePath, headPath string) ([]byte, error) {
+	if r.Text {
+		return nil, nil
+	}
+	var (
+		infos [2]*blob.Info
+		text  = !f.Binary
+	)
+	for i, path := range [2]string{basePath, headPath} {
+		if (i == 0 && f.Status == vcs.FileStatusAdded) || (i == 1 && f.Status == vcs.FileStatusDeleted) {
+			continue
+		}
+		info, err := blob.Stat(path, r.MaxFileSize)
+		if err != nil {
+			return nil, err
+		}
+		infos[i] = &info
+		text = text && info.Kind == blob.KindText
+	}
+	if text {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	blob.Summary(&buf, infos[0], infos[1])
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,125 @@
+package rw
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+func TestFileSummary(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	var (
+		text    = write("text", "hello\n")
+		binary  = write("binary", "\x00\x01")
+		missing = filepath.Join(dir, "missing")
+	)
+	for _, test := range []struct {
+		name       string
+		review     Review
+		file       vcs.ChangedFile
+		base, head string
+		summary    bool
+	}{
+		{
+			name: "text",
+			base: text,
+			head: text,
+		},
+		{
+			name:    "binary",
+			base:    text,
+			head:    binary,
+			summary: true,
+		},
+		{
+			name:    "binary by git",
+			file:    vcs.ChangedFile{Binary: true},
+			base:    text,
+			head:    text,
+			summary: true,
+		},
+		{
+			name:   "forced text",
+			review: Review{Text: true},
+			base:   text,
+			head:   binary,
+		},
+		{
+			name:    "oversized",
+			review:  Review{MaxFileSize: 5},
+			base:    text,
+			head:    text,
+			summary: true,
+		},
+		{
+			name: "added",
+			file: vcs.ChangedFile{Status: vcs.FileStatusAdded},
+			base: missing,
+			head: text,
+		},
+		{
+			name:    "deleted binary",
+			file:    vcs.ChangedFile{Status: vcs.FileStatusDeleted},
+			base:    binary,
+			head:    missing,
+			summary: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			act, err := test.review.fileSummary(test.file, test.base, test.head)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.summary != (act != nil) {
+				t.Errorf("unexpected summary: %q", act)
+			}
+		})
+	}
+}
+
+func TestSizeSummary(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		review  Review
+		file    vcs.ChangedFile
+		summary bool
+	}{
+		{
+			name:   "within limit",
+			review: Review{MaxFileSize: 10},
+			file:   vcs.ChangedFile{Name: "a", BaseSize: 10, HeadSize: 5},
+		},
+		{
+			name:    "oversized head",
+			review:  Review{MaxFileSize: 10},
+			file:    vcs.ChangedFile{Name: "a", BaseSize: 5, HeadSize: 11},
+			summary: true,
+		},
+		{
+			name:   "no limit",
+			review: Review{},
+			file:   vcs.ChangedFile{Name: "a", HeadSize: 11},
+		},
+		{
+			name:   "forced text",
+			review: Review{MaxFileSize: 10, Text: true},
+			file:   vcs.ChangedFile{Name: "a", HeadSize: 11},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			act := test.review.sizeSummary(test.file)
+			if test.summary != (act != nil) {
+				t.Errorf("unexpected summary: %q", act)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 
 	"github.com/gobwas/flagutil"
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/syntax"
 )
 
 func DefineFlags(r *Review, fs *flag.FlagSet) {
@@ -23,10 +25,71 @@ func DefineFlags(r *Review, fs *flag.FlagSet) {
 		"comments", false,
 		"annotate changed file with comments from vcs provider",
 	)
+	fs.BoolVar(&r.ExpandResolved,
+		"expand-resolved", false,
+		"show resolved comment threads in full instead of collapsing them",
+	)
+	fs.BoolVar(&r.Progress,
+		"progress", false,
+		"remember reviewed files and hunks between runs",
+	)
+	fs.BoolVar(&r.Interdiff,
+		"interdiff", false,
+		"review only changes made since the last reviewed head revision",
+	)
+	fs.BoolVar(&r.SplitCommits,
+		"split-commits", false,
+		"pick commits of the review and review them one by one",
+	)
+	fs.BoolVar(&r.Text,
+		"text", false,
+		"review binary, git lfs pointer and oversized files as text",
+	)
+	fs.Int64Var(&r.MaxFileSize,
+		"max-size", DefaultMaxFileSize,
+		"size in bytes above which files are summarized instead of shown as text; 0 means no limit",
+	)
 	fs.Var(&r.Mode,
 		"mode",
 		"review mode",
 	)
+	fs.BoolVar(&r.TUI,
+		"tui", false,
+		"use full-screen terminal interface for quick mode",
+	)
+	fs.Var(&r.Layout,
+		"layout",
+		"quick mode layout: unified or split (side by side)",
+	)
+	fs.Var(&r.DiffAlgorithm,
+		"diff-algorithm",
+		"diff algorithm: myers, patience, histogram or external (uses diff tool)",
+	)
+	fs.BoolVar(&r.Syntax,
+		"syntax", true,
+		"highlight syntax in quick mode depending on file extension",
+	)
+	r.Theme = syntax.DefaultTheme
+	flagutil.Subset(fs, "theme", func(fs *flag.FlagSet) {
+		for _, x := range []struct {
+			c    *color.Color
+			kind syntax.Kind
+		}{
+			{&r.Theme.Keyword, syntax.Keyword},
+			{&r.Theme.Type, syntax.Type},
+			{&r.Theme.String, syntax.String},
+			{&r.Theme.Number, syntax.Number},
+			{&r.Theme.Comment, syntax.Comment},
+			{&r.Theme.Literal, syntax.Literal},
+			{&r.Theme.Key, syntax.Key},
+			{&r.Theme.Builtin, syntax.Builtin},
+		} {
+			fs.Var(x.c,
+				x.kind.String(),
+				"color of "+x.kind.String()+" tokens for syntax highlighting",
+			)
+		}
+	})
 	flagutil.Subset(fs, "editor", func(fs *flag.FlagSet) {
 		fs.StringVar(&r.Editor,
 			"name", DefaultEditor,
@@ -38,6 +101,16 @@ func DefineFlags(r *Review, fs *flag.FlagSet) {
 			"args to be passed to the editor; may support variables: Head, Base",
 		)
 	})
+	flagutil.Subset(fs, "pager", func(fs *flag.FlagSet) {
+		fs.StringVar(&r.Pager,
+			"name", "",
+			"a command-line pager for long hunks and files; $PAGER or less is used if empty",
+		)
+		fs.Var(&r.PagerArgs,
+			"args",
+			"args to be passed to the pager",
+		)
+	})
 	flagutil.Subset(fs, "finder", func(fs *flag.FlagSet) {
 		fs.StringVar(&r.Finder,
 			"name", "",
@@ -0,0 +1,225 @@
+package gerrit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type api struct {
+	client   *http.Client
+	baseURL  string
+	user     string
+	password string
+}
+
+type apiError struct {
+	Method  string
+	Path    string
+	Status  string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf(
+		"gerrit: %s %s: %s: %s",
+		e.Method, e.Path, e.Status, e.Message,
+	)
+}
+
+// magicPrefix is prepended by Gerrit to every JSON response to prevent XSSI.
+var magicPrefix = []byte(")]}'")
+
+// do sends request to the Gerrit REST API and decodes response body into
+// result. Authenticated requests are made if user is set.
+func (a *api) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body, result interface{},
+) error {
+	var r io.Reader
+	if body != nil {
+		bts, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(bts)
+	}
+	u := strings.TrimSuffix(a.baseURL, "/")
+	if a.user != "" {
+		u += "/a"
+	}
+	u += path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	}
+	if a.user != "" {
+		req.SetBasicAuth(a.user, a.password)
+	}
+	log.Printf("gerrit: %s %s", method, u)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		bts, _ := ioutil.ReadAll(resp.Body)
+		return &apiError{
+			Method:  method,
+			Path:    path,
+			Status:  resp.Status,
+			Message: strings.TrimSpace(string(bts)),
+		}
+	}
+	if result == nil {
+		return nil
+	}
+	br := bufio.NewReader(resp.Body)
+	if p, _ := br.Peek(len(magicPrefix)); bytes.Equal(p, magicPrefix) {
+		if _, err := br.ReadString('\n'); err != nil {
+			return err
+		}
+	}
+	return json.NewDecoder(br).Decode(result)
+}
+
+func changePath(number int) string {
+	return fmt.Sprintf("/changes/%d", number)
+}
+
+func revisionPath(number, patchSet int) string {
+	return fmt.Sprintf("%s/revisions/%d", changePath(number), patchSet)
+}
+
+// timeLayout is a layout of timestamps used by Gerrit. Timestamps are always
+// given in UTC.
+const timeLayout = "2006-01-02 15:04:05.000000000"
+
+type apiTime struct {
+	time.Time
+}
+
+func (t *apiTime) UnmarshalJSON(p []byte) error {
+	var s string
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	x, err := time.ParseInLocation(timeLayout, s, time.UTC)
+	if err != nil {
+		return err
+	}
+	t.Time = x
+	return nil
+}
+
+func (t apiTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(timeLayout))
+}
+
+type apiAccount struct {
+	AccountID int64  `json:"_account_id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+}
+
+func (a *apiAccount) login() string {
+	switch {
+	case a == nil:
+		return ""
+	case a.Username != "":
+		return a.Username
+	case a.Email != "":
+		return a.Email
+	default:
+		return a.Name
+	}
+}
+
+type apiParent struct {
+	Commit string `json:"commit"`
+}
+
+type apiCommit struct {
+	Parents []apiParent `json:"parents"`
+	Subject string      `json:"subject"`
+}
+
+type apiRevision struct {
+	Number int        `json:"_number"`
+	Ref    string     `json:"ref"`
+	Commit *apiCommit `json:"commit"`
+}
+
+type apiChange struct {
+	ID              string                  `json:"id"`
+	Project         string                  `json:"project"`
+	Branch          string                  `json:"branch"`
+	ChangeID        string                  `json:"change_id"`
+	Subject         string                  `json:"subject"`
+	Number          int                     `json:"_number"`
+	Owner           apiAccount              `json:"owner"`
+	CurrentRevision string                  `json:"current_revision"`
+	Revisions       map[string]*apiRevision `json:"revisions"`
+	MoreChanges     bool                    `json:"_more_changes"`
+}
+
+type apiRange struct {
+	StartLine      int `json:"start_line"`
+	StartCharacter int `json:"start_character"`
+	EndLine        int `json:"end_line"`
+	EndCharacter   int `json:"end_character"`
+}
+
+type apiComment struct {
+	ID         string      `json:"id,omitempty"`
+	PatchSet   int         `json:"patch_set,omitempty"`
+	Path       string      `json:"path,omitempty"`
+	Side       string      `json:"side,omitempty"`
+	Line       int         `json:"line,omitempty"`
+	Range      *apiRange   `json:"range,omitempty"`
+	InReplyTo  string      `json:"in_reply_to,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	Updated    *apiTime    `json:"updated,omitempty"`
+	Author     *apiAccount `json:"author,omitempty"`
+	Unresolved *bool       `json:"unresolved,omitempty"`
+
+	FixSuggestions []*apiFixSuggestion `json:"fix_suggestions,omitempty"`
+}
+
+type apiFixSuggestion struct {
+	Description  string               `json:"description"`
+	Replacements []*apiFixReplacement `json:"replacements"`
+}
+
+type apiFixReplacement struct {
+	Path        string    `json:"path"`
+	Range       *apiRange `json:"range"`
+	Replacement string    `json:"replacement"`
+}
+
+type apiReviewInput struct {
+	Message string         `json:"message,omitempty"`
+	Labels  map[string]int `json:"labels,omitempty"`
+	Drafts  string         `json:"drafts,omitempty"`
+}
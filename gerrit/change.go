@@ -0,0 +1,359 @@
+package gerrit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gobwas/rw/ed"
+	"github.com/gobwas/rw/internal/gitvcs"
+	"github.com/gobwas/rw/vcs"
+)
+
+type change struct {
+	c    *Client
+	ch   *apiChange
+	head *apiRevision
+	base *apiRevision
+
+	comments vcs.CommentCache
+
+	mu     sync.Mutex
+	drafts map[int]int // Number of created drafts per patch set.
+}
+
+// Close closes the change. Drafts which are not published by SubmitReview()
+// are kept unpublished.
+func (c *change) Close() error {
+	if err := c.comments.Close(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for patchSet, n := range c.drafts {
+		log.Printf("gerrit: %d draft(s) of patch set %d left unpublished", n, patchSet)
+	}
+	return nil
+}
+
+// StartReview implements vcs.PendingReview. Comments are always created as
+// drafts, so there is nothing to start.
+func (c *change) StartReview(ctx context.Context) error {
+	return nil
+}
+
+// SubmitReview implements vcs.PendingReview. It publishes drafts created
+// during the review along with the message and label votes. Configured votes
+// are published on approval; request for changes is voted as Code-Review-1.
+// Drafts left on other patch sets outside of the review are kept
+// unpublished.
+func (c *change) SubmitReview(ctx context.Context, v vcs.Verdict, body string) error {
+	c.mu.Lock()
+	drafts := c.drafts
+	c.drafts = nil
+	c.mu.Unlock()
+
+	for patchSet, n := range drafts {
+		if patchSet == c.head.Number {
+			continue
+		}
+		// Drafts of the base patch set are published separately since
+		// publishing is bound to a single revision.
+		log.Printf("gerrit: publishing %d draft(s) of patch set %d", n, patchSet)
+		if err := c.publish(ctx, patchSet, &apiReviewInput{
+			Drafts: "PUBLISH",
+		}); err != nil {
+			return err
+		}
+	}
+	if body == "" {
+		body = c.c.Message
+	}
+	var labels map[string]int
+	switch v {
+	case vcs.VerdictApprove:
+		labels = c.c.Labels
+	case vcs.VerdictRequestChanges:
+		labels = map[string]int{"Code-Review": -1}
+	}
+	if drafts[c.head.Number] == 0 && len(labels) == 0 && body == "" {
+		return nil
+	}
+	log.Printf("gerrit: publishing %d draft(s) of patch set %d", drafts[c.head.Number], c.head.Number)
+	return c.publish(ctx, c.head.Number, &apiReviewInput{
+		Message: body,
+		Labels:  labels,
+		Drafts:  "PUBLISH",
+	})
+}
+
+func (c *change) publish(ctx context.Context, patchSet int, req *apiReviewInput) error {
+	return c.c.api.do(ctx,
+		http.MethodPost, revisionPath(c.ch.Number, patchSet)+"/review", nil,
+		req, nil,
+	)
+}
+
+func (c *change) String() string {
+	return fmt.Sprintf("%s: %d", c.ch.Project, c.ch.Number)
+}
+
+func (c *change) patchSets() patchSets {
+	ps := patchSets{
+		head: c.head.Number,
+	}
+	if c.base != nil {
+		ps.base = c.base.Number
+	}
+	return ps
+}
+
+func (c *change) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return gitvcs.ChangedFiles(c.c.git.ChangedFiles(ctx, c.BaseName(), c.HeadName()))
+}
+
+func (c *change) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
+	c.comments.Fetch(func(ctx context.Context) (map[string][]vcs.Comment, error) {
+		var (
+			ps   = c.patchSets()
+			self = c.c.self.login()
+		)
+		var published map[string][]*apiComment
+		err := c.c.api.do(ctx,
+			http.MethodGet, changePath(c.ch.Number)+"/comments", nil,
+			nil, &published,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ret := fileComments(ps, published, false, self)
+		if c.c.User != "" {
+			// Drafts are available only for authenticated user.
+			var drafts map[string][]*apiComment
+			err := c.c.api.do(ctx,
+				http.MethodGet, changePath(c.ch.Number)+"/drafts", nil,
+				nil, &drafts,
+			)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, fileComments(ps, drafts, true, self)...)
+		}
+		linkComments(ret)
+		return byFile(ret), nil
+	})
+	return c.comments.File(ctx, file)
+}
+
+// BaseName returns ref of the base patch set or parent commit of the head
+// patch set.
+func (c *change) BaseName() string {
+	if c.base != nil {
+		return c.base.Ref
+	}
+	return c.head.Ref + "^"
+}
+
+func (c *change) HeadName() string {
+	return c.head.Ref
+}
+
+// HeadRevision implements vcs.HeadRevision. Revisions of the change are
+// indexed by their commit hashes.
+func (c *change) HeadRevision(ctx context.Context) (string, error) {
+	for sha, r := range c.ch.Revisions {
+		if r == c.head {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("gerrit: no commit of patch set %d", c.head.Number)
+}
+
+func (c *change) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return c.c.git.ShowFile(ctx, c.BaseName(), file)
+}
+func (c *change) HeadFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return c.c.git.ShowFile(ctx, c.HeadName(), file)
+}
+
+func (c *change) Checkout(ctx context.Context) (dir string, cleanup func() error, err error) {
+	return c.c.git.Checkout(ctx, c.HeadName())
+}
+
+func (c *change) Edit(ctx context.Context, file string, cmd ed.Command) (err error) {
+	log.Printf("editing file %s", file)
+	var (
+		body string
+		fix  *apiFixSuggestion
+	)
+	switch cmd.Mode {
+	case ed.ModeAdd:
+		body = string(bytes.TrimSpace(cmd.Text))
+		if body == "" {
+			log.Println("empty comment; skipping")
+			return nil
+		}
+	case ed.ModeChange, ed.ModeDelete:
+		body = "Suggested change."
+		if cmd.Mode == ed.ModeDelete {
+			body = "Suggested removal."
+		}
+		// Replace whole lines including the line break of the last one.
+		fix = &apiFixSuggestion{
+			Description: body,
+			Replacements: []*apiFixReplacement{{
+				Path: file,
+				Range: &apiRange{
+					StartLine: cmd.Start,
+					EndLine:   cmd.End + 1,
+				},
+				Replacement: string(cmd.Text),
+			}},
+		}
+	default:
+		return nil
+	}
+	patchSet, req, err := c.draft(ctx, file, vcs.SideHead, cmd.Start, cmd.End, body)
+	if err != nil {
+		return err
+	}
+	if fix != nil {
+		req.FixSuggestions = []*apiFixSuggestion{fix}
+	}
+	_, err = c.createDraft(ctx, patchSet, req)
+	return err
+}
+
+// Comment creates a draft comment. Drafts are published when review is
+// submitted.
+func (c *change) Comment(
+	ctx context.Context,
+	file string,
+	side vcs.Side,
+	lo, hi int,
+	body string,
+) (vcs.Comment, error) {
+	patchSet, req, err := c.draft(ctx, file, side, lo, hi, body)
+	if err != nil {
+		return nil, err
+	}
+	x, err := c.createDraft(ctx, patchSet, req)
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// draft returns a request to create draft comment on the lines lo-hi of the
+// file along with the patch set it belongs to.
+func (c *change) draft(
+	ctx context.Context,
+	file string,
+	side vcs.Side,
+	lo, hi int,
+	body string,
+) (int, *apiComment, error) {
+	patchSet, parent := c.patchSets().location(side)
+	req := &apiComment{
+		Path:    file,
+		Line:    hi,
+		Message: body,
+	}
+	if parent {
+		req.Side = "PARENT"
+	}
+	if lo < hi {
+		rev := c.HeadName()
+		if side == vcs.SideBase {
+			rev = c.BaseName()
+		}
+		n, err := c.lineLength(ctx, rev, file, hi)
+		if err != nil {
+			return 0, nil, err
+		}
+		req.Range = &apiRange{
+			StartLine:    lo,
+			EndLine:      hi,
+			EndCharacter: n,
+		}
+	}
+	return patchSet, req, nil
+}
+
+func (c *change) ReplyTo(ctx context.Context, parent vcs.Comment, body string) (vcs.Comment, error) {
+	p := parent.(*comment)
+	req := &apiComment{
+		Path:      p.path,
+		Line:      p.line,
+		InReplyTo: p.id,
+		Message:   body,
+	}
+	if _, isParent := c.patchSets().location(p.side); isParent {
+		req.Side = "PARENT"
+	}
+	x, err := c.createDraft(ctx, p.patchSet, req)
+	if err != nil {
+		return nil, err
+	}
+	x.startLine = p.startLine
+	x.parent = p
+	if p.parent != nil {
+		x.parent = p.parent
+	}
+	return x, nil
+}
+
+func (c *change) createDraft(ctx context.Context, patchSet int, req *apiComment) (*comment, error) {
+	var resp apiComment
+	err := c.c.api.do(ctx,
+		http.MethodPut, revisionPath(c.ch.Number, patchSet)+"/drafts", nil,
+		req, &resp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Path == "" {
+		resp.Path = req.Path
+	}
+	if resp.PatchSet == 0 {
+		resp.PatchSet = patchSet
+	}
+	x := newComment(c.patchSets(), &resp, true, c.c.self.login())
+	if x == nil {
+		return nil, fmt.Errorf("gerrit: created draft is not bound to the line")
+	}
+	c.mu.Lock()
+	if c.drafts == nil {
+		c.drafts = make(map[int]int)
+	}
+	c.drafts[patchSet]++
+	c.mu.Unlock()
+
+	return x, nil
+}
+
+// lineLength returns number of characters at given line of the file.
+func (c *change) lineLength(ctx context.Context, rev, file string, line int) (int, error) {
+	src, err := c.c.git.ShowFile(ctx, rev, file)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	s := bufio.NewScanner(src)
+	s.Buffer(nil, 1<<20)
+	for i := 1; s.Scan(); i++ {
+		if i == line {
+			return utf8.RuneCount(s.Bytes()), nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("gerrit: no line %d in %s:%s", line, rev, file)
+}
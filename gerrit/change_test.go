@@ -0,0 +1,119 @@
+package gerrit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangeSubmitReview(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		verdict vcs.Verdict
+		body    string
+		close   bool
+		labels  Labels
+		message string
+		drafts  map[int]int
+		exp     []string
+	}{
+		{
+			name: "nothing to publish",
+		},
+		{
+			name:   "close without submit",
+			close:  true,
+			labels: Labels{"Code-Review": 1},
+			drafts: map[int]int{2: 1, 3: 2},
+		},
+		{
+			name:   "comment without vote",
+			labels: Labels{"Code-Review": 1},
+		},
+		{
+			name:    "vote without drafts",
+			verdict: vcs.VerdictApprove,
+			labels:  Labels{"Code-Review": 1},
+			exp: []string{
+				`/changes/1/revisions/3/review {"labels":{"Code-Review":1},"drafts":"PUBLISH"}`,
+			},
+		},
+		{
+			name:    "message without drafts",
+			message: "LGTM",
+			exp: []string{
+				`/changes/1/revisions/3/review {"message":"LGTM","drafts":"PUBLISH"}`,
+			},
+		},
+		{
+			name:    "summary overrides message",
+			body:    "Nits",
+			message: "LGTM",
+			exp: []string{
+				`/changes/1/revisions/3/review {"message":"Nits","drafts":"PUBLISH"}`,
+			},
+		},
+		{
+			name:    "request changes",
+			verdict: vcs.VerdictRequestChanges,
+			labels:  Labels{"Code-Review": 1},
+			exp: []string{
+				`/changes/1/revisions/3/review {"labels":{"Code-Review":-1},"drafts":"PUBLISH"}`,
+			},
+		},
+		{
+			name:   "drafts on base and head",
+			drafts: map[int]int{2: 1, 3: 2},
+			exp: []string{
+				`/changes/1/revisions/2/review {"drafts":"PUBLISH"}`,
+				`/changes/1/revisions/3/review {"drafts":"PUBLISH"}`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var act []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req json.RawMessage
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("can't decode request: %v", err)
+				}
+				act = append(act, r.URL.Path+" "+string(req))
+			}))
+			defer srv.Close()
+
+			c := &change{
+				c: &Client{
+					Labels:  test.labels,
+					Message: test.message,
+					api: api{
+						client:  srv.Client(),
+						baseURL: srv.URL,
+					},
+				},
+				ch:     &apiChange{Number: 1},
+				head:   &apiRevision{Number: 3},
+				base:   &apiRevision{Number: 2},
+				drafts: test.drafts,
+			}
+			if !test.close {
+				err := c.SubmitReview(context.Background(), test.verdict, test.body)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := c.Close(); err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(act)
+			if !cmp.Equal(act, test.exp) {
+				t.Errorf("unexpected requests:\n%s", cmp.Diff(test.exp, act))
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package gerrit
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+// byFile groups comments by file keeping their order.
+func byFile(cs []*comment) map[string][]vcs.Comment {
+	m := make(map[string][]vcs.Comment)
+	for _, x := range cs {
+		m[x.path] = append(m[x.path], x)
+	}
+	return m
+}
+
+type comment struct {
+	parent *comment
+
+	id        string
+	inReplyTo string
+	patchSet  int
+	body      string
+	startLine int
+	line      int
+	updatedAt time.Time
+	userLogin string
+	side      vcs.Side
+	path      string
+	draft     bool
+}
+
+func (c *comment) Lines() (lo, hi int) {
+	if c.startLine != 0 {
+		return c.startLine, c.line
+	}
+	return c.line, c.line
+}
+func (c *comment) Body() string {
+	return c.body
+}
+
+// CreatedAt returns time of the last comment update since Gerrit doesn't
+// provide creation time.
+func (c *comment) CreatedAt() time.Time {
+	return c.updatedAt
+}
+func (c *comment) UpdatedAt() time.Time {
+	return c.updatedAt
+}
+func (c *comment) UserLogin() string {
+	return c.userLogin
+}
+func (c *comment) Side() vcs.Side {
+	return c.side
+}
+func (c *comment) Parent() vcs.Comment {
+	if c.parent != nil {
+		return c.parent
+	}
+	return nil
+}
+func (c *comment) ID() string {
+	return c.id
+}
+
+// patchSets holds numbers of patch sets under review. Zero base means that
+// head patch set is compared against its parent commit.
+type patchSets struct {
+	base int
+	head int
+}
+
+// side returns side of the review which comment made on the patch set
+// belongs to. It returns false if comment belongs to neither base nor head.
+func (ps patchSets) side(patchSet int, parent bool) (vcs.Side, bool) {
+	switch {
+	case patchSet == ps.head && !parent:
+		return vcs.SideHead, true
+	case patchSet == ps.head && parent && ps.base == 0:
+		return vcs.SideBase, true
+	case patchSet == ps.base && !parent && ps.base != 0:
+		return vcs.SideBase, true
+	default:
+		return vcs.SideUnknown, false
+	}
+}
+
+// location returns patch set number and Gerrit side of the given review side.
+func (ps patchSets) location(side vcs.Side) (patchSet int, parent bool) {
+	switch {
+	case side != vcs.SideBase:
+		return ps.head, false
+	case ps.base == 0:
+		return ps.head, true
+	default:
+		return ps.base, false
+	}
+}
+
+// fileComments converts comments of the change grouped by file path. Only
+// comments on lines of base and head patch sets are returned.
+func fileComments(ps patchSets, m map[string][]*apiComment, draft bool, self string) []*comment {
+	var ret []*comment
+	for path, xs := range m {
+		for _, x := range xs {
+			x.Path = path
+			c := newComment(ps, x, draft, self)
+			if c == nil {
+				continue
+			}
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+func newComment(ps patchSets, x *apiComment, draft bool, self string) *comment {
+	if x.Line == 0 {
+		// Comment not for the line.
+		return nil
+	}
+	side, ok := ps.side(x.PatchSet, x.Side == "PARENT")
+	if !ok {
+		return nil
+	}
+	c := &comment{
+		id:        x.ID,
+		inReplyTo: x.InReplyTo,
+		patchSet:  x.PatchSet,
+		body:      x.Message,
+		line:      x.Line,
+		side:      side,
+		path:      x.Path,
+		draft:     draft,
+		userLogin: x.Author.login(),
+	}
+	if x.Updated != nil {
+		c.updatedAt = x.Updated.Time
+	}
+	if draft {
+		// Drafts have no author.
+		c.userLogin = self
+	}
+	if r := x.Range; r != nil && r.StartLine < r.EndLine {
+		c.startLine = r.StartLine
+	}
+	return c
+}
+
+// linkComments sets parent comment for replies. Parent is always a root
+// comment of the thread.
+func linkComments(cs []*comment) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].updatedAt.Before(cs[j].updatedAt)
+	})
+	index := make(map[string]*comment, len(cs))
+	for _, c := range cs {
+		index[c.id] = c
+	}
+	root := func(c *comment) *comment {
+		for i := 0; i < len(cs) && c.inReplyTo != ""; i++ {
+			p := index[c.inReplyTo]
+			if p == nil {
+				break
+			}
+			c = p
+		}
+		return c
+	}
+	for _, c := range cs {
+		if c.inReplyTo == "" {
+			continue
+		}
+		if r := root(c); r != c {
+			c.parent = r
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package gerrit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+func TestPatchSetsSide(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		ps       patchSets
+		patchSet int
+		parent   bool
+		side     vcs.Side
+		ok       bool
+	}{
+		{
+			name:     "head",
+			ps:       patchSets{head: 2},
+			patchSet: 2,
+			side:     vcs.SideHead,
+			ok:       true,
+		},
+		{
+			name:     "parent",
+			ps:       patchSets{head: 2},
+			patchSet: 2,
+			parent:   true,
+			side:     vcs.SideBase,
+			ok:       true,
+		},
+		{
+			name:     "old",
+			ps:       patchSets{head: 2},
+			patchSet: 1,
+		},
+		{
+			name:     "base patch set",
+			ps:       patchSets{base: 1, head: 3},
+			patchSet: 1,
+			side:     vcs.SideBase,
+			ok:       true,
+		},
+		{
+			name:     "parent of head with base patch set",
+			ps:       patchSets{base: 1, head: 3},
+			patchSet: 3,
+			parent:   true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			side, ok := test.ps.side(test.patchSet, test.parent)
+			if side != test.side || ok != test.ok {
+				t.Fatalf(
+					"unexpected side: %s, %t; want %s, %t",
+					side, ok, test.side, test.ok,
+				)
+			}
+			if !ok {
+				return
+			}
+			patchSet, parent := test.ps.location(side)
+			if patchSet != test.patchSet || parent != test.parent {
+				t.Fatalf(
+					"unexpected location of %s: %d, %t; want %d, %t",
+					side, patchSet, parent, test.patchSet, test.parent,
+				)
+			}
+		})
+	}
+}
+
+func TestFileComments(t *testing.T) {
+	const data = `{
+		"main.go": [
+			{
+				"id": "a",
+				"patch_set": 2,
+				"line": 10,
+				"range": {"start_line": 8, "end_line": 10, "end_character": 3},
+				"message": "root",
+				"updated": "2021-09-01 10:00:00.000000000",
+				"author": {"username": "reviewer"}
+			},
+			{
+				"id": "b",
+				"patch_set": 2,
+				"line": 10,
+				"in_reply_to": "a",
+				"message": "reply",
+				"updated": "2021-09-01 10:01:00.000000000",
+				"author": {"username": "author"}
+			},
+			{
+				"id": "c",
+				"patch_set": 2,
+				"line": 10,
+				"in_reply_to": "b",
+				"message": "reply to reply",
+				"updated": "2021-09-01 10:02:00.000000000",
+				"author": {"username": "reviewer"}
+			},
+			{
+				"id": "d",
+				"patch_set": 1,
+				"line": 4,
+				"message": "old patch set",
+				"updated": "2021-09-01 09:00:00.000000000",
+				"author": {"username": "reviewer"}
+			},
+			{
+				"id": "e",
+				"patch_set": 2,
+				"message": "file comment",
+				"updated": "2021-09-01 09:00:00.000000000",
+				"author": {"username": "reviewer"}
+			}
+		]
+	}`
+	var m map[string][]*apiComment
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+	cs := fileComments(patchSets{head: 2}, m, false, "me")
+	linkComments(cs)
+
+	if n := len(cs); n != 3 {
+		t.Fatalf("unexpected number of comments: %d", n)
+	}
+	root := cs[0]
+	if lo, hi := root.Lines(); lo != 8 || hi != 10 {
+		t.Errorf("unexpected root lines: %d-%d", lo, hi)
+	}
+	exp := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	if act := root.CreatedAt(); !act.Equal(exp) {
+		t.Errorf("unexpected root time: %s; want %s", act, exp)
+	}
+	for _, c := range cs[1:] {
+		if p := c.Parent(); p == nil || p.ID() != root.ID() {
+			t.Errorf("unexpected parent of %q: %v", c.ID(), p)
+		}
+		if c.Side() != vcs.SideHead || c.path != "main.go" {
+			t.Errorf("unexpected location of %q: %s %s", c.ID(), c.Side(), c.path)
+		}
+	}
+}
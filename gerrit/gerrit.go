@@ -0,0 +1,306 @@
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/vcs"
+)
+
+type Client struct {
+	Project        string
+	CacheDir       string
+	URL            string
+	CloneURL       string
+	User           string
+	Password       string
+	Origin         string
+	Branch         string
+	Query          string
+	ChangeID       int
+	PatchSet       int
+	BasePatchSet   int
+	Labels         Labels
+	Message        string
+	ChangeTemplate string
+
+	// HTTPClient is used to make Gerrit API requests.
+	// If nil then http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	once           sync.Once
+	api            api
+	git            *git.Repository
+	self           apiAccount
+	project        string
+	err            error
+	changeTemplate *template.Template
+}
+
+const cacheOrigin = "rw-origin"
+
+func (c *Client) Init(ctx context.Context) error {
+	c.once.Do(func() {
+		if c.URL == "" {
+			c.err = fmt.Errorf("gerrit: instance url is not set")
+			return
+		}
+		c.api = api{
+			client:   c.HTTPClient,
+			baseURL:  c.URL,
+			user:     c.User,
+			password: c.Password,
+		}
+		if c.api.client == nil {
+			c.api.client = http.DefaultClient
+		}
+		if c.User != "" {
+			c.err = c.api.do(ctx,
+				http.MethodGet, "/accounts/self", nil,
+				nil, &c.self,
+			)
+			if c.err != nil {
+				return
+			}
+		}
+
+		c.changeTemplate, c.err = template.New("change").Parse(c.ChangeTemplate)
+		if c.err != nil {
+			return
+		}
+
+		var (
+			branch  = c.Branch
+			project = c.Project
+		)
+		if project == "" {
+			// Try to work with repo in process's current directory.
+			var owner, repo string
+			owner, repo, c.err = git.ShowRemote(ctx, c.Origin)
+			if c.err == nil {
+				branch, c.err = git.CurrentBranch(ctx)
+			}
+			if c.err != nil {
+				return
+			}
+			project = path.Join(owner, repo)
+		}
+		c.project = strings.Trim(project, "/")
+
+		origin := c.CloneURL
+		if origin == "" {
+			origin = strings.TrimSuffix(c.URL, "/") + "/" + c.project
+		}
+		var dir string
+		if cache := c.CacheDir; cache != "" {
+			var u *url.URL
+			u, c.err = url.Parse(c.URL)
+			if c.err != nil {
+				return
+			}
+			dir = filepath.Join(cache, u.Host, c.project)
+		}
+		c.git, c.err = git.Cache(ctx, dir, cacheOrigin, origin, branch)
+	})
+	return c.err
+}
+
+func (c *Client) List(ctx context.Context, fn func(vcs.ReviewItem)) error {
+	if id := c.ChangeID; id != 0 {
+		ch, err := c.change(ctx, id)
+		if err != nil {
+			return err
+		}
+		fn(&item{
+			change:   ch,
+			template: c.changeTemplate,
+		})
+		return nil
+	}
+	q := []string{
+		"status:open",
+		"project:" + c.project,
+	}
+	if c.User != "" {
+		q = append(q, "reviewer:self", "-owner:self")
+	}
+	if s := c.Query; s != "" {
+		q = append(q, s)
+	}
+	query := url.Values{
+		"q": {strings.Join(q, " ")},
+		"o": {"DETAILED_ACCOUNTS"},
+	}
+	for start := 0; ; {
+		query.Set("S", strconv.Itoa(start))
+		var cs []*apiChange
+		err := c.api.do(ctx,
+			http.MethodGet, "/changes/", query,
+			nil, &cs,
+		)
+		if err != nil {
+			return err
+		}
+		for _, ch := range cs {
+			fn(&item{
+				change:   ch,
+				template: c.changeTemplate,
+			})
+		}
+		start += len(cs)
+		if len(cs) == 0 || !cs[len(cs)-1].MoreChanges {
+			break
+		}
+	}
+	return nil
+}
+
+func (c *Client) Select(ctx context.Context, x vcs.ReviewItem) (vcs.Review, error) {
+	switch v := x.(type) {
+	case *item:
+		// Listed changes have no revisions, so get it again.
+		ch, err := c.change(ctx, v.change.Number)
+		if err != nil {
+			return nil, err
+		}
+		revs := make(map[int]*apiRevision, len(ch.Revisions))
+		for _, r := range ch.Revisions {
+			revs[r.Number] = r
+		}
+		head := revs[c.PatchSet]
+		if c.PatchSet == 0 {
+			head = ch.Revisions[ch.CurrentRevision]
+		}
+		if head == nil {
+			return nil, fmt.Errorf(
+				"gerrit: change %d has no patch set %d",
+				ch.Number, c.PatchSet,
+			)
+		}
+		var base *apiRevision
+		if n := c.BasePatchSet; n != 0 {
+			base = revs[n]
+			if base == nil {
+				return nil, fmt.Errorf(
+					"gerrit: change %d has no patch set %d",
+					ch.Number, n,
+				)
+			}
+		}
+		refspecs := []string{
+			"+" + head.Ref + ":" + head.Ref,
+		}
+		if base != nil {
+			refspecs = append(refspecs, "+"+base.Ref+":"+base.Ref)
+		}
+		if err := c.git.FetchRefspec(ctx, cacheOrigin, refspecs...); err != nil {
+			return nil, err
+		}
+		return &change{
+			c:    c,
+			ch:   ch,
+			head: head,
+			base: base,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("gerrit: select: unsupported type: %T", v)
+	}
+}
+
+func (c *Client) change(ctx context.Context, number int) (*apiChange, error) {
+	ch := new(apiChange)
+	err := c.api.do(ctx,
+		http.MethodGet, changePath(number), url.Values{
+			"o": {"ALL_REVISIONS", "DETAILED_ACCOUNTS"},
+		},
+		nil, ch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+type item struct {
+	change   *apiChange
+	template *template.Template
+}
+
+func (s *item) String() string {
+	var sb strings.Builder
+	err := s.template.Execute(&sb, s)
+	if err != nil {
+		panic(err)
+	}
+	return sb.String()
+}
+
+func (s *item) Number() int {
+	return s.change.Number
+}
+
+func (s *item) UserLogin() string {
+	return s.change.Owner.login()
+}
+
+func (s *item) Title() string {
+	return s.change.Subject
+}
+
+// Labels holds votes to be sent along with published comments.
+// It implements flag.Value interface and accepts values like "Code-Review=+1".
+type Labels map[string]int
+
+func (ls *Labels) Set(s string) error {
+	name, value := split2(s, '=')
+	if name == "" || value == "" {
+		return fmt.Errorf("malformed label vote: %q", s)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("malformed label vote: %q: %v", s, err)
+	}
+	if *ls == nil {
+		*ls = make(Labels)
+	}
+	(*ls)[name] = n
+	return nil
+}
+
+func (ls *Labels) String() string {
+	if ls == nil {
+		return ""
+	}
+	names := make([]string, 0, len(*ls))
+	for name := range *ls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprintf(&sb, "%s=%+d", name, (*ls)[name])
+	}
+	return sb.String()
+}
+
+func split2(s string, c byte) (string, string) {
+	i := strings.IndexByte(s, c)
+	if i == -1 {
+		return s, ""
+	}
+	return s[:i], s[i+1:]
+}
@@ -0,0 +1,56 @@
+package gerrit
+
+import (
+	"flag"
+)
+
+func DefineFlags(c *Client, fs *flag.FlagSet) {
+	fs.StringVar(&c.URL,
+		"url", "",
+		"gerrit instance url",
+	)
+	fs.StringVar(&c.CloneURL,
+		"clone-url", "",
+		"url to clone project from (instance url joined with project by default)",
+	)
+	fs.StringVar(&c.User,
+		"user", "",
+		"user name for http authentication",
+	)
+	fs.StringVar(&c.Password,
+		"password", "",
+		"http password",
+	)
+	fs.StringVar(&c.Query,
+		"q", "",
+		"additional change search query",
+	)
+	fs.IntVar(&c.ChangeID,
+		"change", 0,
+		"change number",
+	)
+	fs.IntVar(&c.PatchSet,
+		"patchset", 0,
+		"patch set to review (current patch set by default)",
+	)
+	fs.IntVar(&c.BasePatchSet,
+		"base-patchset", 0,
+		"patch set to compare with (parent commit by default)",
+	)
+	fs.Var(&c.Labels,
+		"vote",
+		"label vote to publish when review is approved, e.g. Code-Review=+1",
+	)
+	fs.StringVar(&c.Message,
+		"message", "",
+		"message to publish when review is submitted without summary",
+	)
+	fs.StringVar(&c.ChangeTemplate,
+		"change-template", `{{ .Number }} @{{ .UserLogin }}: {{ .Title }}`,
+		"change template",
+	)
+	fs.StringVar(&c.Origin,
+		"origin", "origin",
+		"name of the git remote upstream to use",
+	)
+}
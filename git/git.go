@@ -7,12 +7,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"math/rand"
 	"net/url"
+	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var DefaultRepo Repository
@@ -39,6 +45,53 @@ func Init(ctx context.Context, dir string) (*Repository, error) {
 	return r, nil
 }
 
+// Cache prepares a repository within dir which tracks uri under the remote
+// name. If dir is empty then temporary directory is created.
+//
+// Cached repository is switched to the given branch or to the default branch
+// of the remote if branch is empty.
+func Cache(ctx context.Context, dir, remote, uri, branch string) (_ *Repository, err error) {
+	// Unconditionally create separate dir with repo.
+	// We don't want to mutate remotes for existing repo.
+	if dir == "" {
+		dir, err = ioutil.TempDir("", "rw*")
+	} else {
+		err = os.MkdirAll(dir, 0755)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := &Repository{
+		Dir: dir,
+	}
+	// NOTE: Run `git init` twice is safe here.
+	if err := r.Init(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.EnsureRemote(ctx, remote, uri); err != nil {
+		return nil, err
+	}
+	if err := r.Fetch(ctx, remote); err != nil {
+		return nil, err
+	}
+	if branch == "" {
+		branch, err = r.DefaultBranch(ctx, remote)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := r.EnsureTrackingBranch(ctx, remote, branch); err != nil {
+		return nil, err
+	}
+	if err := r.SwitchBranch(ctx, branch); err != nil {
+		return nil, err
+	}
+	if err := r.Pull(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
 func (r *Repository) Init(ctx context.Context) error {
 	_, err := r.execute(ctx, "git", "init")
 	return err
@@ -54,19 +107,34 @@ func (r *Repository) Fetch(ctx context.Context, remote string) error {
 	return err
 }
 
+func (r *Repository) FetchRefspec(ctx context.Context, remote string, refspecs ...string) error {
+	_, err := r.execute(ctx, "git", append([]string{"fetch", remote}, refspecs...)...)
+	return err
+}
+
 func (r *Repository) Pull(ctx context.Context) error {
 	_, err := r.execute(ctx, "git", "pull")
 	return err
 }
 
 func (r *Repository) Log(ctx context.Context, formats ...string) (lines [][]string, err error) {
+	return r.LogRange(ctx, "", formats...)
+}
+
+// LogRange is like Log but lists commits of the given revision range. Empty
+// range means current branch.
+func (r *Repository) LogRange(ctx context.Context, rng string, formats ...string) (lines [][]string, err error) {
 	for _, f := range formats {
 		if f == "%n" {
 			return nil, fmt.Errorf("git: log: format %%n is non-supported")
 		}
 	}
 	format := strings.Join(formats, "%n")
-	out, err := r.execute(ctx, "git", "log", "--pretty="+format)
+	args := []string{"log", "--pretty=" + format}
+	if rng != "" {
+		args = append(args, rng, "--")
+	}
+	out, err := r.execute(ctx, "git", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -139,14 +207,337 @@ func (r *Repository) DefaultBranch(ctx context.Context, remote string) (string,
 	return "", fmt.Errorf("git: malformed output")
 }
 
-func (r *Repository) ChangedFiles(ctx context.Context, base, head string) ([]string, error) {
-	s, err := r.execute(ctx, "git", "diff", "--name-only", base+"..."+head)
+// ChangedFile describes a file changed between two revisions.
+type ChangedFile struct {
+	// Name is the path of the file at head. Deleted file has its base path.
+	Name string
+
+	// OldName is the path of the file at base if it was renamed or copied.
+	OldName string
+
+	// Status is the change status letter reported by git, such as 'M', 'A',
+	// 'D', 'R' or 'C'.
+	Status byte
+	Binary bool
+
+	// Added and Deleted hold numbers of added and deleted lines. They are
+	// zero for binary files.
+	Added   int
+	Deleted int
+
+	// BaseHash and HeadHash are blob hashes of the file versions. Hash is
+	// empty if the version doesn't exist.
+	BaseHash string
+	HeadHash string
+
+	// BaseSize and HeadSize are sizes of the file versions in bytes.
+	BaseSize int64
+	HeadSize int64
+}
+
+// ChangedFiles returns files changed in head since its merge base with base.
+// Renames and copies are detected.
+func (r *Repository) ChangedFiles(ctx context.Context, base, head string) ([]ChangedFile, error) {
+	s, err := r.execute(ctx, "git", "diff",
+		"--raw", "--numstat", "-z", "--no-abbrev", "--find-renames", "--find-copies",
+		base+"..."+head,
+	)
+	if err != nil {
+		return nil, err
+	}
+	fs, err := parseChangedFiles(s)
 	if err != nil {
 		return nil, err
 	}
+	var hashes []string
+	for _, f := range fs {
+		for _, h := range [2]string{f.BaseHash, f.HeadHash} {
+			if h != "" {
+				hashes = append(hashes, h)
+			}
+		}
+	}
+	sizes, err := r.blobSizes(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fs {
+		fs[i].BaseSize = sizes[fs[i].BaseHash]
+		fs[i].HeadSize = sizes[fs[i].HeadHash]
+	}
+	return fs, nil
+}
+
+// blobSizes returns sizes of the given blobs by their hashes. Missing objects,
+// such as commits of submodules, are omitted.
+func (r *Repository) blobSizes(ctx context.Context, hashes []string) (map[string]int64, error) {
+	sizes := make(map[string]int64, len(hashes))
+	if len(hashes) == 0 {
+		return sizes, nil
+	}
+	in := strings.Join(hashes, "\n") + "\n"
+	s, err := r.run(ctx, nil, strings.NewReader(in),
+		"git", "cat-file", "--batch-check=%(objectname) %(objectsize)",
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(s, "\n") {
+		xs := strings.Fields(line)
+		if len(xs) != 2 || xs[1] == "missing" {
+			continue
+		}
+		n, err := strconv.ParseInt(xs[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("git: malformed cat-file output: %q", line)
+		}
+		sizes[xs[0]] = n
+	}
+	return sizes, nil
+}
+
+// parseChangedFiles parses output of git diff --raw --numstat -z. Raw entries
+// go first and are followed by numstat entries in the same order.
+func parseChangedFiles(s string) ([]ChangedFile, error) {
+	var (
+		fs     []ChangedFile
+		fields = strings.Split(strings.TrimSuffix(s, "\x00"), "\x00")
+		stats  int
+	)
+	next := func() (string, error) {
+		if len(fields) == 0 {
+			return "", fmt.Errorf("git: malformed diff output")
+		}
+		f := fields[0]
+		fields = fields[1:]
+		return f, nil
+	}
+	for len(fields) > 0 && fields[0] != "" {
+		x, _ := next()
+		if strings.HasPrefix(x, ":") {
+			// Raw entry: ":<modes> <hashes> <status>" followed by one or two
+			// paths.
+			meta := strings.Fields(x)
+			if len(meta) != 5 {
+				return nil, fmt.Errorf("git: malformed raw diff entry: %q", x)
+			}
+			// Status of renames and copies is followed by similarity score.
+			f := ChangedFile{
+				Status:   meta[4][0],
+				BaseHash: blobHash(meta[2]),
+				HeadHash: blobHash(meta[3]),
+			}
+			name, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if f.Status == 'R' || f.Status == 'C' {
+				f.OldName = name
+				if name, err = next(); err != nil {
+					return nil, err
+				}
+			}
+			f.Name = name
+			fs = append(fs, f)
+			continue
+		}
+		// Numstat entry: "<added>\t<deleted>\t<path>". Path is empty for
+		// renames and copies and is followed by the old and new paths.
+		ns := strings.SplitN(x, "\t", 3)
+		if len(ns) != 3 || stats == len(fs) {
+			return nil, fmt.Errorf("git: malformed numstat diff entry: %q", x)
+		}
+		if ns[2] == "" {
+			for i := 0; i < 2; i++ {
+				if _, err := next(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		f := &fs[stats]
+		stats++
+		if ns[0] == "-" && ns[1] == "-" {
+			f.Binary = true
+			continue
+		}
+		var err error
+		if f.Added, err = strconv.Atoi(ns[0]); err != nil {
+			return nil, fmt.Errorf("git: malformed numstat diff entry: %q", x)
+		}
+		if f.Deleted, err = strconv.Atoi(ns[1]); err != nil {
+			return nil, fmt.Errorf("git: malformed numstat diff entry: %q", x)
+		}
+	}
+	return fs, nil
+}
+
+// blobHash returns hash of the raw diff entry or empty string if it is null.
+func blobHash(s string) string {
+	if strings.Trim(s, "0") == "" {
+		return ""
+	}
+	return s
+}
+
+// DiffFile streams unified diff of the file between base and head revisions
+// with given number of context lines.
+func (r *Repository) DiffFile(ctx context.Context, base, head, file string, unified int) (io.ReadCloser, error) {
+	return r.stream(ctx, "git", "diff",
+		"--no-color", "--no-ext-diff",
+		fmt.Sprintf("--unified=%d", unified),
+		base, head, "--", file,
+	)
+}
+
+func (r *Repository) RevParse(ctx context.Context, rev string) (string, error) {
+	return r.execute(ctx, "git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+}
+
+func (r *Repository) MergeBase(ctx context.Context, a, b string) (string, error) {
+	return r.execute(ctx, "git", "merge-base", a, b)
+}
+
+// Branches returns names of local branches.
+func (r *Repository) Branches(ctx context.Context) ([]string, error) {
+	s, err := r.execute(ctx, "git", "for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	if s == "" {
+		return nil, nil
+	}
 	return strings.Split(s, "\n"), nil
 }
 
+func (r *Repository) Config(ctx context.Context, key string) (string, error) {
+	return r.execute(ctx, "git", "config", "--get", key)
+}
+
+// ShowNote returns note attached to the object under the given notes ref.
+// It returns empty string if there is no note.
+func (r *Repository) ShowNote(ctx context.Context, ref, object string) (string, error) {
+	hash, err := r.execute(ctx, "git", "rev-parse", "--verify", object+"^{object}")
+	if err != nil {
+		return "", err
+	}
+	// NOTE: notes list of a single object exits with non-zero code if there
+	// is no note, which can't be told apart from other failures. Instead we
+	// list all notes which succeeds even if the notes ref doesn't exist.
+	s, err := r.execute(ctx, "git", "notes", "--ref", ref, "list")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(s, "\n") {
+		_, annotated := split2(line, ' ')
+		if annotated == hash {
+			return r.execute(ctx, "git", "notes", "--ref", ref, "show", hash)
+		}
+	}
+	return "", nil
+}
+
+// AppendNote appends text to the note attached to the object under the given
+// notes ref. Note is created if it doesn't exist.
+func (r *Repository) AppendNote(ctx context.Context, ref, object, text string) error {
+	_, err := r.execute(ctx, "git", "notes", "--ref", ref, "append", "-m", text, object)
+	return err
+}
+
+// Signature holds identity and time of the commit author.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+func (s Signature) env(prefix string) []string {
+	env := []string{
+		prefix + "_NAME=" + s.Name,
+		prefix + "_EMAIL=" + s.Email,
+	}
+	if !s.When.IsZero() {
+		env = append(env, prefix+"_DATE="+s.When.Format(time.RFC3339))
+	}
+	return env
+}
+
+// ApplyPatch applies patch on top of the base revision and creates a commit
+// with given message and author. It uses temporary index file and doesn't
+// touch the working tree. It returns hash of the created commit.
+func (r *Repository) ApplyPatch(ctx context.Context, base string, patch []byte, message string, author Signature) (string, error) {
+	tmp, err := ioutil.TempDir("", "rw-index")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	env := []string{
+		"GIT_INDEX_FILE=" + filepath.Join(tmp, "index"),
+	}
+	if _, err := r.run(ctx, env, nil, "git", "read-tree", base); err != nil {
+		return "", err
+	}
+	if _, err := r.run(ctx, env, bytes.NewReader(patch), "git", "apply", "--cached", "-"); err != nil {
+		return "", err
+	}
+	tree, err := r.run(ctx, env, nil, "git", "write-tree")
+	if err != nil {
+		return "", err
+	}
+	env = append(env, author.env("GIT_AUTHOR")...)
+	env = append(env, author.env("GIT_COMMITTER")...)
+	return r.run(ctx, env, nil, "git", "commit-tree", tree, "-p", base, "-m", message)
+}
+
+// HasFile reports whether file exists at given revision.
+func (r *Repository) HasFile(ctx context.Context, rev, file string) bool {
+	_, err := r.execute(ctx, "git", "cat-file", "-e", rev+":"+file)
+	return err == nil
+}
+
+// MergeFile merges changes made between base and other contents into the
+// current contents. It returns merged contents and false if there were
+// conflicts; conflicting hunks are marked in the returned contents.
+func MergeFile(ctx context.Context, current, base, other []byte) ([]byte, bool, error) {
+	tmp, err := ioutil.TempDir("", "rw-merge")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(tmp)
+
+	args := []string{"merge-file", "-p", "-q"}
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{"current", current},
+		{"base", base},
+		{"other", other},
+	} {
+		name := filepath.Join(tmp, f.name)
+		if err := ioutil.WriteFile(name, f.data, 0644); err != nil {
+			return nil, false, err
+		}
+		args = append(args, name)
+	}
+	var (
+		stdout bytes.Buffer
+		stderr bytes.Buffer
+	)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if x, ok := err.(*exec.ExitError); ok && x.ExitCode() > 0 && x.ExitCode() < 128 {
+		// Positive exit code is the number of conflicts.
+		return stdout.Bytes(), false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("exec `git %s` error: %v: %s", args, err, stderr.String())
+	}
+	return stdout.Bytes(), true, nil
+}
+
 func (r *Repository) ShowFile(ctx context.Context, branch, file string) (io.ReadCloser, error) {
 	return r.stream(ctx, "git", "show", branch+":"+file)
 }
@@ -199,6 +590,13 @@ func (r *Repository) CreateBranch(ctx context.Context, name string) error {
 	return err
 }
 
+// SetBranch makes branch to point to the given revision creating the branch
+// if needed. The branch must not be checked out.
+func (r *Repository) SetBranch(ctx context.Context, name, rev string) error {
+	_, err := r.execute(ctx, "git", "branch", "--force", name, rev)
+	return err
+}
+
 func (r *Repository) DeleteBranch(ctx context.Context, name string) error {
 	_, err := r.execute(ctx, "git", "branch", "-D", name)
 	return err
@@ -217,6 +615,59 @@ func (r *Repository) IsClean(ctx context.Context) (bool, error) {
 	return s == "", nil
 }
 
+// Checkout switches the working tree to the given revision on a temporary
+// branch. Returned cleanup function restores the original branch.
+func (r *Repository) Checkout(ctx context.Context, hash string) (root string, cleanup func() error, err error) {
+	origBranch, err := r.CurrentBranch(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if origBranch == "" {
+		return "", nil, fmt.Errorf("git: checkout: no current branch")
+	}
+	cleanup = func() error {
+		return r.SwitchBranch(ctx, origBranch)
+	}
+	defer func() {
+		if err != nil {
+			cleanup()
+		}
+	}()
+	clean, err := r.IsClean(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if !clean {
+		return "", nil, fmt.Errorf(
+			"git: can't checkout: working tree is not clean",
+		)
+	}
+	if err := r.SwitchBranch(ctx, hash); err != nil {
+		return "", nil, err
+	}
+	tmpBranch := fmt.Sprintf("review-%x", rand.Int63())
+	if err := r.CreateBranch(ctx, tmpBranch); err != nil {
+		return "", nil, err
+	}
+	log.Printf("using %s as root dir for checkout", r.Dir)
+	return r.Dir, func() error {
+		e0 := r.Restore(context.TODO(), ".")
+		e1 := r.Clean(context.TODO())
+		e2 := r.SwitchBranch(context.TODO(), origBranch)
+		e3 := r.DeleteBranch(context.TODO(), tmpBranch)
+		if e0 != nil {
+			return e0
+		}
+		if e1 != nil {
+			return e1
+		}
+		if e2 != nil {
+			return e2
+		}
+		return e3
+	}, nil
+}
+
 func (r *Repository) stream(ctx context.Context, name string, args ...string) (io.ReadCloser, error) {
 	log.Println("streaming", name, args)
 	cmd := exec.CommandContext(ctx, name, args...)
@@ -235,14 +686,24 @@ func (r *Repository) stream(ctx context.Context, name string, args ...string) (i
 }
 
 func (r *Repository) execute(ctx context.Context, name string, args ...string) (output string, err error) {
+	return r.run(ctx, nil, nil, name, args...)
+}
+
+// run executes command with given additional environment variables and
+// standard input.
+func (r *Repository) run(ctx context.Context, env []string, stdin io.Reader, name string, args ...string) (output string, err error) {
 	var (
 		stdout bytes.Buffer
 		stderr bytes.Buffer
 	)
 	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	cmd.Dir = r.Dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	defer func() {
 		str := output
 		if n := len(str); n > 128 {
@@ -0,0 +1,172 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/internal/gittest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseChangedFiles(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		out  []string
+		exp  []ChangedFile
+		err  bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "statuses",
+			out: []string{
+				":100644 100644 0ff3bbb d4de868 M", "a.txt",
+				":100644 100644 bdc955b 8835708 M", "bin",
+				":100644 100644 0ff3bbb d4de868 C094", "a.txt", "c.txt",
+				":100644 000000 587be6b 0000000 D", "d.txt",
+				":000000 100644 0000000 8ba3a16 A", "new.txt",
+				":100644 100644 e8823e1 10adcaf R096", "r.txt", "r 2.txt",
+				"1\t0\ta.txt",
+				"-\t-\tbin",
+				"1\t0\t", "a.txt", "c.txt",
+				"0\t1\td.txt",
+				"1\t0\tnew.txt",
+				"2\t1\t", "r.txt", "r 2.txt",
+			},
+			exp: []ChangedFile{
+				{Name: "a.txt", Status: 'M', Added: 1, BaseHash: "0ff3bbb", HeadHash: "d4de868"},
+				{Name: "bin", Status: 'M', Binary: true, BaseHash: "bdc955b", HeadHash: "8835708"},
+				{Name: "c.txt", OldName: "a.txt", Status: 'C', Added: 1, BaseHash: "0ff3bbb", HeadHash: "d4de868"},
+				{Name: "d.txt", Status: 'D', Deleted: 1, BaseHash: "587be6b"},
+				{Name: "new.txt", Status: 'A', Added: 1, HeadHash: "8ba3a16"},
+				{Name: "r 2.txt", OldName: "r.txt", Status: 'R', Added: 2, Deleted: 1, BaseHash: "e8823e1", HeadHash: "10adcaf"},
+			},
+		},
+		{
+			name: "missing path",
+			out: []string{
+				":100644 100644 e8823e1 10adcaf R096", "r.txt",
+			},
+			err: true,
+		},
+		{
+			name: "extra numstat",
+			out: []string{
+				":100644 100644 0ff3bbb d4de868 M", "a.txt",
+				"1\t0\ta.txt",
+				"1\t0\tb.txt",
+			},
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var out string
+			if len(test.out) > 0 {
+				out = strings.Join(test.out, "\x00") + "\x00"
+			}
+			act, err := parseChangedFiles(out)
+			if test.err {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(act, test.exp) {
+				t.Errorf("unexpected files:\n%s", cmp.Diff(test.exp, act))
+			}
+		})
+	}
+}
+
+func TestShowNote(t *testing.T) {
+	var (
+		ctx = context.Background()
+		dir = t.TempDir()
+		r   = &Repository{Dir: dir}
+	)
+	gittest.Run(t, dir, "init", "-q")
+	gittest.Run(t, dir, "commit", "-q", "--allow-empty", "-m", "first")
+	first := gittest.Run(t, dir, "rev-parse", "HEAD")
+	gittest.Run(t, dir, "commit", "-q", "--allow-empty", "-m", "second")
+
+	note, err := r.ShowNote(ctx, "rw", "HEAD")
+	if err != nil || note != "" {
+		t.Fatalf("unexpected note without notes ref: %q %v", note, err)
+	}
+	gittest.Run(t, dir, "notes", "--ref", "rw", "add", "-m", "hello", first)
+
+	for _, test := range []struct {
+		name   string
+		object string
+		exp    string
+		err    bool
+	}{
+		{
+			name:   "note",
+			object: "HEAD~1",
+			exp:    "hello",
+		},
+		{
+			name:   "no note",
+			object: "HEAD",
+		},
+		{
+			name:   "bad object",
+			object: "no-such-ref",
+			err:    true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			act, err := r.ShowNote(ctx, "rw", test.object)
+			if test.err != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if act != test.exp {
+				t.Errorf("unexpected note: %q; want %q", act, test.exp)
+			}
+		})
+	}
+}
+
+func TestChangedFilesSizes(t *testing.T) {
+	var (
+		ctx = context.Background()
+		dir = t.TempDir()
+		r   = &Repository{Dir: dir}
+	)
+	gittest.Run(t, dir, "init", "-q")
+	gittest.WriteFile(t, dir, "a.txt", "a\n")
+	gittest.WriteFile(t, dir, "d.txt", "deleted\n")
+	gittest.Run(t, dir, "add", ".")
+	gittest.Run(t, dir, "commit", "-q", "-m", "first")
+	gittest.WriteFile(t, dir, "a.txt", "aaa\n")
+	gittest.WriteFile(t, dir, "new.txt", "new file\n")
+	gittest.Run(t, dir, "rm", "-q", "d.txt")
+	gittest.Run(t, dir, "add", ".")
+	gittest.Run(t, dir, "commit", "-q", "-m", "second")
+
+	fs, err := r.ChangedFiles(ctx, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type size struct {
+		Base, Head int64
+	}
+	act := make(map[string]size)
+	for _, f := range fs {
+		act[f.Name] = size{f.BaseSize, f.HeadSize}
+	}
+	exp := map[string]size{
+		"a.txt":   {2, 4},
+		"d.txt":   {8, 0},
+		"new.txt": {0, 9},
+	}
+	if !cmp.Equal(act, exp) {
+		t.Errorf("unexpected sizes:\n%s", cmp.Diff(exp, act))
+	}
+}
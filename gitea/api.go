@@ -0,0 +1,165 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const apiPath = "/api/v1"
+
+type api struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+type apiError struct {
+	Method  string
+	Path    string
+	Status  string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf(
+		"gitea: %s %s: %s: %s",
+		e.Method, e.Path, e.Status, e.Message,
+	)
+}
+
+// do sends request to the Gitea API and decodes response body into result.
+func (a *api) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body, result interface{},
+) error {
+	var r io.Reader
+	if body != nil {
+		bts, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(bts)
+	}
+	u := strings.TrimSuffix(a.baseURL, "/") + apiPath + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t := a.token; t != "" {
+		req.Header.Set("Authorization", "token "+t)
+	}
+	log.Printf("gitea: %s %s", method, u)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		bts, _ := ioutil.ReadAll(resp.Body)
+		var msg struct {
+			Message string `json:"message"`
+		}
+		text := strings.TrimSpace(string(bts))
+		if json.Unmarshal(bts, &msg) == nil && msg.Message != "" {
+			text = msg.Message
+		}
+		return &apiError{
+			Method:  method,
+			Path:    path,
+			Status:  resp.Status,
+			Message: text,
+		}
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
+
+func repoPath(owner, repo string) string {
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+type apiUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+type apiRepository struct {
+	ID            int64  `json:"id"`
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	SSHURL        string `json:"ssh_url"`
+	CloneURL      string `json:"clone_url"`
+}
+
+type apiBranch struct {
+	Ref  string         `json:"ref"`
+	SHA  string         `json:"sha"`
+	Repo *apiRepository `json:"repo"`
+}
+
+type apiPullRequest struct {
+	ID                 int64      `json:"id"`
+	Number             int        `json:"number"`
+	Title              string     `json:"title"`
+	User               apiUser    `json:"user"`
+	Base               apiBranch  `json:"base"`
+	Head               apiBranch  `json:"head"`
+	MergeBase          string     `json:"merge_base"`
+	RequestedReviewers []*apiUser `json:"requested_reviewers"`
+}
+
+type apiReview struct {
+	ID       int64  `json:"id"`
+	State    string `json:"state"`
+	CommitID string `json:"commit_id"`
+	Stale    bool   `json:"stale"`
+}
+
+type apiReviewComment struct {
+	ID               int64     `json:"id"`
+	Body             string    `json:"body"`
+	User             apiUser   `json:"user"`
+	ReviewID         int64     `json:"pull_request_review_id"`
+	Path             string    `json:"path"`
+	CommitID         string    `json:"commit_id"`
+	Position         int       `json:"position"`
+	OriginalPosition int       `json:"original_position"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type apiCreateReviewComment struct {
+	Path        string `json:"path"`
+	Body        string `json:"body"`
+	OldPosition int    `json:"old_position"`
+	NewPosition int    `json:"new_position"`
+}
+
+type apiCreateReview struct {
+	Body     string                    `json:"body"`
+	Event    string                    `json:"event"`
+	CommitID string                    `json:"commit_id"`
+	Comments []*apiCreateReviewComment `json:"comments"`
+}
@@ -0,0 +1,111 @@
+package gitea
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+// byFile groups comments by file keeping their order.
+func byFile(cs []*comment) map[string][]vcs.Comment {
+	m := make(map[string][]vcs.Comment)
+	for _, x := range cs {
+		m[x.path] = append(m[x.path], x)
+	}
+	return m
+}
+
+// buildThreads links review comments into threads.
+//
+// Gitea has no notion of replies; instead all comments left on the same line
+// form a single conversation. That is, the earliest comment on the line
+// becomes a parent for the rest of comments there.
+func buildThreads(cs []*comment) []*comment {
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].createdAt.Before(cs[j].createdAt)
+	})
+	type key struct {
+		path string
+		side vcs.Side
+		line int
+	}
+	index := make(map[key]*comment)
+	for _, x := range cs {
+		k := key{x.path, x.side, x.line}
+		if p := index[k]; p != nil {
+			x.parent = p
+			continue
+		}
+		index[k] = x
+	}
+	return cs
+}
+
+type comment struct {
+	parent *comment
+
+	id        int64
+	reviewID  int64
+	body      string
+	line      int
+	createdAt time.Time
+	updatedAt time.Time
+	userLogin string
+	side      vcs.Side
+	path      string
+}
+
+func (c *comment) Lines() (lo, hi int) {
+	return c.line, c.line
+}
+func (c *comment) Body() string {
+	return c.body
+}
+func (c *comment) CreatedAt() time.Time {
+	return c.createdAt
+}
+func (c *comment) UpdatedAt() time.Time {
+	return c.updatedAt
+}
+func (c *comment) UserLogin() string {
+	return c.userLogin
+}
+func (c *comment) Side() vcs.Side {
+	return c.side
+}
+func (c *comment) Parent() vcs.Comment {
+	if c.parent != nil {
+		return c.parent
+	}
+	return nil
+}
+func (c *comment) ID() string {
+	return strconv.FormatInt(c.id, 10)
+}
+
+// reviewComment converts review comment returned by the API. It returns nil
+// if comment is not bound to a line.
+func reviewComment(x *apiReviewComment) *comment {
+	c := &comment{
+		id:        x.ID,
+		reviewID:  x.ReviewID,
+		body:      x.Body,
+		createdAt: x.CreatedAt,
+		updatedAt: x.UpdatedAt,
+		userLogin: x.User.Login,
+		path:      x.Path,
+	}
+	switch {
+	case x.Position > 0:
+		c.side = vcs.SideHead
+		c.line = x.Position
+	case x.OriginalPosition > 0:
+		c.side = vcs.SideBase
+		c.line = x.OriginalPosition
+	default:
+		return nil
+	}
+	return c
+}
@@ -0,0 +1,52 @@
+package gitea
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+func TestBuildThreads(t *testing.T) {
+	now := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	var cs []*comment
+	for i, x := range []*apiReviewComment{
+		{ID: 1, Path: "a.go", Position: 10, CreatedAt: now.Add(2 * time.Minute)},
+		{ID: 2, Path: "a.go", Position: 10, CreatedAt: now},
+		{ID: 3, Path: "a.go", OriginalPosition: 10, CreatedAt: now.Add(time.Minute)},
+		{ID: 4, Path: "b.go", Position: 10, CreatedAt: now.Add(3 * time.Minute)},
+		{ID: 5, Path: "a.go", CreatedAt: now},
+	} {
+		c := reviewComment(x)
+		if x.ID == 5 {
+			if c != nil {
+				t.Fatalf("unexpected comment for #%d: %+v", i, c)
+			}
+			continue
+		}
+		cs = append(cs, c)
+	}
+	cs = buildThreads(cs)
+
+	exp := map[string]string{
+		"1": "2",
+		"2": "",
+		"3": "",
+		"4": "",
+	}
+	for _, c := range cs {
+		var act string
+		if p := c.Parent(); p != nil {
+			act = p.ID()
+		}
+		if act != exp[c.ID()] {
+			t.Errorf(
+				"unexpected parent of #%s: %q; want %q",
+				c.ID(), act, exp[c.ID()],
+			)
+		}
+	}
+	if side := cs[1].Side(); side != vcs.SideBase {
+		t.Errorf("unexpected side of #%s: %s", cs[1].ID(), side)
+	}
+}
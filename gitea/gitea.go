@@ -0,0 +1,238 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/vcs"
+)
+
+type Client struct {
+	Project    string
+	CacheDir   string
+	URL        string
+	Token      string
+	Origin     string
+	Branch     string
+	User       string
+	PRID       int
+	PRTemplate string
+
+	// HTTPClient is used to make Gitea API requests.
+	// If nil then http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	once       sync.Once
+	api        api
+	user       apiUser
+	git        *git.Repository
+	owner      string
+	repo       string
+	err        error
+	prTemplate *template.Template
+}
+
+const cacheOrigin = "rw-origin"
+
+// pageLimit is a default maximum page size of Gitea API.
+const pageLimit = 50
+
+func (c *Client) Init(ctx context.Context) error {
+	c.once.Do(func() {
+		if c.URL == "" {
+			c.err = fmt.Errorf("gitea: instance url is not set")
+			return
+		}
+		c.api = api{
+			client:  c.HTTPClient,
+			baseURL: c.URL,
+			token:   c.Token,
+		}
+		if c.api.client == nil {
+			c.api.client = http.DefaultClient
+		}
+		if c.err = c.ping(ctx); c.err != nil {
+			return
+		}
+
+		c.prTemplate, c.err = template.New("pr").Parse(c.PRTemplate)
+		if c.err != nil {
+			return
+		}
+
+		branch := c.Branch
+		if p := c.Project; p != "" {
+			c.owner, c.repo = split2(p, '/')
+			if c.repo == "" {
+				c.err = fmt.Errorf("malformed project name: %q", p)
+				return
+			}
+		} else {
+			// Try to work with repo in process's current directory.
+			c.owner, c.repo, c.err = git.ShowRemote(ctx, c.Origin)
+			if c.err == nil {
+				branch, c.err = git.CurrentBranch(ctx)
+			}
+			if c.err != nil {
+				return
+			}
+		}
+		var repo apiRepository
+		c.err = c.api.do(ctx,
+			http.MethodGet, repoPath(c.owner, c.repo), nil,
+			nil, &repo,
+		)
+		if c.err != nil {
+			return
+		}
+
+		var dir string
+		if cache := c.CacheDir; cache != "" {
+			var u *url.URL
+			u, c.err = url.Parse(c.URL)
+			if c.err != nil {
+				return
+			}
+			dir = filepath.Join(cache, u.Host, c.owner, c.repo)
+		}
+		c.git, c.err = git.Cache(ctx, dir, cacheOrigin, repo.SSHURL, branch)
+	})
+	return c.err
+}
+
+func (c *Client) ping(ctx context.Context) error {
+	return c.api.do(ctx, http.MethodGet, "/user", nil, nil, &c.user)
+}
+
+func (c *Client) List(ctx context.Context, fn func(vcs.ReviewItem)) error {
+	if id := c.PRID; id != 0 {
+		pr, err := c.pullRequest(ctx, id)
+		if err != nil {
+			return err
+		}
+		fn(&item{
+			pr:       pr,
+			template: c.prTemplate,
+		})
+		return nil
+	}
+	query := url.Values{
+		"state": {"open"},
+		"sort":  {"oldest"},
+		"limit": {strconv.Itoa(pageLimit)},
+	}
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var prs []*apiPullRequest
+		err := c.api.do(ctx,
+			http.MethodGet, repoPath(c.owner, c.repo)+"/pulls", query,
+			nil, &prs,
+		)
+		if err != nil {
+			return err
+		}
+		for _, pr := range prs {
+			if !c.reviewRequested(pr) {
+				continue
+			}
+			fn(&item{
+				pr:       pr,
+				template: c.prTemplate,
+			})
+		}
+		if len(prs) < pageLimit {
+			break
+		}
+	}
+	return nil
+}
+
+func (c *Client) reviewRequested(pr *apiPullRequest) bool {
+	user := c.User
+	if user == "" {
+		user = c.user.Login
+	}
+	for _, r := range pr.RequestedReviewers {
+		if strings.EqualFold(r.Login, user) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) Select(ctx context.Context, x vcs.ReviewItem) (vcs.Review, error) {
+	switch v := x.(type) {
+	case *item:
+		num := v.pr.Number
+		// Gitea exposes head of each pull request in the base repository,
+		// so there is no need to add remotes for forks.
+		err := c.git.FetchRefspec(ctx, cacheOrigin, fmt.Sprintf(
+			"+refs/pull/%d/head:refs/remotes/%s/pull/%d",
+			num, cacheOrigin, num,
+		))
+		if err != nil {
+			return nil, err
+		}
+		return &pullRequest{
+			c:  c,
+			pr: v.pr,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("gitea: select: unsupported type: %T", v)
+	}
+}
+
+func (c *Client) pullRequest(ctx context.Context, num int) (*apiPullRequest, error) {
+	pr := new(apiPullRequest)
+	err := c.api.do(ctx,
+		http.MethodGet, repoPath(c.owner, c.repo)+"/pulls/"+strconv.Itoa(num), nil,
+		nil, pr,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+type item struct {
+	pr       *apiPullRequest
+	template *template.Template
+}
+
+func (s *item) String() string {
+	var sb strings.Builder
+	err := s.template.Execute(&sb, s)
+	if err != nil {
+		panic(err)
+	}
+	return sb.String()
+}
+
+func (s *item) Number() int {
+	return s.pr.Number
+}
+
+func (s *item) UserLogin() string {
+	return s.pr.User.Login
+}
+
+func (s *item) Title() string {
+	return s.pr.Title
+}
+
+func split2(s string, c byte) (string, string) {
+	i := strings.IndexByte(s, c)
+	if i == -1 {
+		return s, ""
+	}
+	return s[:i], s[i+1:]
+}
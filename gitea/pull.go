@@ -0,0 +1,274 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/rw/ed"
+	"github.com/gobwas/rw/internal/gitvcs"
+	"github.com/gobwas/rw/vcs"
+)
+
+type pullRequest struct {
+	c  *Client
+	pr *apiPullRequest
+
+	comments vcs.CommentCache
+}
+
+func (p *pullRequest) Close() error {
+	return p.comments.Close()
+}
+
+func (p *pullRequest) String() string {
+	return fmt.Sprintf("%s/%s#%d", p.c.owner, p.c.repo, p.pr.Number)
+}
+
+func (p *pullRequest) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return gitvcs.ChangedFiles(p.c.git.ChangedFiles(ctx, p.base(), p.head()))
+}
+
+func (p *pullRequest) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
+	p.comments.Fetch(func(ctx context.Context) (map[string][]vcs.Comment, error) {
+		var (
+			ret []*comment
+			rs  []*apiReview
+		)
+		query := url.Values{
+			"limit": {strconv.Itoa(pageLimit)},
+		}
+		for page := 1; ; page++ {
+			query.Set("page", strconv.Itoa(page))
+			var xs []*apiReview
+			err := p.c.api.do(ctx,
+				http.MethodGet, p.path()+"/reviews", query,
+				nil, &xs,
+			)
+			if err != nil {
+				return nil, err
+			}
+			rs = append(rs, xs...)
+			if len(xs) < pageLimit {
+				break
+			}
+		}
+		for _, r := range rs {
+			if r.Stale || r.CommitID != p.pr.Head.SHA {
+				// Outdated.
+				continue
+			}
+			cs, err := p.reviewComments(ctx, r.ID)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, cs...)
+		}
+		return byFile(buildThreads(ret)), nil
+	})
+	return p.comments.File(ctx, file)
+}
+
+func (p *pullRequest) reviewComments(ctx context.Context, reviewID int64) ([]*comment, error) {
+	var (
+		cs    []*apiReviewComment
+		query = url.Values{
+			"limit": {strconv.Itoa(pageLimit)},
+		}
+	)
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var xs []*apiReviewComment
+		err := p.c.api.do(ctx,
+			http.MethodGet, p.reviewPath(reviewID)+"/comments", query,
+			nil, &xs,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(cs) > 0 && len(xs) > 0 && xs[0].ID == cs[0].ID {
+			// Server doesn't paginate review comments and returned the
+			// same list again.
+			break
+		}
+		cs = append(cs, xs...)
+		if len(xs) < pageLimit {
+			break
+		}
+	}
+	ret := make([]*comment, 0, len(cs))
+	for _, x := range cs {
+		c := reviewComment(x)
+		if c == nil {
+			// Comment not for the line.
+			continue
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+func (p *pullRequest) BaseName() string {
+	return p.base()
+}
+func (p *pullRequest) HeadName() string {
+	return p.head()
+}
+
+func (p *pullRequest) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return p.c.git.ShowFile(ctx, p.base(), file)
+}
+func (p *pullRequest) HeadFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return p.c.git.ShowFile(ctx, p.head(), file)
+}
+
+func (p *pullRequest) Checkout(ctx context.Context) (dir string, cleanup func() error, err error) {
+	return p.c.git.Checkout(ctx, p.pr.Head.SHA)
+}
+
+func (p *pullRequest) Edit(ctx context.Context, file string, c ed.Command) (err error) {
+	log.Printf("editing file %s", file)
+	var body string
+	switch c.Mode {
+	case ed.ModeAdd:
+		body = string(bytes.TrimSpace(c.Text))
+		if body == "" {
+			log.Println("empty comment; skipping")
+			return nil
+		}
+	case ed.ModeChange:
+		body = fmt.Sprintf(
+			"Suggested replacement for lines %d-%d:\n\n%s",
+			c.Start, c.End, quote(c.Text),
+		)
+	case ed.ModeDelete:
+		body = fmt.Sprintf("Suggested removal of lines %d-%d.", c.Start, c.End)
+	default:
+		return nil
+	}
+	_, err = p.Comment(ctx, file, vcs.SideHead, c.Start, c.End, body)
+	return err
+}
+
+func (p *pullRequest) Comment(
+	ctx context.Context,
+	file string,
+	side vcs.Side,
+	lo, hi int,
+	body string,
+) (vcs.Comment, error) {
+	if lo < hi {
+		// Gitea doesn't support multi-line comments, so bind comment to the
+		// last line of the range as GitHub does.
+		log.Printf(
+			"gitea: multi-line comment %s:%d-%d is bound to line %d",
+			file, lo, hi, hi,
+		)
+	}
+	c, err := p.createComment(ctx, file, side, hi, body)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *pullRequest) ReplyTo(ctx context.Context, parent vcs.Comment, body string) (vcs.Comment, error) {
+	root := parent.(*comment)
+	if root.parent != nil {
+		root = root.parent
+	}
+	// Comments on the same line form a conversation, so reply is just a
+	// comment on the parent's line. It's added to the parent's review to not
+	// create a new review per each reply.
+	var x apiReviewComment
+	err := p.c.api.do(ctx,
+		http.MethodPost, p.reviewPath(root.reviewID)+"/comments", nil,
+		newReviewComment(root.path, root.side, root.line, body),
+		&x,
+	)
+	if err != nil {
+		return nil, err
+	}
+	c := reviewComment(&x)
+	if c == nil {
+		return nil, fmt.Errorf("gitea: created reply is not bound to a line")
+	}
+	c.parent = root
+	return c, nil
+}
+
+func (p *pullRequest) createComment(ctx context.Context, file string, side vcs.Side, line int, body string) (*comment, error) {
+	var r apiReview
+	err := p.c.api.do(ctx,
+		http.MethodPost, p.path()+"/reviews", nil,
+		&apiCreateReview{
+			Event:    "COMMENT",
+			CommitID: p.pr.Head.SHA,
+			Comments: []*apiCreateReviewComment{
+				newReviewComment(file, side, line, body),
+			},
+		},
+		&r,
+	)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := p.reviewComments(ctx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("gitea: created review has no comments")
+	}
+	return cs[0], nil
+}
+
+// quote returns text as a markdown block quote. Gitea doesn't apply GitHub's
+// suggestion blocks, so suggested text is quoted as is.
+func quote(text []byte) string {
+	var sb strings.Builder
+	for _, line := range bytes.SplitAfter(bytes.TrimSuffix(text, []byte("\n")), []byte("\n")) {
+		sb.WriteString("> ")
+		sb.Write(line)
+	}
+	return sb.String()
+}
+
+func newReviewComment(file string, side vcs.Side, line int, body string) *apiCreateReviewComment {
+	req := &apiCreateReviewComment{
+		Path: file,
+		Body: body,
+	}
+	if side == vcs.SideBase {
+		req.OldPosition = line
+	} else {
+		req.NewPosition = line
+	}
+	return req
+}
+
+func (p *pullRequest) path() string {
+	return repoPath(p.c.owner, p.c.repo) + "/pulls/" + strconv.Itoa(p.pr.Number)
+}
+
+func (p *pullRequest) reviewPath(id int64) string {
+	return p.path() + "/reviews/" + url.PathEscape(strconv.FormatInt(id, 10))
+}
+
+func (p *pullRequest) base() string {
+	if b := p.pr.MergeBase; b != "" {
+		return b
+	}
+	return path.Join(cacheOrigin, p.pr.Base.Ref)
+}
+
+func (p *pullRequest) head() string {
+	return p.pr.Head.SHA
+}
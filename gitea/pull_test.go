@@ -0,0 +1,159 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPullRequestReplyTo(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var req apiCreateReviewComment
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("can't decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(&apiReviewComment{
+			ID:               2,
+			Body:             req.Body,
+			ReviewID:         7,
+			Path:             req.Path,
+			OriginalPosition: req.OldPosition,
+			CreatedAt:        time.Now(),
+		})
+	}))
+	defer srv.Close()
+
+	p := &pullRequest{
+		c: &Client{
+			api: api{
+				client:  srv.Client(),
+				baseURL: srv.URL,
+			},
+			owner: "owner",
+			repo:  "repo",
+		},
+		pr: &apiPullRequest{
+			Number: 1,
+		},
+	}
+	root := &comment{
+		id:       1,
+		reviewID: 7,
+		path:     "a.go",
+		side:     vcs.SideBase,
+		line:     10,
+	}
+	x, err := p.ReplyTo(context.Background(), root, "reply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{
+		"POST /api/v1/repos/owner/repo/pulls/1/reviews/7/comments",
+	}
+	if !cmp.Equal(requests, exp) {
+		t.Errorf("unexpected requests:\n%s", cmp.Diff(exp, requests))
+	}
+	if p := x.Parent(); p != root {
+		t.Errorf("unexpected parent: %v", p)
+	}
+	if lo, hi := x.Lines(); lo != 10 || hi != 10 || x.Side() != vcs.SideBase {
+		t.Errorf("unexpected reply location: %s %d-%d", x.Side(), lo, hi)
+	}
+}
+
+func TestPullRequestFileCommentsPages(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.Query().Get("page"))
+		if r.URL.Path != "/api/v1/repos/owner/repo/pulls/1/reviews" {
+			json.NewEncoder(w).Encode([]*apiReviewComment{})
+			return
+		}
+		var rs []*apiReview
+		if r.URL.Query().Get("page") == "1" {
+			for i := 0; i < pageLimit; i++ {
+				rs = append(rs, &apiReview{ID: int64(i), Stale: true})
+			}
+		} else {
+			rs = append(rs, &apiReview{ID: 100, CommitID: "head"})
+		}
+		json.NewEncoder(w).Encode(rs)
+	}))
+	defer srv.Close()
+
+	p := &pullRequest{
+		c: &Client{
+			api: api{
+				client:  srv.Client(),
+				baseURL: srv.URL,
+			},
+			owner: "owner",
+			repo:  "repo",
+		},
+		pr: &apiPullRequest{
+			Number: 1,
+			Head:   apiBranch{SHA: "head"},
+		},
+	}
+	defer p.Close()
+	if _, err := p.FileComments(context.Background(), "a.go"); err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{
+		"/api/v1/repos/owner/repo/pulls/1/reviews?1",
+		"/api/v1/repos/owner/repo/pulls/1/reviews?2",
+		"/api/v1/repos/owner/repo/pulls/1/reviews/100/comments?1",
+	}
+	if !cmp.Equal(requests, exp) {
+		t.Errorf("unexpected requests:\n%s", cmp.Diff(exp, requests))
+	}
+}
+
+func TestClientReviewRequested(t *testing.T) {
+	pr := &apiPullRequest{
+		RequestedReviewers: []*apiUser{
+			{Login: "Alice"},
+		},
+	}
+	for _, test := range []struct {
+		name string
+		user string
+		self string
+		exp  bool
+	}{
+		{
+			name: "authenticated user",
+			self: "alice",
+			exp:  true,
+		},
+		{
+			name: "other authenticated user",
+			self: "bob",
+			exp:  false,
+		},
+		{
+			name: "explicit user",
+			user: "alice",
+			self: "bob",
+			exp:  true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{
+				User: test.user,
+				user: apiUser{Login: test.self},
+			}
+			if act := c.reviewRequested(pr); act != test.exp {
+				t.Errorf("unexpected result: %t; want %t", act, test.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package gitea
+
+import (
+	"flag"
+)
+
+func DefineFlags(c *Client, fs *flag.FlagSet) {
+	fs.StringVar(&c.Token,
+		"token", "",
+		"personal api token",
+	)
+	fs.StringVar(&c.URL,
+		"url", "",
+		"gitea instance url",
+	)
+	fs.StringVar(&c.User,
+		"user", "",
+		"user to whom review is requested (authenticated user by default)",
+	)
+	fs.IntVar(&c.PRID,
+		"pr", 0,
+		"pull request id",
+	)
+	fs.StringVar(&c.PRTemplate,
+		"pr-template", `#{{ .Number }} @{{ .UserLogin }}: {{ .Title }}`,
+		"pull request template",
+	)
+	fs.StringVar(&c.Origin,
+		"origin", "origin",
+		"name of the git remote upstream to use",
+	)
+}
@@ -1,84 +1,31 @@
 package github
 
 import (
-	"context"
 	"sort"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/gobwas/rw/vcs"
 )
 
-type comments struct {
-	once   sync.Once
-	done   chan struct{}
-	err    error
-	m      map[string][]vcs.Comment
-	ctx    context.Context
-	cancel context.CancelFunc
-}
-
-func (c *comments) init() (first bool) {
-	c.once.Do(func() {
-		first = true
-		c.done = make(chan struct{})
-		c.m = make(map[string][]vcs.Comment)
-		c.ctx, c.cancel = context.WithCancel(context.Background())
-	})
-	return
-}
-
-func (c *comments) File(ctx context.Context, file string) ([]vcs.Comment, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-c.done:
-		return c.m[file], nil
-	}
-}
-
-func (c *comments) Close() error {
-	var dummy bool
-	c.once.Do(func() {
-		dummy = true
+// fileComments links replies to their parents and groups comments by file.
+func fileComments(cs []*comment) map[string][]vcs.Comment {
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].createdAt.Before(cs[j].createdAt)
 	})
-	if !dummy {
-		c.cancel()
-		<-c.done
-	}
-	return nil
-}
-
-func (c *comments) Fetch(fetch func(ctx context.Context) ([]*comment, error)) {
-	if !c.init() {
-		return
-	}
-	go func() {
-		defer close(c.done)
-		cs, err := fetch(c.ctx)
-		if err != nil {
-			c.err = err
-			return
-		}
-		sort.Slice(cs, func(i, j int) bool {
-			return cs[i].createdAt.Before(cs[j].createdAt)
-		})
-		index := make(map[int64]*comment)
-		for _, x := range cs {
-			//b := bytes.NewBuffer(nil)
-			//e := json.NewEncoder(b)
-			//e.SetIndent("", "  ")
-			//e.Encode(c)
-			//log.Printf("COMMENT FOR %s:\n%s\n\n", *c.Path, b.String())
-			if x.parentID != 0 {
-				// NOTE: we sorted comments sorted by creation date above.
-				x.parent = index[x.parentID]
-			}
-			index[x.id] = x
-			c.m[x.path] = append(c.m[x.path], x)
+	var (
+		ret   = make(map[string][]vcs.Comment)
+		index = make(map[int64]*comment)
+	)
+	for _, x := range cs {
+		if x.parentID != 0 {
+			// NOTE: we sorted comments sorted by creation date above.
+			x.parent = index[x.parentID]
 		}
-	}()
+		index[x.id] = x
+		ret[x.path] = append(ret[x.path], x)
+	}
+	return ret
 }
 
 type comment struct {
@@ -94,6 +41,24 @@ type comment struct {
 	side      vcs.Side
 	parentID  int64
 	path      string
+
+	// Fields below are set for comments made on previous versions of the
+	// pull request.
+	outdated          bool
+	originalCommitID  string
+	originalStartLine int
+	originalLine      int
+
+	// Fields below are set for the first comment of the thread only.
+	threadID string
+	resolved bool
+}
+
+func (c *comment) root() *comment {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
 }
 
 func (c *comment) Lines() (lo, hi int) {
@@ -133,6 +98,12 @@ func (c *comment) Parent() vcs.Comment {
 func (c *comment) ID() string {
 	return strconv.FormatInt(c.id, 10)
 }
+func (c *comment) Outdated() bool {
+	return c.outdated
+}
+func (c *comment) Resolved() bool {
+	return c.root().resolved
+}
 
 func parseSide(s *string) vcs.Side {
 	if s != nil && *s == "LEFT" {
@@ -148,6 +119,13 @@ func parseInt(n *int) int {
 	return *n
 }
 
+func parseString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func parseInt64(n *int64) int64 {
 	if n == nil {
 		return 0
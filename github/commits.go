@@ -0,0 +1,225 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/gobwas/rw/diffutil"
+	"github.com/gobwas/rw/ed"
+	"github.com/gobwas/rw/internal/gitvcs"
+	"github.com/gobwas/rw/vcs"
+)
+
+// Commits implements vcs.CommitLister. Commits are listed from the oldest to
+// the newest one.
+func (p *pullRequest) Commits(ctx context.Context) ([]vcs.Review, error) {
+	lines, err := p.c.git.LogRange(ctx, p.base()+".."+p.head(), "%P", "%H", "%h", "%aL", "%s")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]vcs.Review, len(lines))
+	for i, line := range lines {
+		// Merge commits are reviewed against their first parent.
+		parent, _ := split2(line[0], ' ')
+		ret[len(lines)-i-1] = &prCommit{
+			p: p,
+			commit: &commit{
+				parentHash: parent,
+				hash:       line[1],
+				shortHash:  line[2],
+				email:      line[3],
+				title:      line[4],
+			},
+			rel: relocator{p: p},
+		}
+	}
+	return ret, nil
+}
+
+// prCommit is a review of a single commit of the pull request. Comments are
+// made on the pull request: lines of the commit are mapped onto the lines of
+// the pull request head and vice versa.
+type prCommit struct {
+	p      *pullRequest
+	commit *commit
+	rel    relocator
+}
+
+func (c *prCommit) String() string {
+	return c.commit.String()
+}
+
+func (c *prCommit) Close() error {
+	return nil
+}
+
+func (c *prCommit) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return gitvcs.ChangedFiles(c.p.c.git.ChangedFiles(ctx, c.commit.parentHash, c.commit.hash))
+}
+
+func (c *prCommit) BaseName() string {
+	return c.commit.parentHash
+}
+func (c *prCommit) HeadName() string {
+	return c.commit.hash
+}
+
+func (c *prCommit) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return c.p.c.git.ShowFile(ctx, c.commit.parentHash, file)
+}
+func (c *prCommit) HeadFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return c.p.c.git.ShowFile(ctx, c.commit.hash, file)
+}
+
+func (c *prCommit) Checkout(ctx context.Context) (dir string, cleanup func() error, err error) {
+	return c.p.c.git.Checkout(ctx, c.commit.hash)
+}
+
+// FileComments returns comments of the pull request which lines exist in the
+// commit. Lines of returned comments are the lines of the commit.
+func (c *prCommit) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
+	cs, err := c.p.FileComments(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		ret   []vcs.Comment
+		index = make(map[int64]*comment)
+	)
+	for _, x := range cs {
+		var (
+			pc = x.(*comment)
+			cp = *pc
+		)
+		switch {
+		case pc.parent != nil:
+			// Replies follow their parent comment. Comments are ordered by
+			// creation time, so the parent is already mapped.
+			parent, has := index[pc.parent.id]
+			if !has {
+				continue
+			}
+			cp.startLine = parent.startLine
+			cp.line = parent.line
+
+		case pc.line == 0:
+			// Orphaned outdated comment.
+			continue
+
+		default:
+			from, to := c.p.head(), c.commit.hash
+			if pc.side == vcs.SideBase {
+				from, to = c.p.base(), c.commit.parentHash
+			}
+			lo, hi, ok := c.mapLines(ctx, file, from, to, pc.startLine, pc.line)
+			if !ok {
+				continue
+			}
+			cp.startLine = lo
+			cp.line = hi
+		}
+		index[cp.id] = &cp
+		ret = append(ret, &cp)
+	}
+	return ret, nil
+}
+
+// Comment comments the pull request lines which correspond to the lines
+// lo..hi of the commit. It returns error if the lines don't exist at the pull
+// request anymore.
+func (c *prCommit) Comment(ctx context.Context, file string, side vcs.Side, lo, hi int, body string) (vcs.Comment, error) {
+	from, to := c.commit.hash, c.p.head()
+	if side == vcs.SideBase {
+		from, to = c.commit.parentHash, c.p.base()
+	}
+	start, line, ok := c.mapLines(ctx, file, from, to, lo, hi)
+	if !ok {
+		return nil, fmt.Errorf(
+			"github: %s line(s) %d-%d of %s at %s don't exist in the pull request",
+			side, lo, hi, file, c.commit.shortHash,
+		)
+	}
+	if start == 0 {
+		start = line
+	}
+	x, err := c.p.Comment(ctx, file, side, start, line, body)
+	if err != nil {
+		return nil, err
+	}
+	cp := *x.(*comment)
+	cp.startLine = 0
+	cp.line = hi
+	if lo < hi {
+		cp.startLine = lo
+	}
+	return &cp, nil
+}
+
+// ReplyTo replies to the pull request comment. Reply is placed at the lines
+// of the parent comment.
+func (c *prCommit) ReplyTo(ctx context.Context, parent vcs.Comment, body string) (vcs.Comment, error) {
+	x, err := c.p.ReplyTo(ctx, parent, body)
+	if err != nil {
+		return nil, err
+	}
+	cp := *x.(*comment)
+	cp.startLine, cp.line = parent.Lines()
+	if cp.startLine == cp.line {
+		cp.startLine = 0
+	}
+	return &cp, nil
+}
+
+// Edit maps lines of the command onto the pull request head.
+func (c *prCommit) Edit(ctx context.Context, file string, cmd ed.Command) error {
+	var (
+		from = c.commit.hash
+		to   = c.p.head()
+
+		start, end int
+		ok         bool
+	)
+	if cmd.End == 0 {
+		_, start, ok = c.mapLines(ctx, file, from, to, 0, cmd.Start)
+	} else if start, end, ok = c.mapLines(ctx, file, from, to, cmd.Start, cmd.End); ok && start == 0 {
+		// Range collapsed into a single line.
+		start = end
+	}
+	if !ok {
+		return fmt.Errorf(
+			"github: line %d of %s at %s doesn't exist in the pull request",
+			cmd.Start, file, c.commit.shortHash,
+		)
+	}
+	cmd.Start, cmd.End = start, end
+	return c.p.Edit(ctx, file, cmd)
+}
+
+// mapLines maps lines lo..hi of the file version at revision from onto the
+// version at revision to. Zero lo means a single line hi. It returns false if
+// any of the lines was changed or the file doesn't exist at any revision.
+func (c *prCommit) mapLines(ctx context.Context, file, from, to string, lo, hi int) (_, _ int, ok bool) {
+	g := c.p.c.git
+	if !g.HasFile(ctx, from, file) || !g.HasFile(ctx, to, file) {
+		return 0, 0, false
+	}
+	ms, err := c.rel.matchRevisions(ctx, from, to, file)
+	if err != nil {
+		log.Printf("can't match %s at %s and %s: %v", file, from, to, err)
+		return 0, 0, false
+	}
+	if hi, ok = diffutil.MapLine(ms, hi); !ok {
+		return 0, 0, false
+	}
+	if lo != 0 {
+		if lo, ok = diffutil.MapLine(ms, lo); !ok {
+			return 0, 0, false
+		}
+		if lo == hi {
+			lo = 0
+		}
+	}
+	return lo, hi, true
+}
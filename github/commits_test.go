@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/internal/gittest"
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v39/github"
+)
+
+func TestPullRequestCommits(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	lines := func(xs ...string) string {
+		return strings.Join(xs, "\n") + "\n"
+	}
+	gittest.Run(t, dir, "init", "-q", "-b", "main")
+	gittest.WriteFile(t, dir, "a.txt", lines("1", "2", "3", "4", "5", "6", "7", "8", "9"))
+	gittest.Run(t, dir, "add", "-A")
+	gittest.Run(t, dir, "commit", "-q", "-m", "initial")
+
+	gittest.Run(t, dir, "checkout", "-q", "-b", "feature")
+	gittest.WriteFile(t, dir, "a.txt", lines("1", "2", "3", "new", "4", "5", "6", "7", "8", "9"))
+	gittest.Run(t, dir, "commit", "-q", "-am", "first")
+	gittest.WriteFile(t, dir, "a.txt", lines("0", "1", "2", "3", "new", "4", "5", "6", "seven", "8", "9"))
+	gittest.Run(t, dir, "commit", "-q", "-am", "second")
+	head := gittest.Run(t, dir, "rev-parse", "HEAD")
+
+	p := &pullRequest{
+		c: &Client{
+			git: &git.Repository{Dir: dir},
+		},
+		pr: &github.PullRequest{
+			Base: &github.PullRequestBranch{
+				Ref: github.String("main"),
+			},
+			Head: &github.PullRequestBranch{
+				Ref: github.String("feature"),
+				SHA: &head,
+			},
+		},
+		pending: new(pendingReview),
+	}
+	root := &comment{id: 1, line: 5, side: vcs.SideHead, path: "a.txt"}
+	p.comments.Fetch(func(context.Context) (map[string][]vcs.Comment, error) {
+		return fileComments([]*comment{
+			root,
+			{id: 2, line: 5, side: vcs.SideHead, path: "a.txt", parent: root},
+			{id: 3, line: 9, side: vcs.SideHead, path: "a.txt"},
+			{id: 4, startLine: 1, line: 2, side: vcs.SideBase, path: "a.txt"},
+		}), nil
+	})
+
+	commits, err := p.Commits(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, c := range commits {
+		titles = append(titles, c.(*prCommit).commit.title)
+	}
+	if exp := []string{"first", "second"}; !cmp.Equal(titles, exp) {
+		t.Fatalf("unexpected commits:\n%s", cmp.Diff(exp, titles))
+	}
+	first := commits[0]
+
+	cs, err := first.FileComments(ctx, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type commentLines struct {
+		ID     string
+		Lo, Hi int
+	}
+	var act []commentLines
+	for _, c := range cs {
+		lo, hi := c.Lines()
+		act = append(act, commentLines{c.ID(), lo, hi})
+	}
+	exp := []commentLines{
+		{"1", 4, 4},
+		{"2", 4, 4},
+		{"4", 1, 2},
+	}
+	if !cmp.Equal(act, exp) {
+		t.Errorf("unexpected comment lines:\n%s", cmp.Diff(exp, act))
+	}
+
+	// Line "new" is the line 4 of the first commit and the line 5 of the
+	// pull request head.
+	c, err := first.Comment(ctx, "a.txt", vcs.SideHead, 4, 4, "comment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lo, hi := c.Lines(); lo != 4 || hi != 4 {
+		t.Errorf("unexpected lines of created comment: %d-%d; want 4-4", lo, hi)
+	}
+	if n := len(p.pending.comments); n != 1 {
+		t.Fatalf("unexpected number of drafts: %d", n)
+	}
+	if line := p.pending.comments[0].line; line != 5 {
+		t.Errorf("unexpected line of pull request comment: %d; want 5", line)
+	}
+
+	// Line "7" was changed by the second commit.
+	if _, err := first.Comment(ctx, "a.txt", vcs.SideHead, 7, 8, "comment"); err == nil {
+		t.Errorf("want error for the line missing at the pull request head")
+	}
+}
@@ -1,10 +1,17 @@
 package github
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"sync"
 
 	"github.com/gobwas/rw/ed"
+	"github.com/gobwas/rw/internal/gitvcs"
 	"github.com/gobwas/rw/vcs"
 	"github.com/google/go-github/v39/github"
 )
@@ -14,7 +21,10 @@ type diff struct {
 	commit *commit
 	remote string
 
-	comments comments
+	comments vcs.CommentCache
+
+	mu        sync.Mutex
+	positions map[string]positions
 }
 
 func (d *diff) String() string {
@@ -26,50 +36,181 @@ func (d *diff) Close() error {
 }
 
 func (d *diff) Comment(ctx context.Context, file string, side vcs.Side, lo, hi int, body string) (vcs.Comment, error) {
-	// TODO
-	return nil, nil
+	// NOTE: commit comments can't span multiple lines, so we bind comment to
+	// the last line of the range.
+	return d.comment(ctx, file, side, hi, body)
+}
+
+func (d *diff) comment(ctx context.Context, file string, side vcs.Side, line int, body string) (*comment, error) {
+	ps, err := d.filePositions(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	pos, ok := ps.position(side, line)
+	if !ok {
+		return nil, fmt.Errorf(
+			"github: %s line %d of %s is not a part of the diff",
+			side, line, file,
+		)
+	}
+	x, _, err := d.c.client.Repositories.CreateComment(
+		ctx, d.c.owner, d.c.repo, d.commit.hash,
+		&github.RepositoryComment{
+			Body:     &body,
+			Path:     &file,
+			Position: &pos,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return repoComment(x, side, line), nil
 }
 
-// FIXME: remove it
+// Edit comments the commit. Commit comments don't render suggestions, so
+// changes and deletions are posted as comments holding a patch of the
+// suggested change.
 func (d *diff) Edit(ctx context.Context, file string, cmd ed.Command) error {
+	switch cmd.Mode {
+	case ed.ModeAdd:
+		body := string(bytes.TrimSpace(cmd.Text))
+		if body == "" {
+			log.Println("empty comment; skipping")
+			return nil
+		}
+		_, err := d.comment(ctx, file, vcs.SideHead, cmd.Start, body)
+		return err
+
+	case ed.ModeChange, ed.ModeDelete:
+		lo, hi := cmd.Start, cmd.End
+		if hi == 0 {
+			hi = lo
+		}
+		src, err := showFile(ctx, d.c.git, d.HeadName(), file)
+		if err != nil {
+			return err
+		}
+		patch, err := suggestionPatch(file, src, lo, hi, cmd.Text)
+		if err != nil {
+			return err
+		}
+		body := fmt.Sprintf("Suggested change:\n```diff\n%s```", patch)
+		_, err = d.comment(ctx, file, vcs.SideHead, hi, body)
+		return err
+	}
 	return nil
 }
 
+// QuotedReplies implements vcs.QuotedReplies.
+func (d *diff) QuotedReplies() bool {
+	return true
+}
+
+// replyQuote is a prefix of the commit comment made as a reply. Commit
+// comments have no threads, so reply quotes a link to its parent comment.
+const replyQuote = "> In reply to [comment](#commitcomment-%d)\n\n"
+
+var replyQuoteRe = regexp.MustCompile(`^> In reply to \[comment\]\(#commitcomment-(\d+)\)\n\n`)
+
+// parseReply returns id of the parent comment quoted in the body of reply
+// and the body without the quote. It returns zero id if body is not a reply.
+func parseReply(body string) (int64, string) {
+	m := replyQuoteRe.FindStringSubmatch(body)
+	if m == nil {
+		return 0, body
+	}
+	id, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, body
+	}
+	return id, body[len(m[0]):]
+}
+
+// ReplyTo creates a comment at the same position as the parent comment.
+// Commit comments don't support replies, so the reply quotes its parent.
 func (d *diff) ReplyTo(ctx context.Context, p vcs.Comment, body string) (vcs.Comment, error) {
-	// TODO
-	return nil, nil
+	parent := p.(*comment).root()
+	x, err := d.comment(ctx, parent.path, parent.side, parent.line, fmt.Sprintf(replyQuote, parent.id)+body)
+	if err != nil {
+		return nil, err
+	}
+	x.parent = parent
+	x.parentID = parent.id
+	return x, nil
 }
 
 func (d *diff) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
-	d.comments.Fetch(func(ctx context.Context) ([]*comment, error) {
-		cs, _, err := d.c.client.Repositories.ListComments(
-			ctx, d.c.owner, d.c.repo, nil,
-		)
-		if err != nil {
-			return nil, err
+	d.comments.Fetch(func(ctx context.Context) (map[string][]vcs.Comment, error) {
+		var cs []*github.RepositoryComment
+		opts := &github.ListOptions{
+			PerPage: 100,
+		}
+		for {
+			xs, resp, err := d.c.client.Repositories.ListCommitComments(
+				ctx, d.c.owner, d.c.repo, d.commit.hash, opts,
+			)
+			if err != nil {
+				return nil, err
+			}
+			cs = append(cs, xs...)
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
 		}
 		ret := make([]*comment, 0, len(cs))
 		for _, c := range cs {
-			if *c.CommitID != d.commit.hash {
+			if c.Position == nil || c.Path == nil {
+				// Comment not for the file.
 				continue
 			}
-			if c.Position == nil {
-				// Comment not for the file.
+			ps, err := d.filePositions(ctx, *c.Path)
+			if err != nil {
+				return nil, err
+			}
+			side, line, ok := ps.line(*c.Position)
+			if !ok {
+				// Position is outside of the diff.
 				continue
 			}
-			ret = append(ret, repoComment(c))
+			ret = append(ret, repoComment(c, side, line))
 		}
-		return ret, nil
+		return fileComments(ret), nil
 	})
 	return d.comments.File(ctx, file)
 }
 
-func (d *diff) ChangedFiles(ctx context.Context) ([]string, error) {
-	return d.c.git.ChangedFiles(ctx, d.BaseName(), d.HeadName())
+// filePositions returns positions of the file's diff lines.
+func (d *diff) filePositions(ctx context.Context, file string) (positions, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if ps, has := d.positions[file]; has {
+		return ps, nil
+	}
+	r, err := d.c.git.DiffFile(ctx, d.BaseName(), d.HeadName(), file, 3)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := parsePositions(r)
+	if e := r.Close(); e != nil && err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	if d.positions == nil {
+		d.positions = make(map[string]positions)
+	}
+	d.positions[file] = ps
+	return ps, nil
+}
+
+func (d *diff) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return gitvcs.ChangedFiles(d.c.git.ChangedFiles(ctx, d.BaseName(), d.HeadName()))
 }
 
 func (d *diff) Checkout(ctx context.Context) (string, func() error, error) {
-	return checkout(ctx, d.c.git, d.commit.hash)
+	return d.c.git.Checkout(ctx, d.commit.hash)
 }
 
 func (d *diff) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
@@ -86,16 +227,17 @@ func (d *diff) HeadName() string {
 	return d.commit.hash
 }
 
-func repoComment(c *github.RepositoryComment) *comment {
+func repoComment(c *github.RepositoryComment, side vcs.Side, line int) *comment {
+	parentID, body := parseReply(*c.Body)
 	return &comment{
 		id:        *c.ID,
-		body:      *c.Body,
-		line:      *c.Position,
+		body:      body,
+		parentID:  parentID,
+		line:      line,
 		createdAt: *c.CreatedAt,
 		updatedAt: *c.UpdatedAt,
 		userLogin: *c.User.Login,
-		side:      vcs.SideBase,
+		side:      side,
 		path:      *c.Path,
-		//parentID:  parseInt64(c.InReplyTo),
 	}
 }
@@ -0,0 +1,42 @@
+package github
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseReply(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		body string
+		id   int64
+		exp  string
+	}{
+		{
+			name: "reply",
+			body: fmt.Sprintf(replyQuote, 42) + "body",
+			id:   42,
+			exp:  "body",
+		},
+		{
+			name: "not a reply",
+			body: "body",
+			exp:  "body",
+		},
+		{
+			name: "quote in the middle",
+			body: "body\n\n" + fmt.Sprintf(replyQuote, 42),
+			exp:  "body\n\n" + fmt.Sprintf(replyQuote, 42),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			id, body := parseReply(test.body)
+			if id != test.id || body != test.exp {
+				t.Errorf(
+					"unexpected reply: %d %q; want %d %q",
+					id, body, test.id, test.exp,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-github/v39/github"
+)
+
+// UserLogin implements vcs.CommentEditor.
+func (p *pullRequest) UserLogin(ctx context.Context) (string, error) {
+	return p.c.login(ctx)
+}
+
+// UpdateComment implements vcs.CommentEditor.
+func (p *pullRequest) UpdateComment(ctx context.Context, x vcs.Comment, body string) (vcs.Comment, error) {
+	c := x.(*comment)
+	if p.pending != nil && p.pending.update(c, body) {
+		return c, nil
+	}
+	r, _, err := p.c.client.PullRequests.EditComment(
+		ctx, p.c.owner, p.c.repo, c.id,
+		&github.PullRequestComment{
+			Body: &body,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: comment is updated in place to keep its relocated lines and
+	// links to the thread.
+	c.body = r.GetBody()
+	c.updatedAt = time.Now()
+	if r.UpdatedAt != nil {
+		c.updatedAt = *r.UpdatedAt
+	}
+	return c, nil
+}
+
+// DeleteComment implements vcs.CommentEditor.
+func (p *pullRequest) DeleteComment(ctx context.Context, x vcs.Comment) error {
+	c := x.(*comment)
+	if p.pending != nil && p.pending.remove(c) {
+		return nil
+	}
+	_, err := p.c.client.PullRequests.DeleteComment(ctx, p.c.owner, p.c.repo, c.id)
+	if err != nil {
+		return err
+	}
+	p.comments.Remove(c.path, c)
+	return nil
+}
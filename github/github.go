@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"html/template"
-	"io/ioutil"
 	"net/url"
-	"os"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -41,6 +39,9 @@ type Client struct {
 	repo       string
 	err        error
 	prTemplate *template.Template
+
+	mu   sync.Mutex
+	self string
 }
 
 const cacheOrigin = "rw-origin"
@@ -80,49 +81,12 @@ func (c *Client) Init(ctx context.Context) error {
 			}
 		}
 
-		// Unconditionally create temp dir with repo.
-		// We don't want to mutate remotes for existing repo.
 		var dir string
-		if cache := c.CacheDir; cache == "" {
-			dir, c.err = ioutil.TempDir("", "rw*")
-		} else {
+		if cache := c.CacheDir; cache != "" {
 			dir = filepath.Join(cache, c.owner, c.repo)
-			c.err = os.MkdirAll(dir, 0755)
-		}
-		if c.err != nil {
-			return
-		}
-
-		c.git = &git.Repository{
-			Dir: dir,
-		}
-		// NOTE: Run `git init` twice is safe here.
-		if c.err = c.git.Init(ctx); c.err != nil {
-			return
 		}
 		origin := fmt.Sprintf("git@github.com:%s/%s.git", c.owner, c.repo)
-		if c.err = c.git.EnsureRemote(ctx, cacheOrigin, origin); c.err != nil {
-			return
-		}
-		if c.err = c.git.Fetch(ctx, cacheOrigin); c.err != nil {
-			return
-		}
-
-		if branch == "" {
-			branch, c.err = c.git.DefaultBranch(ctx, cacheOrigin)
-			if c.err != nil {
-				return
-			}
-		}
-		c.err = c.git.EnsureTrackingBranch(ctx, cacheOrigin, branch)
-		if c.err != nil {
-			return
-		}
-		c.err = c.git.SwitchBranch(ctx, branch)
-		if c.err != nil {
-			return
-		}
-		c.err = c.git.Pull(ctx)
+		c.git, c.err = git.Cache(ctx, dir, cacheOrigin, origin, branch)
 		if c.err != nil {
 			return
 		}
@@ -140,6 +104,21 @@ func (c *Client) Init(ctx context.Context) error {
 	return c.err
 }
 
+// login returns login of the authenticated user.
+func (c *Client) login(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.self != "" {
+		return c.self, nil
+	}
+	user, _, err := c.client.Users.Get(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	c.self = user.GetLogin()
+	return c.self, nil
+}
+
 func (c *Client) ping(ctx context.Context) error {
 	octocat, _, err := c.client.Octocat(ctx, "")
 	if err != nil {
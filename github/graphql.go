@@ -0,0 +1,46 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type graphqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphqlError  `json:"errors"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// graphql executes GitHub GraphQL API query with given variables and decodes
+// its data into v.
+func (c *Client) graphql(ctx context.Context, query string, vars map[string]interface{}, v interface{}) error {
+	req, err := c.client.NewRequest("POST", "graphql", &graphqlRequest{
+		Query:     query,
+		Variables: vars,
+	})
+	if err != nil {
+		return err
+	}
+	var resp graphqlResponse
+	if _, err := c.client.Do(ctx, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			msgs[i] = e.Message
+		}
+		return fmt.Errorf("github: graphql error: %s", strings.Join(msgs, "; "))
+	}
+	return json.Unmarshal(resp.Data, v)
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v39/github"
+)
+
+// newGraphQLTestClient returns client of owner/repo which responds to i-th
+// GraphQL request with pages[i]. Received requests are appended to the
+// returned slice.
+func newGraphQLTestClient(t *testing.T, pages ...string) (*Client, *[]graphqlRequest) {
+	var reqs []graphqlRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("can't decode request: %v", err)
+			return
+		}
+		reqs = append(reqs, req)
+		if len(reqs) > len(pages) {
+			t.Errorf("unexpected request: %s", req.Query)
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, pages[len(reqs)-1])
+	}))
+	t.Cleanup(srv.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	return &Client{
+		client: client,
+		owner:  "owner",
+		repo:   "repo",
+	}, &reqs
+}
@@ -0,0 +1,18 @@
+package github
+
+import (
+	"context"
+
+	"github.com/gobwas/rw/interdiff"
+	"github.com/gobwas/rw/vcs"
+)
+
+// Interdiff implements vcs.Interdiffer. Previous head revision is fetched if
+// the pull request was force-pushed since then.
+func (p *pullRequest) Interdiff(ctx context.Context, rev string) (vcs.Interdiff, error) {
+	rel := relocator{p: p}
+	if err := rel.ensureCommit(ctx, rev); err != nil {
+		return nil, err
+	}
+	return interdiff.New(ctx, p.c.git, p.base(), rev, *p.pr.Head.SHA)
+}
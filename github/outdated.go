@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"log"
+
+	"github.com/gobwas/rw/diffutil"
+	"github.com/gobwas/rw/vcs"
+)
+
+// relocator maps lines of comments made on previous versions of the pull
+// request onto its current version.
+type relocator struct {
+	p *pullRequest
+
+	commits map[string]error
+	bases   map[string]string
+	matches map[[3]string][]diffutil.Match
+}
+
+// relocate sets lines of outdated comment c to the lines of the current
+// version of the file. Comment lines are reset to zero if they can't be
+// mapped, that is, the comment is orphaned.
+func (r *relocator) relocate(ctx context.Context, c *comment) {
+	c.outdated = true
+	c.startLine = 0
+	c.line = 0
+
+	ms, err := r.fileMatches(ctx, c)
+	if err != nil {
+		log.Printf(
+			"can't relocate outdated comment %d on %s at %s: %v",
+			c.id, c.path, c.originalCommitID, err,
+		)
+		return
+	}
+	line, ok := diffutil.MapLine(ms, c.originalLine)
+	if !ok {
+		return
+	}
+	c.line = line
+	if c.originalStartLine != 0 {
+		start, ok := diffutil.MapLine(ms, c.originalStartLine)
+		if !ok {
+			start = line
+		}
+		if start != line {
+			c.startLine = start
+		}
+	}
+}
+
+// fileMatches returns blocks of equal lines of the comment's file version it
+// was made on and the current version of the file.
+func (r *relocator) fileMatches(ctx context.Context, c *comment) ([]diffutil.Match, error) {
+	from, to, err := r.revisions(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return r.matchRevisions(ctx, from, to, c.path)
+}
+
+// matchRevisions returns blocks of equal lines of the file versions at from
+// and to revisions.
+func (r *relocator) matchRevisions(ctx context.Context, from, to, path string) ([]diffutil.Match, error) {
+	key := [3]string{from, to, path}
+	if ms, has := r.matches[key]; has {
+		return ms, nil
+	}
+	a, err := showFile(ctx, r.p.c.git, from, path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := showFile(ctx, r.p.c.git, to, path)
+	if err != nil {
+		return nil, err
+	}
+	ms := diffutil.Matches(diffutil.Lines(a), diffutil.Lines(b), diffutil.Myers)
+	if r.matches == nil {
+		r.matches = make(map[[3]string][]diffutil.Match)
+	}
+	r.matches[key] = ms
+	return ms, nil
+}
+
+// revisions returns revision comment was made on and the current revision of
+// the same side of the pull request.
+func (r *relocator) revisions(ctx context.Context, c *comment) (from, to string, err error) {
+	if err := r.ensureCommit(ctx, c.originalCommitID); err != nil {
+		return "", "", err
+	}
+	if c.side == vcs.SideHead {
+		return c.originalCommitID, r.p.head(), nil
+	}
+	// Base side comments were made on the base of the pull request as it
+	// was for the original commit.
+	base, has := r.bases[c.originalCommitID]
+	if !has {
+		base, err = r.p.c.git.MergeBase(ctx, c.originalCommitID, r.p.base())
+		if err != nil {
+			return "", "", err
+		}
+		if r.bases == nil {
+			r.bases = make(map[string]string)
+		}
+		r.bases[c.originalCommitID] = base
+	}
+	return base, r.p.base(), nil
+}
+
+// ensureCommit fetches commit with given hash if it's not present locally.
+// That is the case when the pull request was force-pushed.
+func (r *relocator) ensureCommit(ctx context.Context, hash string) (err error) {
+	if err, has := r.commits[hash]; has {
+		return err
+	}
+	if _, err = r.p.c.git.RevParse(ctx, hash); err != nil {
+		err = r.p.c.git.FetchRefspec(ctx, r.p.headRemote, hash)
+	}
+	if r.commits == nil {
+		r.commits = make(map[string]error)
+	}
+	r.commits[hash] = err
+	return err
+}
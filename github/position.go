@@ -0,0 +1,98 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+// positions maps file lines to the positions within the file's unified diff.
+// Position is the number of lines down from the first hunk header of the file
+// as GitHub commit comments API expects.
+type positions []position
+
+type position struct {
+	pos int
+	old int // Zero if line was added.
+	new int // Zero if line was deleted.
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+func parsePositions(r io.Reader) (ps positions, err error) {
+	var (
+		s = bufio.NewScanner(r)
+
+		pos      = -1
+		old, new int
+	)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := s.Bytes()
+		if pos == -1 && !bytes.HasPrefix(line, []byte("@@")) {
+			// Skip diff header.
+			continue
+		}
+		pos++
+		if len(line) == 0 {
+			// Some tools strip trailing whitespace of the context lines.
+			line = []byte{' '}
+		}
+		switch line[0] {
+		case '@':
+			m := hunkHeader.FindSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("malformed hunk header: %q", line)
+			}
+			old, _ = strconv.Atoi(string(m[1]))
+			new, _ = strconv.Atoi(string(m[2]))
+		case '-':
+			ps = append(ps, position{pos: pos, old: old})
+			old++
+		case '+':
+			ps = append(ps, position{pos: pos, new: new})
+			new++
+		case ' ':
+			ps = append(ps, position{pos: pos, old: old, new: new})
+			old++
+			new++
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// position returns diff position of the line at given side.
+func (ps positions) position(side vcs.Side, line int) (int, bool) {
+	for _, p := range ps {
+		if side == vcs.SideBase && p.old == line {
+			return p.pos, true
+		}
+		if side == vcs.SideHead && p.new == line {
+			return p.pos, true
+		}
+	}
+	return 0, false
+}
+
+// line returns file line and its side for the given diff position. Context
+// lines are reported as head lines.
+func (ps positions) line(pos int) (vcs.Side, int, bool) {
+	for _, p := range ps {
+		if p.pos != pos {
+			continue
+		}
+		if p.new != 0 {
+			return vcs.SideHead, p.new, true
+		}
+		return vcs.SideBase, p.old, true
+	}
+	return vcs.SideUnknown, 0, false
+}
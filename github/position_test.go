@@ -0,0 +1,88 @@
+package github
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+const testDiff = `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+@@ -10,2 +10,3 @@ ten
+ ten
++eleven
+ twelve
+`
+
+func TestPositions(t *testing.T) {
+	ps, err := parsePositions(strings.NewReader(testDiff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		side vcs.Side
+		line int
+		pos  int
+		miss bool
+	}{
+		{
+			name: "context",
+			side: vcs.SideHead,
+			line: 1,
+			pos:  1,
+		},
+		{
+			name: "deleted",
+			side: vcs.SideBase,
+			line: 2,
+			pos:  2,
+		},
+		{
+			name: "added",
+			side: vcs.SideHead,
+			line: 2,
+			pos:  3,
+		},
+		{
+			name: "second hunk",
+			side: vcs.SideHead,
+			line: 11,
+			pos:  7,
+		},
+		{
+			name: "outside",
+			side: vcs.SideHead,
+			line: 5,
+			miss: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pos, ok := ps.position(test.side, test.line)
+			if ok == test.miss {
+				t.Fatalf("unexpected position presence: %t", ok)
+			}
+			if test.miss {
+				return
+			}
+			if pos != test.pos {
+				t.Fatalf("unexpected position: %d; want %d", pos, test.pos)
+			}
+			side, line, ok := ps.line(pos)
+			if !ok || side != test.side || line != test.line {
+				t.Fatalf(
+					"unexpected line: %s %d; want %s %d",
+					side, line, test.side, test.line,
+				)
+			}
+		})
+	}
+}
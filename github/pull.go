@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/gobwas/rw/ed"
+	"github.com/gobwas/rw/internal/gitvcs"
 	"github.com/gobwas/rw/vcs"
 	"github.com/google/go-github/v39/github"
 )
@@ -23,11 +24,35 @@ type pullRequest struct {
 	baseRemote string
 	headRemote string
 
-	comments comments
+	comments vcs.CommentCache
+	pending  *pendingReview
+
+	// suggested is the last commit made by ApplySuggestion().
+	suggested string
 }
 
+// Close leaves comments which were not submitted in the pending review on
+// GitHub. The review can be submitted later from the browser. It also tells
+// how to get suggestions applied during the review, if any.
 func (p *pullRequest) Close() error {
-	return p.comments.Close()
+	if err := p.comments.Close(); err != nil {
+		return err
+	}
+	if p.suggested != "" {
+		fmt.Printf(
+			"Applied suggestions are committed to %s. To get them run:\n"+
+				"\tgit fetch %s %s && git merge FETCH_HEAD\n",
+			p.suggestionsBranch(), p.c.git.Dir, p.suggestionsBranch(),
+		)
+	}
+	if p.pending == nil || p.pending.empty() {
+		return nil
+	}
+	if err := p.sendPending(context.Background()); err != nil {
+		return err
+	}
+	fmt.Printf("Comments are left in a pending review of %s\n", p.pr.GetHTMLURL())
+	return nil
 }
 
 func (p *pullRequest) String() string {
@@ -39,39 +64,61 @@ func (p *pullRequest) String() string {
 	}, "-")
 }
 
-func (p *pullRequest) ChangedFiles(ctx context.Context) ([]string, error) {
-	return p.c.git.ChangedFiles(ctx, p.base(), p.head())
+func (p *pullRequest) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return gitvcs.ChangedFiles(p.c.git.ChangedFiles(ctx, p.base(), p.head()))
 }
 
 func (p *pullRequest) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
-	p.comments.Fetch(func(ctx context.Context) ([]*comment, error) {
-		cs, _, err := p.c.client.PullRequests.ListComments(
-			ctx, p.c.owner, p.c.repo, *p.pr.Number,
-			&github.PullRequestListCommentsOptions{
-				Sort:      "created",
-				Direction: "asc",
-			},
-		)
+	p.comments.Fetch(func(ctx context.Context) (map[string][]vcs.Comment, error) {
+		cs, err := p.fetchComments(ctx)
 		if err != nil {
 			return nil, err
 		}
-		ret := make([]*comment, 0, len(cs))
-		for _, c := range cs {
-			if *c.CommitID != *p.pr.Head.SHA {
-				// Outdated.
-				continue
-			}
-			if c.Line == nil {
-				// Comment not for the file.
-				continue
-			}
-			ret = append(ret, prComment(c))
-		}
-		return ret, nil
+		return fileComments(cs), nil
 	})
 	return p.comments.File(ctx, file)
 }
 
+func (p *pullRequest) fetchComments(ctx context.Context) ([]*comment, error) {
+	cs, _, err := p.c.client.PullRequests.ListComments(
+		ctx, p.c.owner, p.c.repo, *p.pr.Number,
+		&github.PullRequestListCommentsOptions{
+			Sort:      "created",
+			Direction: "asc",
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		ret = make([]*comment, 0, len(cs))
+		rel = relocator{p: p}
+	)
+	for _, c := range cs {
+		if c.Line == nil && c.OriginalLine == nil {
+			// Comment not for the file.
+			continue
+		}
+		x := prComment(c)
+		if c.Line == nil || *c.CommitID != *p.pr.Head.SHA {
+			rel.relocate(ctx, x)
+		}
+		ret = append(ret, x)
+	}
+	ts, err := p.reviewThreads(ctx)
+	if err != nil {
+		log.Printf("can't fetch review threads: %v", err)
+		return ret, nil
+	}
+	for _, x := range ret {
+		if t, has := ts[x.id]; has {
+			x.threadID = t.id
+			x.resolved = t.resolved
+		}
+	}
+	return ret, nil
+}
+
 func (p *pullRequest) BaseName() string {
 	return p.base()
 }
@@ -87,7 +134,7 @@ func (p *pullRequest) HeadFile(ctx context.Context, file string) (io.ReadCloser,
 }
 
 func (p *pullRequest) Checkout(ctx context.Context) (dir string, cleanup func() error, err error) {
-	return checkout(ctx, p.c.git, *p.pr.Head.SHA)
+	return p.c.git.Checkout(ctx, *p.pr.Head.SHA)
 }
 
 func (p *pullRequest) Edit(ctx context.Context, file string, c ed.Command) (err error) {
@@ -104,27 +151,23 @@ func (p *pullRequest) Edit(ctx context.Context, file string, c ed.Command) (err
 }
 
 func (p *pullRequest) delete(ctx context.Context, file string, c ed.Command) error {
-	for line := c.Start; line <= c.End; line++ {
-		fmt.Println("SUGGEST DELETION AT", line)
-		fmt.Println("----")
-	}
-	return nil
+	// Deletion is an empty suggestion.
+	return p.suggest(ctx, file, c.Start, c.End, nil)
 }
+
 func (p *pullRequest) change(ctx context.Context, file string, c ed.Command) error {
-	if c.Start != c.End {
-		return fmt.Errorf("WARNING: multiline suggestions are not supported yet")
-	}
+	return p.suggest(ctx, file, c.Start, c.End, c.Text)
+}
+
+// suggest creates a comment with suggestion to replace lines lo..hi of the
+// head file with the given text.
+func (p *pullRequest) suggest(ctx context.Context, file string, lo, hi int, text []byte) error {
+	// NOTE: text is either empty or ends with a newline.
 	body := fmt.Sprintf(
-		"```suggestion\n%s\n```",
-		c.Text,
+		"```suggestion\n%s```",
+		text,
 	)
-	_, _, err := p.c.client.PullRequests.CreateComment(ctx, p.c.owner, p.c.repo, *p.pr.Number, &github.PullRequestComment{
-		CommitID: p.pr.Head.SHA,
-		Body:     &body,
-		Path:     &file,
-		Line:     &c.Start,
-		Side:     &right,
-	})
+	_, err := p.Comment(ctx, file, vcs.SideHead, lo, hi, body)
 	return err
 }
 
@@ -135,6 +178,12 @@ var (
 
 func (p *pullRequest) ReplyTo(ctx context.Context, parent vcs.Comment, body string) (vcs.Comment, error) {
 	c := parent.(*comment)
+	if p.pending != nil {
+		if c.parent != nil {
+			c = c.parent
+		}
+		return p.pending.reply(c, body), nil
+	}
 	r, _, err := p.c.client.PullRequests.CreateCommentInReplyTo(
 		ctx, p.c.owner, p.c.repo, *p.pr.Number,
 		body, c.id,
@@ -142,7 +191,14 @@ func (p *pullRequest) ReplyTo(ctx context.Context, parent vcs.Comment, body stri
 	if err != nil {
 		return nil, err
 	}
-	return prComment(r), nil
+	x := prComment(r)
+	x.parent = c
+	// Outdated comment replies are outdated as well. Make them follow the
+	// relocated parent.
+	x.startLine = c.startLine
+	x.line = c.line
+	x.outdated = c.outdated
+	return x, nil
 }
 
 func sideOf(side vcs.Side) *string {
@@ -182,6 +238,15 @@ func (p *pullRequest) Comment(
 			file, lo, hi, err, buf.String(),
 		)
 	}()
+	return p.createComment(ctx, req)
+}
+
+// createComment creates a review comment or adds it to the pending review if
+// it was started.
+func (p *pullRequest) createComment(ctx context.Context, req *github.PullRequestComment) (*comment, error) {
+	if p.pending != nil {
+		return p.pending.add(req), nil
+	}
 	x, _, err := p.c.client.PullRequests.CreateComment(ctx, p.c.owner, p.c.repo, *p.pr.Number, req)
 	if err != nil {
 		return nil, err
@@ -215,10 +280,7 @@ func (p *pullRequest) comment(ctx context.Context, file string, c ed.Command) (e
 		id, err := strconv.ParseInt(num, 10, 64)
 		if err == nil {
 			body = strings.TrimSpace(rest)
-			_, _, err := p.c.client.PullRequests.CreateCommentInReplyTo(
-				ctx, p.c.owner, p.c.repo, *p.pr.Number,
-				body, id,
-			)
+			_, err := p.ReplyTo(ctx, &comment{id: id}, body)
 			return err
 		}
 		log.Printf("warning: incorrect parent comment id: %q: %v", num, err)
@@ -226,7 +288,7 @@ func (p *pullRequest) comment(ctx context.Context, file string, c ed.Command) (e
 
 	start := c.Start
 
-	_, _, err = p.c.client.PullRequests.CreateComment(ctx, p.c.owner, p.c.repo, *p.pr.Number, &github.PullRequestComment{
+	_, err = p.createComment(ctx, &github.PullRequestComment{
 		CommitID: p.pr.Head.SHA,
 		Body:     &body,
 		Path:     &file,
@@ -256,5 +318,9 @@ func prComment(c *github.PullRequestComment) *comment {
 		side:      parseSide(c.Side),
 		parentID:  parseInt64(c.InReplyTo),
 		path:      *c.Path,
+
+		originalCommitID:  parseString(c.OriginalCommitID),
+		originalStartLine: parseInt(c.OriginalStartLine),
+		originalLine:      parseInt(c.OriginalLine),
 	}
 }
@@ -0,0 +1,363 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-github/v39/github"
+)
+
+const addThreadMutation = `
+mutation($review: ID!, $path: String!, $body: String!, $line: Int!, $side: DiffSide!, $startLine: Int, $startSide: DiffSide) {
+  addPullRequestReviewThread(input: {
+    pullRequestReviewId: $review,
+    path: $path,
+    body: $body,
+    line: $line,
+    side: $side,
+    startLine: $startLine,
+    startSide: $startSide
+  }) {
+    thread {
+      id
+      comments(first: 1) {
+        nodes {
+          databaseId
+        }
+      }
+    }
+  }
+}`
+
+const addReplyMutation = `
+mutation($review: ID!, $thread: ID!, $body: String!) {
+  addPullRequestReviewThreadReply(input: {
+    pullRequestReviewId: $review,
+    pullRequestReviewThreadId: $thread,
+    body: $body
+  }) {
+    comment {
+      databaseId
+    }
+  }
+}`
+
+// pendingReview holds comments created during the review session. They are
+// added to a pending pull request review when the review is submitted or
+// closed.
+type pendingReview struct {
+	mu   sync.Mutex
+	self string
+	seq  int64
+
+	// comments holds draft comments in order of creation. Replies have
+	// non-nil parent and always follow it if the parent is a draft too.
+	comments []*comment
+
+	// review is a pending review created on GitHub, if any.
+	review *github.PullRequestReview
+}
+
+func (r *pendingReview) nextID() int64 {
+	// NOTE: drafts have negative ids to not intersect with published ones.
+	r.seq--
+	return r.seq
+}
+
+func (r *pendingReview) add(req *github.PullRequestComment) *comment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := &comment{
+		id:        r.nextID(),
+		body:      *req.Body,
+		startLine: parseInt(req.StartLine),
+		line:      parseInt(req.Line),
+		createdAt: time.Now(),
+		updatedAt: time.Now(),
+		userLogin: r.self,
+		side:      parseSide(req.Side),
+		path:      *req.Path,
+	}
+	r.comments = append(r.comments, c)
+	return c
+}
+
+func (r *pendingReview) reply(parent *comment, body string) *comment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := &comment{
+		parent:    parent,
+		id:        r.nextID(),
+		body:      body,
+		startLine: parent.startLine,
+		line:      parent.line,
+		createdAt: time.Now(),
+		updatedAt: time.Now(),
+		userLogin: r.self,
+		side:      parent.side,
+		parentID:  parent.id,
+		path:      parent.path,
+		outdated:  parent.outdated,
+	}
+	r.comments = append(r.comments, c)
+	return c
+}
+
+// update changes body of the draft comment c. It returns false if c is not a
+// draft of the review.
+func (r *pendingReview) update(c *comment, body string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.has(c) {
+		return false
+	}
+	c.body = body
+	c.updatedAt = time.Now()
+	return true
+}
+
+// remove deletes the draft comment c along with draft replies to it. It
+// returns false if c is not a draft of the review.
+func (r *pendingReview) remove(c *comment) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.has(c) {
+		return false
+	}
+	removed := map[*comment]bool{
+		c: true,
+	}
+	ret := r.comments[:0]
+	for _, x := range r.comments {
+		// Replies always follow their parents.
+		if removed[x] || removed[x.parent] {
+			removed[x] = true
+			continue
+		}
+		ret = append(ret, x)
+	}
+	r.comments = ret
+	return true
+}
+
+func (r *pendingReview) has(c *comment) bool {
+	for _, x := range r.comments {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+// empty reports whether there are no draft comments to be sent.
+func (r *pendingReview) empty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.comments) == 0
+}
+
+// next returns the earliest draft comment not sent yet.
+func (r *pendingReview) next() *comment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.comments) == 0 {
+		return nil
+	}
+	return r.comments[0]
+}
+
+// sent removes the draft comment c after it was added to the pending review.
+func (r *pendingReview) sent(c *comment) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.comments) > 0 && r.comments[0] == c {
+		r.comments = r.comments[1:]
+	}
+}
+
+// StartReview implements vcs.PendingReview. GitHub allows only one pending
+// review per user, so the one left after previous session is reused.
+func (p *pullRequest) StartReview(ctx context.Context) error {
+	login, err := p.c.login(ctx)
+	if err != nil {
+		return err
+	}
+	review, err := p.findPending(ctx, login)
+	if err != nil {
+		return err
+	}
+	p.pending = &pendingReview{
+		self:   login,
+		review: review,
+	}
+	return nil
+}
+
+// findPending returns pending review of the user with given login. It returns
+// nil if there is no such review.
+func (p *pullRequest) findPending(ctx context.Context, login string) (*github.PullRequestReview, error) {
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+	for {
+		rs, resp, err := p.c.client.PullRequests.ListReviews(
+			ctx, p.c.owner, p.c.repo, *p.pr.Number, opts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rs {
+			if r.GetState() == "PENDING" && strings.EqualFold(r.GetUser().GetLogin(), login) {
+				log.Printf("reusing pending review %d", r.GetID())
+				return r, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// SubmitReview implements vcs.PendingReview.
+func (p *pullRequest) SubmitReview(ctx context.Context, v vcs.Verdict, body string) error {
+	if p.pending == nil {
+		return fmt.Errorf("github: review is not started")
+	}
+	if p.pending.review == nil && p.pending.empty() && v == vcs.VerdictComment && body == "" {
+		return nil
+	}
+	if err := p.sendPending(ctx); err != nil {
+		return err
+	}
+	event := reviewEvent(v)
+	req := &github.PullRequestReviewRequest{
+		Event: &event,
+	}
+	if body != "" {
+		req.Body = &body
+	}
+	log.Printf("submitting review %s", event)
+	_, _, err := p.c.client.PullRequests.SubmitReview(
+		ctx, p.c.owner, p.c.repo, *p.pr.Number, p.pending.review.GetID(), req,
+	)
+	if err != nil {
+		return err
+	}
+	p.pending.review = nil
+	return nil
+}
+
+// sendPending adds draft comments to the pending review on GitHub. The
+// review is created if it doesn't exist yet. Comments which are successfully
+// sent are removed from the drafts, so sending can be retried after error.
+func (p *pullRequest) sendPending(ctx context.Context) error {
+	r := p.pending
+	if r.review == nil {
+		// Review without event stays pending until it's submitted.
+		review, _, err := p.c.client.PullRequests.CreateReview(
+			ctx, p.c.owner, p.c.repo, *p.pr.Number,
+			&github.PullRequestReviewRequest{
+				CommitID: p.pr.Head.SHA,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		r.review = review
+	}
+	for c := r.next(); c != nil; c = r.next() {
+		var err error
+		if c.parent == nil {
+			err = p.addThread(ctx, c)
+		} else {
+			err = p.addReply(ctx, c)
+		}
+		if err != nil {
+			return err
+		}
+		r.sent(c)
+	}
+	return nil
+}
+
+// addThread adds draft comment c to the pending review as a new thread.
+func (p *pullRequest) addThread(ctx context.Context, c *comment) error {
+	vars := map[string]interface{}{
+		"review": p.pending.review.GetNodeID(),
+		"path":   c.path,
+		"body":   c.body,
+		"line":   c.line,
+		"side":   *sideOf(c.side),
+	}
+	if c.startLine != 0 {
+		vars["startLine"] = c.startLine
+		vars["startSide"] = *sideOf(c.side)
+	}
+	var resp struct {
+		AddPullRequestReviewThread struct {
+			Thread struct {
+				ID       string
+				Comments struct {
+					Nodes []struct {
+						DatabaseID int64
+					}
+				}
+			}
+		}
+	}
+	if err := p.c.graphql(ctx, addThreadMutation, vars, &resp); err != nil {
+		return fmt.Errorf("github: can't add comment on %s:%d: %v", c.path, c.line, err)
+	}
+	t := resp.AddPullRequestReviewThread.Thread
+	if len(t.Comments.Nodes) == 0 {
+		return fmt.Errorf("github: created thread on %s:%d has no comments", c.path, c.line)
+	}
+	// Make replies to the draft refer the created thread.
+	c.id = t.Comments.Nodes[0].DatabaseID
+	c.threadID = t.ID
+	return nil
+}
+
+// addReply adds draft reply c to the thread of its parent within the pending
+// review.
+func (p *pullRequest) addReply(ctx context.Context, c *comment) error {
+	thread, err := p.threadID(ctx, c.parent.root())
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		AddPullRequestReviewThreadReply struct {
+			Comment struct {
+				DatabaseID int64
+			}
+		}
+	}
+	err = p.c.graphql(ctx, addReplyMutation, map[string]interface{}{
+		"review": p.pending.review.GetNodeID(),
+		"thread": thread,
+		"body":   c.body,
+	}, &resp)
+	if err != nil {
+		return fmt.Errorf("github: can't add reply on %s:%d: %v", c.path, c.line, err)
+	}
+	c.id = resp.AddPullRequestReviewThreadReply.Comment.DatabaseID
+	c.parentID = c.parent.id
+	return nil
+}
+
+func reviewEvent(v vcs.Verdict) string {
+	switch v {
+	case vcs.VerdictApprove:
+		return "APPROVE"
+	case vcs.VerdictRequestChanges:
+		return "REQUEST_CHANGES"
+	default:
+		return "COMMENT"
+	}
+}
@@ -0,0 +1,203 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v39/github"
+)
+
+func TestPendingReview(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		submit  bool
+		reviews string
+		exp     []string
+	}{
+		{
+			name:   "submit",
+			submit: true,
+			exp: []string{
+				"create review at head",
+				"thread R1 a.go:2-3 RIGHT: comment",
+				"reply R1 T1: draft reply",
+				"reply R1 T100: published reply",
+				"submit review 1: APPROVE lgtm",
+			},
+		},
+		{
+			name:   "submit existing pending review",
+			submit: true,
+			reviews: `[
+				{"id": 3, "node_id": "R3", "state": "APPROVED", "user": {"login": "alice"}},
+				{"id": 4, "node_id": "R4", "state": "PENDING", "user": {"login": "bob"}},
+				{"id": 5, "node_id": "R5", "state": "PENDING", "user": {"login": "Alice"}}
+			]`,
+			exp: []string{
+				"thread R5 a.go:2-3 RIGHT: comment",
+				"reply R5 T1: draft reply",
+				"reply R5 T100: published reply",
+				"submit review 5: APPROVE lgtm",
+			},
+		},
+		{
+			name: "close",
+			exp: []string{
+				"create review at head",
+				"thread R1 a.go:2-3 RIGHT: comment",
+				"reply R1 T1: draft reply",
+				"reply R1 T100: published reply",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var act []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/repos/owner/repo/pulls/42/reviews" && r.Method == http.MethodGet:
+					if test.reviews == "" {
+						fmt.Fprint(w, `[]`)
+					} else {
+						fmt.Fprint(w, test.reviews)
+					}
+
+				case r.URL.Path == "/repos/owner/repo/pulls/42/reviews":
+					var req github.PullRequestReviewRequest
+					json.NewDecoder(r.Body).Decode(&req)
+					act = append(act, "create review at "+req.GetCommitID())
+					fmt.Fprint(w, `{"id": 1, "node_id": "R1"}`)
+
+				case strings.HasSuffix(r.URL.Path, "/events"):
+					var req github.PullRequestReviewRequest
+					json.NewDecoder(r.Body).Decode(&req)
+					id := strings.Split(r.URL.Path, "/")[7]
+					act = append(act, fmt.Sprintf(
+						"submit review %s: %s %s", id, req.GetEvent(), req.GetBody(),
+					))
+					fmt.Fprintf(w, `{"id": %s}`, id)
+
+				case r.URL.Path == "/graphql":
+					var req graphqlRequest
+					json.NewDecoder(r.Body).Decode(&req)
+					v := req.Variables
+					switch {
+					case strings.Contains(req.Query, "addPullRequestReviewThreadReply"):
+						act = append(act, fmt.Sprintf(
+							"reply %s %s: %s", v["review"], v["thread"], v["body"],
+						))
+						fmt.Fprint(w, `{"data": {"addPullRequestReviewThreadReply": {
+							"comment": {"databaseId": 20}
+						}}}`)
+					case strings.Contains(req.Query, "addPullRequestReviewThread"):
+						act = append(act, fmt.Sprintf(
+							"thread %s %s:%v-%v %s: %s",
+							v["review"], v["path"], v["startLine"], v["line"], v["side"], v["body"],
+						))
+						fmt.Fprint(w, `{"data": {"addPullRequestReviewThread": {
+							"thread": {"id": "T1", "comments": {"nodes": [{"databaseId": 10}]}}
+						}}}`)
+					default:
+						t.Errorf("unexpected query: %s", req.Query)
+					}
+
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+			p := &pullRequest{
+				c: &Client{
+					client: client,
+					owner:  "owner",
+					repo:   "repo",
+					self:   "alice",
+				},
+				pr: &github.PullRequest{
+					Number: github.Int(42),
+					Head: &github.PullRequestBranch{
+						SHA: github.String("head"),
+					},
+				},
+			}
+			ctx := context.Background()
+			if err := p.StartReview(ctx); err != nil {
+				t.Fatal(err)
+			}
+			root, err := p.Comment(ctx, "a.go", vcs.SideHead, 2, 3, "comment")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := p.ReplyTo(ctx, root, "draft reply"); err != nil {
+				t.Fatal(err)
+			}
+			published := &comment{
+				id:       100,
+				path:     "b.go",
+				line:     1,
+				threadID: "T100",
+			}
+			if _, err := p.ReplyTo(ctx, published, "published reply"); err != nil {
+				t.Fatal(err)
+			}
+			if test.submit {
+				err = p.SubmitReview(ctx, vcs.VerdictApprove, "lgtm")
+			} else {
+				err = p.Close()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(act, test.exp) {
+				t.Errorf("unexpected requests:\n%s", cmp.Diff(test.exp, act))
+			}
+			if id := root.ID(); id != "10" {
+				t.Errorf("unexpected id of sent draft: %s", id)
+			}
+			if !p.pending.empty() {
+				t.Errorf("drafts are left after sending")
+			}
+		})
+	}
+}
+
+func TestPendingReviewRemove(t *testing.T) {
+	var (
+		r    = new(pendingReview)
+		line = 1
+		path = "a.go"
+	)
+	add := func(body string) *comment {
+		return r.add(&github.PullRequestComment{
+			Body: &body,
+			Path: &path,
+			Line: &line,
+		})
+	}
+	root := add("root")
+	reply := r.reply(root, "reply")
+	other := add("other")
+	r.reply(reply, "reply to reply")
+
+	if !r.remove(root) {
+		t.Fatalf("draft is not removed")
+	}
+	if r.remove(reply) {
+		t.Errorf("reply is removed twice")
+	}
+	if n := len(r.comments); n != 1 || r.comments[0] != other {
+		t.Errorf("unexpected drafts left: %d", n)
+	}
+}
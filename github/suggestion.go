@@ -0,0 +1,189 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gobwas/rw/diffutil"
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/vcs"
+)
+
+// suggestionContext is the number of context lines in the suggestion patch.
+const suggestionContext = 3
+
+// ApplySuggestion implements vcs.SuggestionApplier. Suggestions are committed
+// on top of each other to the suggestionsBranch() of the cache repository.
+func (p *pullRequest) ApplySuggestion(ctx context.Context, file string, x vcs.Comment, text []byte) (string, error) {
+	c := x.(*comment)
+	lo, hi := c.Lines()
+	if c.side != vcs.SideHead || lo == 0 {
+		return "", fmt.Errorf("github: suggestion is not bound to the head file lines")
+	}
+	base := p.suggested
+	if base == "" {
+		base = *p.pr.Head.SHA
+	}
+	src, err := showFile(ctx, p.c.git, base, file)
+	if err != nil {
+		return "", err
+	}
+	if base != *p.pr.Head.SHA {
+		// Previously applied suggestions may shift the commented lines.
+		head, err := showFile(ctx, p.c.git, p.head(), file)
+		if err != nil {
+			return "", err
+		}
+		if lo, hi, err = mapSuggestionLines(file, head, src, lo, hi); err != nil {
+			return "", err
+		}
+	}
+	patch, err := suggestionPatch(file, src, lo, hi, text)
+	if err != nil {
+		return "", err
+	}
+	author, err := p.author(ctx)
+	if err != nil {
+		return "", err
+	}
+	head, err := p.c.git.ApplyPatch(ctx, base, patch,
+		fmt.Sprintf(
+			"Apply suggestion from @%s\n\nSuggested in review comment %d.",
+			c.userLogin, c.id,
+		),
+		author,
+	)
+	if err != nil {
+		return "", err
+	}
+	if err := p.c.git.SetBranch(ctx, p.suggestionsBranch(), head); err != nil {
+		return "", err
+	}
+	p.suggested = head
+	return head, nil
+}
+
+// suggestionsBranch returns name of the branch holding applied suggestions.
+func (p *pullRequest) suggestionsBranch() string {
+	return fmt.Sprintf("rw/suggestions/%d", *p.pr.Number)
+}
+
+// mapSuggestionLines maps commented lines lo..hi of the head file onto the
+// file with previously applied suggestions. It returns error if any of the
+// lines was changed by those suggestions.
+func mapSuggestionLines(file string, head, src []byte, lo, hi int) (_, _ int, err error) {
+	ms := diffutil.Matches(diffutil.Lines(head), diffutil.Lines(src), diffutil.Myers)
+	for i := lo; i <= hi; i++ {
+		if _, ok := diffutil.MapLine(ms, i); !ok {
+			return 0, 0, fmt.Errorf(
+				"github: lines %d-%d of %s are changed by applied suggestions",
+				lo, hi, file,
+			)
+		}
+	}
+	a, _ := diffutil.MapLine(ms, lo)
+	b, _ := diffutil.MapLine(ms, hi)
+	return a, b, nil
+}
+
+// author returns signature for commits made on behalf of the authenticated
+// user. Git configuration has precedence over the GitHub user login.
+func (p *pullRequest) author(ctx context.Context) (git.Signature, error) {
+	name, _ := p.c.git.Config(ctx, "user.name")
+	email, _ := p.c.git.Config(ctx, "user.email")
+	if name != "" && email != "" {
+		return git.Signature{
+			Name:  name,
+			Email: email,
+		}, nil
+	}
+	login, err := p.c.login(ctx)
+	if err != nil {
+		return git.Signature{}, err
+	}
+	if name == "" {
+		name = login
+	}
+	if email == "" {
+		email = login + "@users.noreply.github.com"
+	}
+	return git.Signature{
+		Name:  name,
+		Email: email,
+	}, nil
+}
+
+// suggestionPatch returns unified diff of the file replacing its lines lo..hi
+// with the given text.
+func suggestionPatch(file string, src []byte, lo, hi int, text []byte) ([]byte, error) {
+	lines := diffutil.Lines(src)
+	if lo < 1 || hi < lo || hi > len(lines) {
+		return nil, fmt.Errorf(
+			"github: suggestion lines %d-%d are out of file %s range",
+			lo, hi, file,
+		)
+	}
+	var (
+		add   = diffutil.Lines(text)
+		start = lo - suggestionContext
+		end   = hi + suggestionContext
+	)
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	var (
+		oldLines = end - start + 1
+		newLines = oldLines - (hi - lo + 1) + len(add)
+		newStart = start
+	)
+	if newLines == 0 {
+		newStart--
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- a/%s\n+++ b/%s\n", file, file)
+	fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", start, oldLines, newStart, newLines)
+	write := func(mark byte, line []byte) {
+		buf.WriteByte(mark)
+		buf.Write(line)
+		if !bytes.HasSuffix(line, []byte{'\n'}) {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	for i := start; i < lo; i++ {
+		write(' ', lines[i-1])
+	}
+	for i := lo; i <= hi; i++ {
+		write('-', lines[i-1])
+	}
+	for _, line := range add {
+		write('+', line)
+	}
+	for i := hi + 1; i <= end; i++ {
+		write(' ', lines[i-1])
+	}
+	return buf.Bytes(), nil
+}
+
+// showFile returns contents of the file at given revision.
+func showFile(ctx context.Context, g *git.Repository, rev, file string) ([]byte, error) {
+	src, err := g.ShowFile(ctx, rev, file)
+	if err != nil {
+		return nil, err
+	}
+	bts, err := ioutil.ReadAll(src)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+	// Close() reports non-zero exit code, for example, if the file doesn't
+	// exist at given revision.
+	if err := src.Close(); err != nil {
+		return nil, err
+	}
+	return bts, nil
+}
@@ -0,0 +1,108 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestSuggestionPatch(t *testing.T) {
+	src := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+	for _, test := range []struct {
+		name   string
+		src    []byte
+		lo, hi int
+		text   string
+		exp    string
+		err    bool
+	}{
+		{
+			name: "change",
+			src:  src,
+			lo:   5,
+			hi:   5,
+			text: "five\n",
+			exp: "--- a/f.txt\n+++ b/f.txt\n" +
+				"@@ -2,7 +2,7 @@\n" +
+				" 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "delete at start",
+			src:  src,
+			lo:   1,
+			hi:   2,
+			exp: "--- a/f.txt\n+++ b/f.txt\n" +
+				"@@ -1,5 +1,3 @@\n" +
+				"-1\n-2\n 3\n 4\n 5\n",
+		},
+		{
+			name: "no newline at end",
+			src:  []byte("a\nb"),
+			lo:   1,
+			hi:   1,
+			text: "A\n",
+			exp: "--- a/f.txt\n+++ b/f.txt\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"-a\n+A\n b\n\\ No newline at end of file\n",
+		},
+		{
+			name: "out of range",
+			src:  src,
+			lo:   9,
+			hi:   10,
+			err:  true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			act, err := suggestionPatch("f.txt", test.src, test.lo, test.hi, []byte(test.text))
+			if test.err {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(act) != test.exp {
+				t.Errorf("unexpected patch:\n%s\nwant:\n%s", act, test.exp)
+			}
+		})
+	}
+}
+
+func TestMapSuggestionLines(t *testing.T) {
+	head := []byte("1\n2\n3\n4\n5\n6\n")
+	for _, test := range []struct {
+		name   string
+		src    string
+		lo, hi int
+		expLo  int
+		expHi  int
+		err    bool
+	}{
+		{
+			name:  "shifted",
+			src:   "0\n1\n2\n3\n4\n5\n6\n",
+			lo:    4,
+			hi:    5,
+			expLo: 5,
+			expHi: 6,
+		},
+		{
+			name: "changed",
+			src:  "1\n2\n3\nfour\n5\n6\n",
+			lo:   3,
+			hi:   5,
+			err:  true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			lo, hi, err := mapSuggestionLines("f.txt", head, []byte(test.src), test.lo, test.hi)
+			if test.err != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if lo != test.expLo || hi != test.expHi {
+				t.Errorf("unexpected lines: %d-%d; want %d-%d", lo, hi, test.expLo, test.expHi)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+const reviewThreadsQuery = `
+query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        pageInfo {
+          hasNextPage
+          endCursor
+        }
+        nodes {
+          id
+          isResolved
+          comments(first: 1) {
+            nodes {
+              databaseId
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+const resolveThreadMutation = `
+mutation($id: ID!) {
+  resolveReviewThread(input: {threadId: $id}) {
+    thread {
+      isResolved
+    }
+  }
+}`
+
+const unresolveThreadMutation = `
+mutation($id: ID!) {
+  unresolveReviewThread(input: {threadId: $id}) {
+    thread {
+      isResolved
+    }
+  }
+}`
+
+// reviewThread represents resolution state of a review thread.
+type reviewThread struct {
+	id       string
+	resolved bool
+}
+
+// reviewThreads returns review threads of the pull request indexed by the
+// database id of their first comment.
+func (p *pullRequest) reviewThreads(ctx context.Context) (map[int64]reviewThread, error) {
+	var (
+		ret    = make(map[int64]reviewThread)
+		cursor *string
+	)
+	for {
+		var resp struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						PageInfo struct {
+							HasNextPage bool
+							EndCursor   string
+						}
+						Nodes []struct {
+							ID         string
+							IsResolved bool
+							Comments   struct {
+								Nodes []struct {
+									DatabaseID int64
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		err := p.c.graphql(ctx, reviewThreadsQuery, map[string]interface{}{
+			"owner":  p.c.owner,
+			"repo":   p.c.repo,
+			"number": *p.pr.Number,
+			"cursor": cursor,
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		ts := resp.Repository.PullRequest.ReviewThreads
+		for _, t := range ts.Nodes {
+			if len(t.Comments.Nodes) == 0 {
+				continue
+			}
+			ret[t.Comments.Nodes[0].DatabaseID] = reviewThread{
+				id:       t.ID,
+				resolved: t.IsResolved,
+			}
+		}
+		if !ts.PageInfo.HasNextPage {
+			return ret, nil
+		}
+		cursor = &ts.PageInfo.EndCursor
+	}
+}
+
+// ResolveThread implements vcs.ThreadResolver.
+func (p *pullRequest) ResolveThread(ctx context.Context, c vcs.Comment) error {
+	return p.setResolved(ctx, c, resolveThreadMutation, true)
+}
+
+// UnresolveThread implements vcs.ThreadResolver.
+func (p *pullRequest) UnresolveThread(ctx context.Context, c vcs.Comment) error {
+	return p.setResolved(ctx, c, unresolveThreadMutation, false)
+}
+
+func (p *pullRequest) setResolved(ctx context.Context, c vcs.Comment, mutation string, resolved bool) error {
+	root := c.(*comment).root()
+	id, err := p.threadID(ctx, root)
+	if err != nil {
+		return err
+	}
+	var resp interface{}
+	err = p.c.graphql(ctx, mutation, map[string]interface{}{
+		"id": id,
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	root.resolved = resolved
+	return nil
+}
+
+// threadID returns node id of the review thread started by the root comment.
+func (p *pullRequest) threadID(ctx context.Context, root *comment) (string, error) {
+	if root.threadID != "" {
+		return root.threadID, nil
+	}
+	if root.id < 0 {
+		return "", fmt.Errorf("github: comment %q is not sent yet", root.body)
+	}
+	// Thread was created after the comments were fetched.
+	ts, err := p.reviewThreads(ctx)
+	if err != nil {
+		return "", err
+	}
+	t, has := ts[root.id]
+	if !has {
+		return "", fmt.Errorf("github: no review thread for comment %d", root.id)
+	}
+	root.threadID = t.id
+	return t.id, nil
+}
@@ -0,0 +1,54 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v39/github"
+)
+
+func TestReviewThreads(t *testing.T) {
+	pages := []string{
+		`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+			"nodes": [
+				{"id": "T1", "isResolved": true, "comments": {"nodes": [{"databaseId": 10}]}},
+				{"id": "T2", "isResolved": false, "comments": {"nodes": []}}
+			]
+		}}}}}`,
+		`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"pageInfo": {"hasNextPage": false, "endCursor": ""},
+			"nodes": [
+				{"id": "T3", "isResolved": false, "comments": {"nodes": [{"databaseId": 30}]}}
+			]
+		}}}}}`,
+	}
+	c, reqs := newGraphQLTestClient(t, pages...)
+
+	number := 42
+	p := &pullRequest{
+		c: c,
+		pr: &github.PullRequest{
+			Number: &number,
+		},
+	}
+	act, err := p.reviewThreads(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[int64]reviewThread{
+		10: {id: "T1", resolved: true},
+		30: {id: "T3", resolved: false},
+	}
+	if !cmp.Equal(act, exp, cmp.AllowUnexported(reviewThread{})) {
+		t.Errorf("unexpected threads:\n%s", cmp.Diff(exp, act, cmp.AllowUnexported(reviewThread{})))
+	}
+	var cursors []interface{}
+	for _, req := range *reqs {
+		cursors = append(cursors, req.Variables["cursor"])
+	}
+	if exp := []interface{}{nil, "c1"}; !cmp.Equal(cursors, exp) {
+		t.Errorf("unexpected cursors:\n%s", cmp.Diff(exp, cursors))
+	}
+}
@@ -0,0 +1,103 @@
+package github
+
+import (
+	"context"
+)
+
+const viewedFilesQuery = `
+query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      files(first: 100, after: $cursor) {
+        pageInfo {
+          hasNextPage
+          endCursor
+        }
+        nodes {
+          path
+          viewerViewedState
+        }
+      }
+    }
+  }
+}`
+
+const markViewedMutation = `
+mutation($id: ID!, $path: String!) {
+  markFileAsViewed(input: {pullRequestId: $id, path: $path}) {
+    clientMutationId
+  }
+}`
+
+const unmarkViewedMutation = `
+mutation($id: ID!, $path: String!) {
+  unmarkFileAsViewed(input: {pullRequestId: $id, path: $path}) {
+    clientMutationId
+  }
+}`
+
+// HeadRevision implements vcs.HeadRevision.
+func (p *pullRequest) HeadRevision(ctx context.Context) (string, error) {
+	return *p.pr.Head.SHA, nil
+}
+
+// ViewedFiles implements vcs.FileViewer. Files which were changed after
+// being marked as viewed are not reported.
+func (p *pullRequest) ViewedFiles(ctx context.Context) ([]string, error) {
+	var (
+		ret    []string
+		cursor *string
+	)
+	for {
+		var resp struct {
+			Repository struct {
+				PullRequest struct {
+					Files struct {
+						PageInfo struct {
+							HasNextPage bool
+							EndCursor   string
+						}
+						Nodes []struct {
+							Path              string
+							ViewerViewedState string
+						}
+					}
+				}
+			}
+		}
+		err := p.c.graphql(ctx, viewedFilesQuery, map[string]interface{}{
+			"owner":  p.c.owner,
+			"repo":   p.c.repo,
+			"number": *p.pr.Number,
+			"cursor": cursor,
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		fs := resp.Repository.PullRequest.Files
+		for _, f := range fs.Nodes {
+			// Other states are UNVIEWED and DISMISSED; the latter means the
+			// file was changed since it was viewed.
+			if f.ViewerViewedState == "VIEWED" {
+				ret = append(ret, f.Path)
+			}
+		}
+		if !fs.PageInfo.HasNextPage {
+			return ret, nil
+		}
+		cursor = &fs.PageInfo.EndCursor
+	}
+}
+
+// SetViewed implements vcs.FileViewer.
+func (p *pullRequest) SetViewed(ctx context.Context, file string, viewed bool) error {
+	mutation := unmarkViewedMutation
+	if viewed {
+		mutation = markViewedMutation
+	}
+	var resp interface{}
+	return p.c.graphql(ctx, mutation, map[string]interface{}{
+		"id":   p.pr.GetNodeID(),
+		"path": file,
+	}, &resp)
+}
@@ -0,0 +1,65 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v39/github"
+)
+
+func TestViewedFiles(t *testing.T) {
+	pages := []string{
+		`{"data": {"repository": {"pullRequest": {"files": {
+			"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+			"nodes": [
+				{"path": "a.go", "viewerViewedState": "VIEWED"},
+				{"path": "b.go", "viewerViewedState": "DISMISSED"}
+			]
+		}}}}}`,
+		`{"data": {"repository": {"pullRequest": {"files": {
+			"pageInfo": {"hasNextPage": false, "endCursor": ""},
+			"nodes": [
+				{"path": "c.go", "viewerViewedState": "UNVIEWED"},
+				{"path": "d.go", "viewerViewedState": "VIEWED"}
+			]
+		}}}}}`,
+		`{"data": {"markFileAsViewed": {"clientMutationId": null}}}`,
+	}
+	c, reqs := newGraphQLTestClient(t, pages...)
+
+	var (
+		number = 42
+		nodeID = "PR_1"
+	)
+	p := &pullRequest{
+		c: c,
+		pr: &github.PullRequest{
+			Number: &number,
+			NodeID: &nodeID,
+		},
+	}
+	ctx := context.Background()
+	act, err := p.ViewedFiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"a.go", "d.go"}; !cmp.Equal(act, exp) {
+		t.Errorf("unexpected viewed files:\n%s", cmp.Diff(exp, act))
+	}
+	if err := p.SetViewed(ctx, "c.go", true); err != nil {
+		t.Fatal(err)
+	}
+	req := (*reqs)[len(*reqs)-1]
+	if !strings.Contains(req.Query, "markFileAsViewed") {
+		t.Errorf("unexpected mutation: %s", req.Query)
+	}
+	exp := map[string]interface{}{
+		"id":   "PR_1",
+		"path": "c.go",
+	}
+	if !cmp.Equal(req.Variables, exp) {
+		t.Errorf("unexpected mutation variables:\n%s", cmp.Diff(exp, req.Variables))
+	}
+}
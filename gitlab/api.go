@@ -0,0 +1,201 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const apiPath = "/api/v4"
+
+type api struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+type apiError struct {
+	Method  string
+	Path    string
+	Status  string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf(
+		"gitlab: %s %s: %s: %s",
+		e.Method, e.Path, e.Status, e.Message,
+	)
+}
+
+// do sends request to the GitLab API and decodes response body into result.
+// It returns the next page number if response is paginated.
+func (a *api) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body, result interface{},
+) (next int, err error) {
+	var r io.Reader
+	if body != nil {
+		bts, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		r = bytes.NewReader(bts)
+	}
+	u := strings.TrimSuffix(a.baseURL, "/") + apiPath + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t := a.token; t != "" {
+		req.Header.Set("PRIVATE-TOKEN", t)
+	}
+	log.Printf("gitlab: %s %s", method, u)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		bts, _ := ioutil.ReadAll(resp.Body)
+		var msg struct {
+			Message interface{} `json:"message"`
+			Error   string      `json:"error"`
+		}
+		text := strings.TrimSpace(string(bts))
+		if json.Unmarshal(bts, &msg) == nil {
+			switch {
+			case msg.Message != nil:
+				text = fmt.Sprint(msg.Message)
+			case msg.Error != "":
+				text = msg.Error
+			}
+		}
+		return 0, &apiError{
+			Method:  method,
+			Path:    path,
+			Status:  resp.Status,
+			Message: text,
+		}
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return 0, err
+		}
+	}
+	if s := resp.Header.Get("X-Next-Page"); s != "" {
+		next, err = strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("gitlab: malformed next page header: %v", err)
+		}
+	}
+	return next, nil
+}
+
+func projectPath(project string) string {
+	return "/projects/" + url.PathEscape(project)
+}
+
+func mergeRequestPath(project string, iid int) string {
+	return projectPath(project) + "/merge_requests/" + strconv.Itoa(iid)
+}
+
+type apiUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type apiProject struct {
+	ID                int64  `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	SSHURLToRepo      string `json:"ssh_url_to_repo"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+}
+
+type apiDiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+type apiMergeRequest struct {
+	ID           int64        `json:"id"`
+	IID          int          `json:"iid"`
+	Title        string       `json:"title"`
+	Author       apiUser      `json:"author"`
+	SourceBranch string       `json:"source_branch"`
+	TargetBranch string       `json:"target_branch"`
+	SHA          string       `json:"sha"`
+	DiffRefs     *apiDiffRefs `json:"diff_refs"`
+	WebURL       string       `json:"web_url"`
+}
+
+type apiLine struct {
+	LineCode string  `json:"line_code"`
+	Type     *string `json:"type"`
+	OldLine  *int    `json:"old_line"`
+	NewLine  *int    `json:"new_line"`
+}
+
+type apiLineRange struct {
+	Start apiLine `json:"start"`
+	End   apiLine `json:"end"`
+}
+
+type apiPosition struct {
+	BaseSHA      string        `json:"base_sha"`
+	StartSHA     string        `json:"start_sha"`
+	HeadSHA      string        `json:"head_sha"`
+	PositionType string        `json:"position_type"`
+	OldPath      string        `json:"old_path,omitempty"`
+	NewPath      string        `json:"new_path,omitempty"`
+	OldLine      *int          `json:"old_line,omitempty"`
+	NewLine      *int          `json:"new_line,omitempty"`
+	LineRange    *apiLineRange `json:"line_range,omitempty"`
+}
+
+type apiNote struct {
+	ID        int64        `json:"id"`
+	Type      string       `json:"type"`
+	Body      string       `json:"body"`
+	Author    apiUser      `json:"author"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	System    bool         `json:"system"`
+	Position  *apiPosition `json:"position"`
+}
+
+type apiDiscussion struct {
+	ID             string     `json:"id"`
+	IndividualNote bool       `json:"individual_note"`
+	Notes          []*apiNote `json:"notes"`
+}
+
+type apiCreateDiscussion struct {
+	Body     string       `json:"body"`
+	Position *apiPosition `json:"position,omitempty"`
+}
+
+type apiCreateNote struct {
+	Body string `json:"body"`
+}
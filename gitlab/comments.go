@@ -0,0 +1,138 @@
+package gitlab
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+// byFile sorts comments by creation time and groups them by file.
+func byFile(cs []*comment) map[string][]vcs.Comment {
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].createdAt.Before(cs[j].createdAt)
+	})
+	m := make(map[string][]vcs.Comment)
+	for _, x := range cs {
+		m[x.path] = append(m[x.path], x)
+	}
+	return m
+}
+
+type comment struct {
+	parent *comment
+
+	id           int64
+	discussionID string
+	body         string
+	startLine    int
+	line         int
+	createdAt    time.Time
+	updatedAt    time.Time
+	userLogin    string
+	side         vcs.Side
+	path         string
+}
+
+func (c *comment) Lines() (lo, hi int) {
+	if c.startLine != 0 {
+		return c.startLine, c.line
+	}
+	return c.line, c.line
+}
+func (c *comment) Body() string {
+	return c.body
+}
+func (c *comment) CreatedAt() time.Time {
+	return c.createdAt
+}
+func (c *comment) UpdatedAt() time.Time {
+	return c.updatedAt
+}
+func (c *comment) UserLogin() string {
+	return c.userLogin
+}
+func (c *comment) Side() vcs.Side {
+	return c.side
+}
+func (c *comment) Parent() vcs.Comment {
+	if c.parent != nil {
+		return c.parent
+	}
+	return nil
+}
+func (c *comment) ID() string {
+	return strconv.FormatInt(c.id, 10)
+}
+
+// discussionComments converts notes of the discussion into comments. It
+// returns nil if discussion is not bound to a diff line.
+//
+// The first note of the discussion becomes a parent for the rest of notes.
+func discussionComments(d *apiDiscussion) []*comment {
+	if len(d.Notes) == 0 {
+		return nil
+	}
+	root := d.Notes[0]
+	if root.Position == nil || root.Position.PositionType != "text" {
+		return nil
+	}
+	side, path, lo, hi, ok := positionLines(root.Position)
+	if !ok {
+		return nil
+	}
+	var (
+		ret    = make([]*comment, 0, len(d.Notes))
+		parent *comment
+	)
+	for _, n := range d.Notes {
+		if n.System {
+			continue
+		}
+		c := &comment{
+			parent:       parent,
+			id:           n.ID,
+			discussionID: d.ID,
+			body:         n.Body,
+			line:         hi,
+			createdAt:    n.CreatedAt,
+			updatedAt:    n.UpdatedAt,
+			userLogin:    n.Author.Username,
+			side:         side,
+			path:         path,
+		}
+		if lo != hi {
+			c.startLine = lo
+		}
+		if parent == nil {
+			parent = c
+		}
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+// positionLines returns side, file path and line range which note position
+// points to. Lines not changed by merge request are bound to the head side.
+func positionLines(p *apiPosition) (side vcs.Side, path string, lo, hi int, ok bool) {
+	switch {
+	case p.NewLine != nil:
+		side, path, hi = vcs.SideHead, p.NewPath, *p.NewLine
+	case p.OldLine != nil:
+		side, path, hi = vcs.SideBase, p.OldPath, *p.OldLine
+	default:
+		return 0, "", 0, 0, false
+	}
+	lo = hi
+	if r := p.LineRange; r != nil {
+		start := r.Start.NewLine
+		if side == vcs.SideBase {
+			start = r.Start.OldLine
+		}
+		if start != nil && *start < hi {
+			lo = *start
+		}
+	}
+	return side, path, lo, hi, true
+}
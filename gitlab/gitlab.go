@@ -0,0 +1,236 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/vcs"
+)
+
+const DefaultURL = "https://gitlab.com"
+
+type Client struct {
+	Project    string
+	CacheDir   string
+	URL        string
+	Token      string
+	Origin     string
+	Branch     string
+	User       string
+	MRID       int
+	MRTemplate string
+
+	// HTTPClient is used to make GitLab API requests.
+	// If nil then http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	once       sync.Once
+	api        api
+	git        *git.Repository
+	user       apiUser
+	project    apiProject
+	err        error
+	mrTemplate *template.Template
+}
+
+const cacheOrigin = "rw-origin"
+
+func (c *Client) Init(ctx context.Context) error {
+	c.once.Do(func() {
+		c.api = api{
+			client:  c.HTTPClient,
+			baseURL: c.URL,
+			token:   c.Token,
+		}
+		if c.api.client == nil {
+			c.api.client = http.DefaultClient
+		}
+		if c.api.baseURL == "" {
+			c.api.baseURL = DefaultURL
+		}
+		if c.err = c.ping(ctx); c.err != nil {
+			return
+		}
+
+		c.mrTemplate, c.err = template.New("mr").Parse(c.MRTemplate)
+		if c.err != nil {
+			return
+		}
+
+		var (
+			branch  = c.Branch
+			project = c.Project
+		)
+		if project == "" {
+			// Try to work with repo in process's current directory.
+			var owner, repo string
+			owner, repo, c.err = git.ShowRemote(ctx, c.Origin)
+			if c.err == nil {
+				branch, c.err = git.CurrentBranch(ctx)
+			}
+			if c.err != nil {
+				return
+			}
+			project = path.Join(owner, repo)
+		}
+		project = strings.Trim(project, "/")
+		if !strings.Contains(project, "/") {
+			c.err = fmt.Errorf("malformed project name: %q", project)
+			return
+		}
+		_, c.err = c.api.do(ctx,
+			http.MethodGet, projectPath(project), nil,
+			nil, &c.project,
+		)
+		if c.err != nil {
+			return
+		}
+
+		var dir string
+		if cache := c.CacheDir; cache != "" {
+			var u *url.URL
+			u, c.err = url.Parse(c.api.baseURL)
+			if c.err != nil {
+				return
+			}
+			dir = filepath.Join(cache, u.Host, c.project.PathWithNamespace)
+		}
+		c.git, c.err = git.Cache(ctx, dir, cacheOrigin, c.project.SSHURLToRepo, branch)
+	})
+	return c.err
+}
+
+func (c *Client) ping(ctx context.Context) error {
+	_, err := c.api.do(ctx, http.MethodGet, "/user", nil, nil, &c.user)
+	return err
+}
+
+func (c *Client) projectID() string {
+	return strconv.FormatInt(c.project.ID, 10)
+}
+
+func (c *Client) List(ctx context.Context, fn func(vcs.ReviewItem)) error {
+	if id := c.MRID; id != 0 {
+		mr, err := c.mergeRequest(ctx, id)
+		if err != nil {
+			return err
+		}
+		fn(&item{
+			mr:       mr,
+			template: c.mrTemplate,
+		})
+		return nil
+	}
+	user := c.User
+	if user == "" {
+		user = c.user.Username
+	}
+	query := url.Values{
+		"state":             {"opened"},
+		"reviewer_username": {user},
+		"order_by":          {"created_at"},
+		"sort":              {"asc"},
+		"per_page":          {"100"},
+	}
+	for page := 1; page != 0; {
+		query.Set("page", strconv.Itoa(page))
+		var (
+			mrs []*apiMergeRequest
+			err error
+		)
+		page, err = c.api.do(ctx,
+			http.MethodGet, projectPath(c.projectID())+"/merge_requests", query,
+			nil, &mrs,
+		)
+		if err != nil {
+			return err
+		}
+		for _, mr := range mrs {
+			fn(&item{
+				mr:       mr,
+				template: c.mrTemplate,
+			})
+		}
+	}
+	return nil
+}
+
+func (c *Client) Select(ctx context.Context, x vcs.ReviewItem) (vcs.Review, error) {
+	switch v := x.(type) {
+	case *item:
+		iid := v.mr.IID
+		// GitLab exposes head of each merge request in the target project,
+		// so there is no need to add remotes for forks.
+		err := c.git.FetchRefspec(ctx, cacheOrigin, fmt.Sprintf(
+			"+refs/merge-requests/%d/head:refs/remotes/%s/merge-requests/%d",
+			iid, cacheOrigin, iid,
+		))
+		if err != nil {
+			return nil, err
+		}
+		// Listed merge requests have no diff refs, so get it again.
+		mr, err := c.mergeRequest(ctx, iid)
+		if err != nil {
+			return nil, err
+		}
+		if mr.DiffRefs == nil {
+			return nil, fmt.Errorf(
+				"gitlab: merge request !%d has no diff refs", iid,
+			)
+		}
+		return &mergeRequest{
+			c:  c,
+			mr: mr,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("gitlab: select: unsupported type: %T", v)
+	}
+}
+
+func (c *Client) mergeRequest(ctx context.Context, iid int) (*apiMergeRequest, error) {
+	mr := new(apiMergeRequest)
+	_, err := c.api.do(ctx,
+		http.MethodGet, mergeRequestPath(c.projectID(), iid), nil,
+		nil, mr,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+type item struct {
+	mr       *apiMergeRequest
+	template *template.Template
+}
+
+func (s *item) String() string {
+	var sb strings.Builder
+	err := s.template.Execute(&sb, s)
+	if err != nil {
+		panic(err)
+	}
+	return sb.String()
+}
+
+func (s *item) IID() int {
+	return s.mr.IID
+}
+
+func (s *item) UserLogin() string {
+	return s.mr.Author.Username
+}
+
+func (s *item) Title() string {
+	return s.mr.Title
+}
@@ -0,0 +1,384 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gobwas/rw/internal/gittest"
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMapLine(t *testing.T) {
+	hs := []hunk{
+		// Line 2 changed.
+		{oldStart: 2, oldLines: 1, newStart: 2, newLines: 1},
+		// Two lines added after line 4.
+		{oldStart: 4, oldLines: 0, newStart: 5, newLines: 2},
+		// Line 7 deleted.
+		{oldStart: 7, oldLines: 1, newStart: 8, newLines: 0},
+	}
+	for _, test := range []struct {
+		name string
+		side vcs.Side
+		line int
+		old  int
+		new  int
+	}{
+		{side: vcs.SideHead, line: 1, old: 1, new: 1},
+		{side: vcs.SideHead, line: 2, old: 0, new: 2},
+		{side: vcs.SideHead, line: 4, old: 4, new: 4},
+		{side: vcs.SideHead, line: 5, old: 0, new: 5},
+		{side: vcs.SideHead, line: 6, old: 0, new: 6},
+		{side: vcs.SideHead, line: 7, old: 5, new: 7},
+		{side: vcs.SideHead, line: 8, old: 6, new: 8},
+		{side: vcs.SideHead, line: 9, old: 8, new: 9},
+		{side: vcs.SideBase, line: 2, old: 2, new: 0},
+		{side: vcs.SideBase, line: 5, old: 5, new: 7},
+		{side: vcs.SideBase, line: 7, old: 7, new: 0},
+		{side: vcs.SideBase, line: 8, old: 8, new: 9},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			old, new := mapLine(hs, test.side, test.line)
+			if old != test.old || new != test.new {
+				t.Errorf(
+					"mapLine(%s, %d) = %d, %d; want %d, %d",
+					test.side, test.line, old, new, test.old, test.new,
+				)
+			}
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	ctx := context.Background()
+
+	upstream := t.TempDir()
+	gittest.Run(t, upstream, "init", "--initial-branch", "main")
+	gittest.WriteFile(t, upstream, "main.go", "a\nb\nc\nd\n")
+	gittest.Run(t, upstream, "add", ".")
+	gittest.Run(t, upstream, "commit", "-m", "initial")
+	base := gittest.Run(t, upstream, "rev-parse", "HEAD")
+	gittest.Run(t, upstream, "checkout", "-b", "feature")
+	gittest.WriteFile(t, upstream, "main.go", "a\nB\nc\nd\ne\n")
+	gittest.Run(t, upstream, "commit", "-am", "feature")
+	head := gittest.Run(t, upstream, "rev-parse", "HEAD")
+	gittest.Run(t, upstream, "update-ref", "refs/merge-requests/1/head", head)
+	gittest.Run(t, upstream, "checkout", "main")
+
+	var (
+		mu    sync.Mutex
+		posts = make(map[string][]json.RawMessage)
+	)
+	now := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	mr := apiMergeRequest{
+		IID:          1,
+		Title:        "Feature",
+		Author:       apiUser{Username: "author"},
+		SourceBranch: "feature",
+		TargetBranch: "main",
+		SHA:          head,
+	}
+	mrFull := mr
+	mrFull.DiffRefs = &apiDiffRefs{
+		BaseSHA:  base,
+		StartSHA: base,
+		HeadSHA:  head,
+	}
+	line := func(n int) *int {
+		return &n
+	}
+	discussions := []*apiDiscussion{
+		{
+			ID: "d0",
+			Notes: []*apiNote{
+				{
+					ID:        10,
+					Type:      "DiffNote",
+					Body:      "why uppercase?",
+					Author:    apiUser{Username: "reviewer"},
+					CreatedAt: now,
+					UpdatedAt: now,
+					Position: &apiPosition{
+						HeadSHA:      head,
+						PositionType: "text",
+						OldPath:      "main.go",
+						NewPath:      "main.go",
+						NewLine:      line(2),
+					},
+				},
+				{
+					ID:        11,
+					Type:      "DiffNote",
+					Body:      "because",
+					Author:    apiUser{Username: "author"},
+					CreatedAt: now.Add(time.Minute),
+					UpdatedAt: now.Add(time.Minute),
+					Position: &apiPosition{
+						HeadSHA:      head,
+						PositionType: "text",
+						OldPath:      "main.go",
+						NewPath:      "main.go",
+						NewLine:      line(2),
+					},
+				},
+			},
+		},
+		{
+			ID: "d1",
+			Notes: []*apiNote{
+				{
+					ID:        20,
+					Type:      "DiffNote",
+					Body:      "outdated",
+					Author:    apiUser{Username: "reviewer"},
+					CreatedAt: now,
+					UpdatedAt: now,
+					Position: &apiPosition{
+						HeadSHA:      base,
+						PositionType: "text",
+						NewPath:      "main.go",
+						NewLine:      line(1),
+					},
+				},
+			},
+		},
+		{
+			ID:             "d2",
+			IndividualNote: true,
+			Notes: []*apiNote{
+				{
+					ID:        30,
+					Body:      "LGTM",
+					Author:    apiUser{Username: "reviewer"},
+					CreatedAt: now,
+					UpdatedAt: now,
+				},
+			},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message":"401 Unauthorized"}`)
+			return
+		}
+		var resp interface{}
+		switch r.Method + " " + strings.TrimPrefix(r.URL.EscapedPath(), apiPath) {
+		case "GET /user":
+			resp = apiUser{ID: 1, Username: "reviewer"}
+
+		case "GET /projects/group%2Fproject":
+			resp = apiProject{
+				ID:                42,
+				PathWithNamespace: "group/project",
+				DefaultBranch:     "main",
+				SSHURLToRepo:      upstream,
+			}
+
+		case "GET /projects/42/merge_requests":
+			if act := r.URL.Query().Get("reviewer_username"); act != "reviewer" {
+				t.Errorf("unexpected reviewer: %q", act)
+			}
+			switch r.URL.Query().Get("page") {
+			case "1":
+				w.Header().Set("X-Next-Page", "2")
+				resp = []apiMergeRequest{mr}
+			default:
+				resp = []apiMergeRequest{}
+			}
+
+		case "GET /projects/42/merge_requests/1":
+			resp = mrFull
+
+		case "GET /projects/42/merge_requests/1/discussions":
+			resp = discussions
+
+		case
+			"POST /projects/42/merge_requests/1/discussions",
+			"POST /projects/42/merge_requests/1/discussions/d0/notes":
+
+			var body json.RawMessage
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode request body error: %v", err)
+			}
+			mu.Lock()
+			posts[r.URL.Path] = append(posts[r.URL.Path], body)
+			mu.Unlock()
+
+			var req apiCreateDiscussion
+			json.Unmarshal(body, &req)
+			note := apiNote{
+				ID:        100,
+				Body:      req.Body,
+				Author:    apiUser{Username: "reviewer"},
+				CreatedAt: now,
+				UpdatedAt: now,
+				Position:  req.Position,
+			}
+			if strings.HasSuffix(r.URL.Path, "/notes") {
+				resp = note
+			} else {
+				resp = apiDiscussion{
+					ID:    "new",
+					Notes: []*apiNote{&note},
+				}
+			}
+
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	c := &Client{
+		Project:    "group/project",
+		URL:        srv.URL,
+		Token:      "token",
+		MRTemplate: `!{{ .IID }} @{{ .UserLogin }}: {{ .Title }}`,
+		HTTPClient: srv.Client(),
+	}
+	if err := c.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var items []vcs.ReviewItem
+	if err := c.List(ctx, func(x vcs.ReviewItem) {
+		items = append(items, x)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(items); n != 1 {
+		t.Fatalf("unexpected number of items: %d", n)
+	}
+	if act, exp := items[0].String(), "!1 @author: Feature"; act != exp {
+		t.Errorf("unexpected item string: %q; want %q", act, exp)
+	}
+
+	review, err := c.Select(ctx, items[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer review.Close()
+
+	files, err := review.ChangedFiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []vcs.ChangedFile{{Name: "main.go", Added: 2, Deleted: 1, BaseSize: 8, HeadSize: 10}}; !cmp.Equal(exp, files) {
+		t.Errorf("unexpected changed files:\n%s", cmp.Diff(exp, files))
+	}
+	headFile, err := review.HeadFile(ctx, "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bts, err := ioutil.ReadAll(headFile)
+	headFile.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := string(bts), "a\nB\nc\nd\ne\n"; act != exp {
+		t.Errorf("unexpected head file: %q; want %q", act, exp)
+	}
+
+	cs, err := review.FileComments(ctx, "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(cs); n != 2 {
+		t.Fatalf("unexpected number of comments: %d", n)
+	}
+	for i, c := range cs {
+		if side := c.Side(); side != vcs.SideHead {
+			t.Errorf("unexpected #%d comment side: %s", i, side)
+		}
+		if lo, hi := c.Lines(); lo != 2 || hi != 2 {
+			t.Errorf("unexpected #%d comment lines: %d-%d", i, lo, hi)
+		}
+	}
+	if p := cs[1].Parent(); p == nil || p.ID() != cs[0].ID() {
+		t.Errorf("unexpected parent of reply: %v", p)
+	}
+
+	// Comment on the unchanged line, which must be addressed by both base and
+	// head line numbers.
+	if _, err := review.Comment(ctx, "main.go", vcs.SideHead, 3, 4, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	// Comment on the added line.
+	if _, err := review.Comment(ctx, "main.go", vcs.SideHead, 5, 5, "world"); err != nil {
+		t.Fatal(err)
+	}
+	rep, err := review.ReplyTo(ctx, cs[1], "ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := rep.Parent(); p == nil || p.ID() != cs[0].ID() {
+		t.Errorf("unexpected parent of reply: %v", p)
+	}
+	if lo, hi := rep.Lines(); lo != 2 || hi != 2 {
+		t.Errorf("unexpected reply lines: %d-%d", lo, hi)
+	}
+
+	var created []apiCreateDiscussion
+	for _, raw := range posts["/api/v4/projects/42/merge_requests/1/discussions"] {
+		var x apiCreateDiscussion
+		if err := json.Unmarshal(raw, &x); err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, x)
+	}
+	exp := []apiCreateDiscussion{
+		{
+			Body: "hello",
+			Position: &apiPosition{
+				BaseSHA:      base,
+				StartSHA:     base,
+				HeadSHA:      head,
+				PositionType: "text",
+				OldPath:      "main.go",
+				NewPath:      "main.go",
+				OldLine:      line(4),
+				NewLine:      line(4),
+				LineRange: &apiLineRange{
+					Start: diffLine("main.go", 3, 3),
+					End:   diffLine("main.go", 4, 4),
+				},
+			},
+		},
+		{
+			Body: "world",
+			Position: &apiPosition{
+				BaseSHA:      base,
+				StartSHA:     base,
+				HeadSHA:      head,
+				PositionType: "text",
+				OldPath:      "main.go",
+				NewPath:      "main.go",
+				NewLine:      line(5),
+			},
+		},
+	}
+	if !cmp.Equal(exp, created) {
+		t.Errorf("unexpected created discussions:\n%s", cmp.Diff(exp, created))
+	}
+	if n := len(posts["/api/v4/projects/42/merge_requests/1/discussions/d0/notes"]); n != 1 {
+		t.Errorf("unexpected number of replies: %d", n)
+	}
+}
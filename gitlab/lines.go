@@ -0,0 +1,120 @@
+package gitlab
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+// hunk represents a header of unified diff hunk.
+type hunk struct {
+	oldStart int
+	oldLines int
+	newStart int
+	newLines int
+}
+
+var hunkRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parseHunks parses hunk headers from the unified diff provided by r.
+func parseHunks(r io.Reader) (hs []hunk, err error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		m := hunkRe.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		var (
+			h  hunk
+			xs = [...]*int{&h.oldStart, &h.oldLines, &h.newStart, &h.newLines}
+		)
+		for i, x := range xs {
+			sub := m[i+1]
+			if sub == "" {
+				// Omitted lines count means single line.
+				*x = 1
+				continue
+			}
+			*x, err = strconv.Atoi(sub)
+			if err != nil {
+				return nil, fmt.Errorf("gitlab: malformed hunk header: %v", err)
+			}
+		}
+		hs = append(hs, h)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return hs, nil
+}
+
+// mapLine returns both base (old) and head (new) line numbers for the line
+// at given side. If line was changed, then line number of the opposite side
+// is zero.
+//
+// Hunks must be produced with zero context lines.
+func mapLine(hs []hunk, side vcs.Side, line int) (old, new int) {
+	var offset int // Difference between new and old line numbers.
+	for _, h := range hs {
+		start, lines := h.newStart, h.newLines
+		if side == vcs.SideBase {
+			start, lines = h.oldStart, h.oldLines
+		}
+		if lines == 0 {
+			// Empty range has the address of the line *after* which lines
+			// are added or deleted.
+			start++
+		}
+		if line < start {
+			break
+		}
+		if line < start+lines {
+			if side == vcs.SideBase {
+				return line, 0
+			}
+			return 0, line
+		}
+		offset += h.newLines - h.oldLines
+	}
+	if side == vcs.SideBase {
+		return line, line + offset
+	}
+	return line - offset, line
+}
+
+// lineCode returns GitLab's identifier of the diff line.
+func lineCode(path string, old, new int) string {
+	sum := sha1.Sum([]byte(path))
+	return fmt.Sprintf("%s_%d_%d", hex.EncodeToString(sum[:]), old, new)
+}
+
+var (
+	lineTypeOld = "old"
+	lineTypeNew = "new"
+)
+
+func diffLine(path string, old, new int) apiLine {
+	l := apiLine{
+		LineCode: lineCode(path, old, new),
+	}
+	if old != 0 {
+		l.OldLine = &old
+	}
+	if new != 0 {
+		l.NewLine = &new
+	}
+	switch {
+	case old == 0:
+		l.Type = &lineTypeNew
+	case new == 0:
+		l.Type = &lineTypeOld
+	}
+	return l
+}
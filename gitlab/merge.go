@@ -0,0 +1,236 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gobwas/rw/ed"
+	"github.com/gobwas/rw/internal/gitvcs"
+	"github.com/gobwas/rw/vcs"
+)
+
+type mergeRequest struct {
+	c  *Client
+	mr *apiMergeRequest
+
+	comments vcs.CommentCache
+}
+
+func (m *mergeRequest) Close() error {
+	return m.comments.Close()
+}
+
+func (m *mergeRequest) String() string {
+	return fmt.Sprintf("%s!%d", m.c.project.PathWithNamespace, m.mr.IID)
+}
+
+func (m *mergeRequest) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return gitvcs.ChangedFiles(m.c.git.ChangedFiles(ctx, m.base(), m.head()))
+}
+
+func (m *mergeRequest) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
+	m.comments.Fetch(func(ctx context.Context) (_ map[string][]vcs.Comment, err error) {
+		var ret []*comment
+		query := url.Values{
+			"per_page": {"100"},
+		}
+		for page := 1; page != 0; {
+			query.Set("page", strconv.Itoa(page))
+			var ds []*apiDiscussion
+			page, err = m.c.api.do(ctx,
+				http.MethodGet, m.path()+"/discussions", query,
+				nil, &ds,
+			)
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range ds {
+				if len(d.Notes) == 0 {
+					continue
+				}
+				if p := d.Notes[0].Position; p == nil || p.HeadSHA != m.head() {
+					// Either outdated or not bound to a file.
+					continue
+				}
+				ret = append(ret, discussionComments(d)...)
+			}
+		}
+		return byFile(ret), nil
+	})
+	return m.comments.File(ctx, file)
+}
+
+func (m *mergeRequest) BaseName() string {
+	return m.base()
+}
+func (m *mergeRequest) HeadName() string {
+	return m.head()
+}
+
+func (m *mergeRequest) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return m.c.git.ShowFile(ctx, m.base(), file)
+}
+func (m *mergeRequest) HeadFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return m.c.git.ShowFile(ctx, m.head(), file)
+}
+
+func (m *mergeRequest) Checkout(ctx context.Context) (dir string, cleanup func() error, err error) {
+	return m.c.git.Checkout(ctx, m.head())
+}
+
+func (m *mergeRequest) Edit(ctx context.Context, file string, c ed.Command) (err error) {
+	log.Printf("editing file %s", file)
+	var body string
+	switch c.Mode {
+	case ed.ModeAdd:
+		body = string(bytes.TrimSpace(c.Text))
+		if body == "" {
+			log.Println("empty comment; skipping")
+			return nil
+		}
+	case ed.ModeChange, ed.ModeDelete:
+		// GitLab suggestions are relative to the commented line.
+		body = fmt.Sprintf(
+			"```suggestion:-0+%d\n%s```",
+			c.End-c.Start, c.Text,
+		)
+	default:
+		return nil
+	}
+	_, err = m.Comment(ctx, file, vcs.SideHead, c.Start, c.Start, body)
+	return err
+}
+
+func (m *mergeRequest) Comment(
+	ctx context.Context,
+	file string,
+	side vcs.Side,
+	lo, hi int,
+	body string,
+) (vcs.Comment, error) {
+	pos, err := m.position(ctx, file, side, lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	var d apiDiscussion
+	_, err = m.c.api.do(ctx,
+		http.MethodPost, m.path()+"/discussions", nil,
+		&apiCreateDiscussion{
+			Body:     body,
+			Position: pos,
+		},
+		&d,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Notes) == 0 {
+		return nil, fmt.Errorf("gitlab: created discussion has no notes")
+	}
+	n := d.Notes[0]
+	c := &comment{
+		id:           n.ID,
+		discussionID: d.ID,
+		body:         n.Body,
+		line:         hi,
+		createdAt:    n.CreatedAt,
+		updatedAt:    n.UpdatedAt,
+		userLogin:    n.Author.Username,
+		side:         side,
+		path:         file,
+	}
+	if lo < hi {
+		c.startLine = lo
+	}
+	return c, nil
+}
+
+func (m *mergeRequest) ReplyTo(ctx context.Context, parent vcs.Comment, body string) (vcs.Comment, error) {
+	root := parent.(*comment)
+	if root.parent != nil {
+		root = root.parent
+	}
+	var n apiNote
+	_, err := m.c.api.do(ctx,
+		http.MethodPost, m.path()+"/discussions/"+url.PathEscape(root.discussionID)+"/notes", nil,
+		&apiCreateNote{
+			Body: body,
+		},
+		&n,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &comment{
+		parent:       root,
+		id:           n.ID,
+		discussionID: root.discussionID,
+		body:         n.Body,
+		startLine:    root.startLine,
+		line:         root.line,
+		createdAt:    n.CreatedAt,
+		updatedAt:    n.UpdatedAt,
+		userLogin:    n.Author.Username,
+		side:         root.side,
+		path:         root.path,
+	}, nil
+}
+
+// position builds diff position of the given line range. GitLab requires
+// both base and head line numbers for lines not changed by merge request, so
+// here we map lines using diff between base and head revisions.
+func (m *mergeRequest) position(ctx context.Context, file string, side vcs.Side, lo, hi int) (*apiPosition, error) {
+	diff, err := m.c.git.DiffFile(ctx, m.base(), m.head(), file, 0)
+	if err != nil {
+		return nil, err
+	}
+	hs, err := parseHunks(diff)
+	if e := diff.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	refs := m.mr.DiffRefs
+	pos := &apiPosition{
+		BaseSHA:      refs.BaseSHA,
+		StartSHA:     refs.StartSHA,
+		HeadSHA:      refs.HeadSHA,
+		PositionType: "text",
+		OldPath:      file,
+		NewPath:      file,
+	}
+	old, new := mapLine(hs, side, hi)
+	if old != 0 {
+		pos.OldLine = &old
+	}
+	if new != 0 {
+		pos.NewLine = &new
+	}
+	if lo < hi {
+		startOld, startNew := mapLine(hs, side, lo)
+		pos.LineRange = &apiLineRange{
+			Start: diffLine(file, startOld, startNew),
+			End:   diffLine(file, old, new),
+		}
+	}
+	return pos, nil
+}
+
+func (m *mergeRequest) path() string {
+	return mergeRequestPath(m.c.projectID(), m.mr.IID)
+}
+
+func (m *mergeRequest) base() string {
+	return m.mr.DiffRefs.BaseSHA
+}
+
+func (m *mergeRequest) head() string {
+	return m.mr.DiffRefs.HeadSHA
+}
@@ -0,0 +1,32 @@
+package gitlab
+
+import (
+	"flag"
+)
+
+func DefineFlags(c *Client, fs *flag.FlagSet) {
+	fs.StringVar(&c.Token,
+		"token", "",
+		"personal api token",
+	)
+	fs.StringVar(&c.URL,
+		"url", DefaultURL,
+		"gitlab instance url",
+	)
+	fs.StringVar(&c.User,
+		"user", "",
+		"user to whom review is requested (authenticated user by default)",
+	)
+	fs.IntVar(&c.MRID,
+		"mr", 0,
+		"merge request id",
+	)
+	fs.StringVar(&c.MRTemplate,
+		"mr-template", `!{{ .IID }} @{{ .UserLogin }}: {{ .Title }}`,
+		"merge request template",
+	)
+	fs.StringVar(&c.Origin,
+		"origin", "origin",
+		"name of the git remote upstream to use",
+	)
+}
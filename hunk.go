@@ -0,0 +1,154 @@
+package rw
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+// hunk represents a group of consecutive edit buffers rendered together with
+// surrounding context.
+type hunk struct {
+	q *quick
+
+	lo *editBuffer
+	hi *editBuffer
+
+	contextBefore int
+	contextAfter  int
+
+	// Fields below are filled by Render().
+	beforeLines int
+	afterLines  int
+
+	baseStart int
+	baseStop  int
+	headStart int
+	headStop  int
+}
+
+// newHunk returns hunk starting at b. Following buffers which are not
+// further than contextAfter lines away are joined to the hunk.
+func newHunk(q *quick, b *editBuffer, contextBefore, contextAfter int) *hunk {
+	h := &hunk{
+		q:  q,
+		lo: b,
+		hi: b,
+
+		contextBefore: contextBefore,
+		contextAfter:  contextAfter,
+	}
+	for prev, next := b, q.Next(b); next != nil && baseDistance(prev, next) <= contextAfter; {
+		h.hi = next
+		prev = next
+		next = q.Next(prev)
+	}
+	return h
+}
+
+// hunkStarts returns buffers starting each hunk of q.
+func hunkStarts(q *quick, contextBefore, contextAfter int) (ret []*editBuffer) {
+	for b := q.Front(); b != nil; {
+		ret = append(ret, b)
+		b = q.Next(newHunk(q, b, contextBefore, contextAfter).hi)
+	}
+	return ret
+}
+
+// Render writes hunk header and contents to w.
+func (h *hunk) Render(w io.Writer) {
+	var (
+		q = h.q
+
+		buffers     []io.WriterTo
+		baseLines   int
+		headLines   int
+		staticLines int
+	)
+	for prev, curr := (*editBuffer)(nil), h.lo; curr != nil; prev, curr = curr, q.Next(curr) {
+		baseLines += curr.baseLines
+		headLines += curr.headLines
+
+		if prev != nil {
+			var buf bytes.Buffer
+			staticLines += q.ExpandBetween(&buf, prev, curr)
+			buffers = append(buffers, buffer(buf.Bytes()))
+		}
+		buffers = append(buffers, curr)
+
+		if curr == h.hi {
+			break
+		}
+	}
+	var (
+		before bytes.Buffer
+		after  bytes.Buffer
+	)
+	h.beforeLines = q.ExpandBefore(&before, h.lo, h.contextBefore)
+	h.afterLines = q.ExpandAfter(&after, h.hi, h.contextAfter)
+
+	var (
+		expandLines = h.beforeLines + h.afterLines
+		baseTotal   = baseLines + staticLines + expandLines
+		headTotal   = headLines + staticLines + expandLines
+	)
+	h.baseStart = h.lo.baseStart - h.beforeLines
+	h.baseStop = h.baseStart + baseTotal
+	h.headStart = h.lo.headStart - h.beforeLines
+	h.headStop = h.headStart + headTotal
+
+	fmt.Fprintf(w, "@@ -%d,%d +%d,%d:\n",
+		h.baseStart, baseTotal,
+		h.headStart, headTotal,
+	)
+	before.WriteTo(w)
+	for _, b := range buffers {
+		b.WriteTo(w)
+	}
+	after.WriteTo(w)
+}
+
+// ExpandBefore adds more context before the hunk. If there are no more lines
+// before the hunk, it joins the previous hunk.
+func (h *hunk) ExpandBefore() {
+	if h.q.HasLinesBefore(h.lo, h.beforeLines) {
+		h.contextBefore += 5
+		return
+	}
+	if p := h.q.Prev(h.lo); p != nil {
+		h.lo = p
+	}
+}
+
+// ExpandAfter adds more context after the hunk. If there are no more lines
+// after the hunk, it joins the next hunk.
+func (h *hunk) ExpandAfter() {
+	if h.q.HasLinesAfter(h.hi, h.afterLines) {
+		h.contextAfter += 5
+		return
+	}
+	if n := h.q.Next(h.hi); n != nil {
+		h.hi = n
+	}
+}
+
+// Rerender renders hunk buffers again, for example, to show new comments.
+func (h *hunk) Rerender() {
+	for e := h.lo; e != h.q.Next(h.hi); e = h.q.Next(e) {
+		h.q.RenderBuffer(e)
+	}
+}
+
+// buffers calls it for each edit buffer of the hunk.
+func (h *hunk) buffers(it func(*editBuffer)) {
+	for e := h.lo; e != h.q.Next(h.hi); e = h.q.Next(e) {
+		it(e)
+	}
+}
+
+// Threads returns threads within the hunk lines.
+func (h *hunk) Threads() []vcs.Thread {
+	return h.q.ThreadsBetween(h.baseStart, h.baseStop, h.headStart, h.headStop)
+}
@@ -0,0 +1,93 @@
+package rw
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+// interdiffReview is a review of changes made since a previous head revision.
+// Base versions of its files are the previous head versions, so comments can
+// be made on the head side only.
+//
+// Optional interfaces must be checked on the original review returned by
+// unwrap().
+type interdiffReview struct {
+	vcs.Review
+	diff vcs.Interdiff
+}
+
+func (r *interdiffReview) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return r.diff.ChangedFiles(ctx)
+}
+
+func (r *interdiffReview) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return r.diff.BaseFile(ctx, file)
+}
+
+func (r *interdiffReview) BaseName() string {
+	return r.diff.BaseName()
+}
+
+// FileComments returns head side comments of the file. Lines of base side
+// comments don't match the previous head version of the file.
+func (r *interdiffReview) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
+	cs, err := r.Review.FileComments(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	ret := cs[:0:0]
+	for _, c := range cs {
+		if c.Side() == vcs.SideHead {
+			ret = append(ret, c)
+		}
+	}
+	return ret, nil
+}
+
+func (r *interdiffReview) Comment(ctx context.Context, file string, side vcs.Side, lo, hi int, body string) (vcs.Comment, error) {
+	if side != vcs.SideHead {
+		return nil, fmt.Errorf("can't comment lines of the previous revision")
+	}
+	return r.Review.Comment(ctx, file, side, lo, hi, body)
+}
+
+// unwrap returns the original review if review is an interdiff.
+func unwrap(review vcs.Review) vcs.Review {
+	if r, ok := review.(*interdiffReview); ok {
+		return r.Review
+	}
+	return review
+}
+
+// interdiff returns review of changes made since the last reviewed head
+// revision. It returns review as is if there is no such revision.
+func (r *Review) interdiff(ctx context.Context, review vcs.Review, prog *progress) (vcs.Review, error) {
+	id, ok := review.(vcs.Interdiffer)
+	if !ok {
+		return nil, fmt.Errorf("interdiff is not supported for %s", review)
+	}
+	if prog == nil {
+		return nil, fmt.Errorf("interdiff requires tracking of review progress; use -progress flag")
+	}
+	rev, err := prog.lastReviewed()
+	if err != nil {
+		return nil, err
+	}
+	if rev == "" {
+		fmt.Println("No previously reviewed revision; reviewing all changes.")
+		return review, nil
+	}
+	diff, err := id.Interdiff(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Reviewing changes since %s.\n", shortenRef(rev))
+	prog.since = rev
+	return &interdiffReview{
+		Review: review,
+		diff:   diff,
+	}, nil
+}
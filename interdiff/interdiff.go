@@ -0,0 +1,206 @@
+// Package interdiff computes changes made to a review between two versions
+// of its head.
+//
+// Head versions may have different bases, for example, when the review was
+// rebased. To not show changes brought by the base update, the previous head
+// version of a file is rebased onto the current base first, the same way
+// git-range-diff compares patches.
+package interdiff
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/gobwas/rw/blob"
+	"github.com/gobwas/rw/diffutil"
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/vcs"
+)
+
+// Diff holds changes between the previous and the current head revisions.
+// It implements vcs.Interdiff.
+type Diff struct {
+	git *git.Repository
+
+	prev     string
+	prevBase string
+	head     string
+	base     string
+
+	mu      sync.Mutex
+	rebased map[string][]byte
+}
+
+// New returns changes made between prev and head revisions, both forked
+// from the base branch.
+func New(ctx context.Context, g *git.Repository, base, prev, head string) (*Diff, error) {
+	prevBase, err := g.MergeBase(ctx, prev, base)
+	if err != nil {
+		return nil, err
+	}
+	headBase, err := g.MergeBase(ctx, head, base)
+	if err != nil {
+		return nil, err
+	}
+	return &Diff{
+		git:      g,
+		prev:     prev,
+		prevBase: prevBase,
+		head:     head,
+		base:     headBase,
+		rebased:  make(map[string][]byte),
+	}, nil
+}
+
+// BaseName returns the previous head revision.
+func (d *Diff) BaseName() string {
+	return d.prev
+}
+
+// ChangedFiles returns files which differ between the rebased previous head
+// and the current head.
+func (d *Diff) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	prev, err := d.git.ChangedFiles(ctx, d.prevBase, d.prev)
+	if err != nil {
+		return nil, err
+	}
+	head, err := d.git.ChangedFiles(ctx, d.base, d.head)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]bool)
+	for _, f := range append(prev, head...) {
+		index[f.Name] = true
+	}
+	var ret []vcs.ChangedFile
+	for file := range index {
+		a, err := d.rebase(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.show(ctx, d.head, file)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(a, b) {
+			ret = append(ret, changedFile(file, a, b))
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+// changedFile describes changes made between a and b versions of the file.
+// Nil version means that the file doesn't exist.
+func changedFile(name string, a, b []byte) vcs.ChangedFile {
+	f := vcs.ChangedFile{
+		Name:     name,
+		BaseSize: int64(len(a)),
+		HeadSize: int64(len(b)),
+	}
+	switch {
+	case a == nil:
+		f.Status = vcs.FileStatusAdded
+	case b == nil:
+		f.Status = vcs.FileStatusDeleted
+	}
+	if blob.IsBinary(a) || blob.IsBinary(b) {
+		f.Binary = true
+		return f
+	}
+	var (
+		x = diffutil.Lines(a)
+		y = diffutil.Lines(b)
+	)
+	f.Added, f.Deleted = len(y), len(x)
+	for _, m := range diffutil.Matches(x, y, diffutil.Myers) {
+		f.Added -= m.N
+		f.Deleted -= m.N
+	}
+	return f
+}
+
+// BaseFile returns the previous head version of the file rebased onto the
+// current base.
+func (d *Diff) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	bts, err := d.rebase(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(bts)), nil
+}
+
+// rebase applies changes made to the file in the previous head onto the
+// current base version of the file. If changes can't be applied cleanly, the
+// previous head version is returned as is.
+func (d *Diff) rebase(ctx context.Context, file string) ([]byte, error) {
+	d.mu.Lock()
+	bts, has := d.rebased[file]
+	d.mu.Unlock()
+	if has {
+		return bts, nil
+	}
+	var (
+		revs = [3]string{d.prevBase, d.prev, d.base}
+		srcs [3][]byte
+	)
+	for i, rev := range revs {
+		src, err := d.show(ctx, rev, file)
+		if err != nil {
+			return nil, err
+		}
+		srcs[i] = src
+	}
+	base, prev, curr := srcs[0], srcs[1], srcs[2]
+	switch {
+	case bytes.Equal(base, curr):
+		// Base was not changed.
+		bts = prev
+	case bytes.Equal(base, prev):
+		// File was not changed in the previous head.
+		bts = curr
+	default:
+		merged, ok, err := git.MergeFile(ctx, curr, base, prev)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			bts = merged
+		} else {
+			log.Printf("interdiff: can't rebase %s from %s onto %s", file, d.prev, d.base)
+			bts = prev
+		}
+	}
+	d.mu.Lock()
+	d.rebased[file] = bts
+	d.mu.Unlock()
+	return bts, nil
+}
+
+// show returns contents of the file at given revision. It returns nil if the
+// file doesn't exist.
+func (d *Diff) show(ctx context.Context, rev, file string) ([]byte, error) {
+	if !d.git.HasFile(ctx, rev, file) {
+		return nil, nil
+	}
+	src, err := d.git.ShowFile(ctx, rev, file)
+	if err != nil {
+		return nil, err
+	}
+	bts, err := ioutil.ReadAll(src)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+	if err := src.Close(); err != nil {
+		return nil, err
+	}
+	return bts, nil
+}
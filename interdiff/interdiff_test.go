@@ -0,0 +1,93 @@
+package interdiff
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/internal/gittest"
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	lines := func(xs ...string) string {
+		return strings.Join(xs, "\n") + "\n"
+	}
+	gittest.Run(t, dir, "init", "-q", "-b", "main")
+	gittest.WriteFile(t, dir, "a.txt", lines("1", "2", "3", "4", "5", "6", "7", "8", "9"))
+	gittest.WriteFile(t, dir, "b.txt", lines("x"))
+	gittest.Run(t, dir, "add", "-A")
+	gittest.Run(t, dir, "commit", "-q", "-m", "initial")
+
+	gittest.Run(t, dir, "checkout", "-q", "-b", "feature")
+	gittest.WriteFile(t, dir, "a.txt", lines("1", "two", "3", "4", "5", "6", "7", "8", "9"))
+	gittest.WriteFile(t, dir, "c.txt", lines("c"))
+	gittest.Run(t, dir, "add", "-A")
+	gittest.Run(t, dir, "commit", "-q", "-m", "feature v1")
+	prev := gittest.Run(t, dir, "rev-parse", "HEAD")
+
+	gittest.Run(t, dir, "checkout", "-q", "main")
+	gittest.WriteFile(t, dir, "a.txt", lines("1", "2", "3", "4", "5", "6", "7", "eight", "9"))
+	gittest.WriteFile(t, dir, "b.txt", lines("y"))
+	gittest.Run(t, dir, "commit", "-q", "-am", "base update")
+
+	// Rebase the feature onto the updated base and fix it.
+	gittest.Run(t, dir, "checkout", "-q", "-B", "feature")
+	gittest.WriteFile(t, dir, "a.txt", lines("1", "TWO", "3", "4", "5", "6", "7", "eight", "9"))
+	gittest.WriteFile(t, dir, "c.txt", lines("c"))
+	gittest.WriteFile(t, dir, "d.txt", lines("d"))
+	gittest.Run(t, dir, "add", "-A")
+	gittest.Run(t, dir, "commit", "-q", "-m", "feature v2")
+	head := gittest.Run(t, dir, "rev-parse", "HEAD")
+
+	d, err := New(ctx, &git.Repository{Dir: dir}, "main", prev, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.ChangedFiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []vcs.ChangedFile{
+		{Name: "a.txt", Added: 1, Deleted: 1, BaseSize: 24, HeadSize: 24},
+		{Name: "d.txt", Status: vcs.FileStatusAdded, Added: 1, HeadSize: 2},
+	}
+	if !cmp.Equal(files, exp) {
+		t.Fatalf("unexpected changed files:\n%s", cmp.Diff(exp, files))
+	}
+	for _, test := range []struct {
+		file string
+		exp  string
+	}{
+		{
+			file: "a.txt",
+			exp:  lines("1", "two", "3", "4", "5", "6", "7", "eight", "9"),
+		},
+		{
+			file: "b.txt",
+			exp:  lines("y"),
+		},
+		{
+			file: "d.txt",
+			exp:  "",
+		},
+	} {
+		src, err := d.BaseFile(ctx, test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bts, err := ioutil.ReadAll(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if act := string(bts); act != test.exp {
+			t.Errorf("unexpected base version of %s: %q; want %q", test.file, act, test.exp)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Package gitvcs converts results of the git package into vcs types for the
+// providers built on top of git repositories.
+package gitvcs
+
+import (
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/vcs"
+)
+
+// ChangedFiles converts files returned by git.Repository.ChangedFiles().
+// Non-nil err is returned as is, so it can wrap the call directly.
+func ChangedFiles(fs []git.ChangedFile, err error) ([]vcs.ChangedFile, error) {
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]vcs.ChangedFile, len(fs))
+	for i, f := range fs {
+		ret[i] = vcs.ChangedFile{
+			Name:     f.Name,
+			OldName:  f.OldName,
+			Status:   fileStatus(f.Status),
+			Binary:   f.Binary,
+			Added:    f.Added,
+			Deleted:  f.Deleted,
+			BaseSize: f.BaseSize,
+			HeadSize: f.HeadSize,
+		}
+	}
+	return ret, nil
+}
+
+func fileStatus(s byte) vcs.FileStatus {
+	switch s {
+	case 'A':
+		return vcs.FileStatusAdded
+	case 'D':
+		return vcs.FileStatusDeleted
+	case 'R':
+		return vcs.FileStatusRenamed
+	case 'C':
+		return vcs.FileStatusCopied
+	default:
+		return vcs.FileStatusModified
+	}
+}
@@ -0,0 +1,182 @@
+package rw
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/diffutil"
+	"github.com/gobwas/rw/syntax"
+)
+
+// span represents a byte range [lo, hi) within a line.
+type span struct {
+	lo, hi int
+}
+
+// minSimilarity is the minimum ratio of unchanged bytes for a pair of lines
+// to be highlighted word by word. Lines which are less similar are treated as
+// completely rewritten.
+const minSimilarity = 0.5
+
+// pairSpans pairs i-th deleted line with i-th added line and returns changed
+// spans for each of them. Lines with no pair or without enough similarity
+// with their pair get nil spans.
+func pairSpans(base, head [][]byte) (bs, hs [][]span) {
+	bs = make([][]span, len(base))
+	hs = make([][]span, len(head))
+	for i := 0; i < len(base) && i < len(head); i++ {
+		bs[i], hs[i] = intraline(base[i], head[i])
+	}
+	return bs, hs
+}
+
+// intraline computes token level difference between a and b and returns
+// spans of a which were deleted and spans of b which were inserted.
+func intraline(a, b []byte) (as, bs []span) {
+	a = bytes.TrimRight(a, "\n")
+	b = bytes.TrimRight(b, "\n")
+	if bytes.Equal(a, b) {
+		return nil, nil
+	}
+	var (
+		ta = tokenize(a)
+		tb = tokenize(b)
+
+		oa = offsets(ta)
+		ob = offsets(tb)
+
+		common int
+	)
+	ms := diffutil.Matches(ta, tb, diffutil.Myers)
+	for i := 1; i < len(ms); i++ {
+		var (
+			prev = ms[i-1]
+			next = ms[i]
+		)
+		common += oa[prev.A+prev.N] - oa[prev.A]
+		if lo, hi := oa[prev.A+prev.N], oa[next.A]; lo < hi {
+			as = append(as, span{lo, hi})
+		}
+		if lo, hi := ob[prev.B+prev.N], ob[next.B]; lo < hi {
+			bs = append(bs, span{lo, hi})
+		}
+	}
+	if float64(2*common) < minSimilarity*float64(len(a)+len(b)) {
+		return nil, nil
+	}
+	return as, bs
+}
+
+// tokenize splits line into words, runs of spaces and single punctuation
+// characters.
+func tokenize(line []byte) (ts [][]byte) {
+	for len(line) > 0 {
+		r, n := utf8.DecodeRune(line)
+		class := tokenClass(r)
+		if class != 0 {
+			for n < len(line) {
+				r, m := utf8.DecodeRune(line[n:])
+				if tokenClass(r) != class {
+					break
+				}
+				n += m
+			}
+		}
+		ts = append(ts, line[:n])
+		line = line[n:]
+	}
+	return ts
+}
+
+// tokenClass returns non-zero class for runes which may be grouped together
+// into a single token.
+func tokenClass(r rune) int {
+	switch {
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	case unicode.IsSpace(r):
+		return 2
+	default:
+		return 0
+	}
+}
+
+// offsets returns byte offsets of each token and the total length as the last
+// element.
+func offsets(ts [][]byte) []int {
+	ret := make([]int, len(ts)+1)
+	for i, t := range ts {
+		ret[i+1] = ret[i] + len(t)
+	}
+	return ret
+}
+
+// styleLine returns line colored with fg and given syntax tokens, with emph
+// spans emphasized. Syntax tokens are ignored if theme is nil. Line is
+// returned as is if there is nothing to style.
+func styleLine(line []byte, fg color.Color, theme *syntax.Theme, tokens []syntax.Span, emph []span) []byte {
+	type style struct {
+		fg   color.Color
+		emph bool
+	}
+	styles := make([]style, len(line))
+	for i := range styles {
+		styles[i].fg = fg
+	}
+	if theme != nil {
+		for _, t := range tokens {
+			c := theme.Color(t.Kind)
+			if c == color.Unknown {
+				continue
+			}
+			for i := t.Lo; i < t.Hi && i < len(line); i++ {
+				styles[i].fg = c
+			}
+		}
+	}
+	for _, s := range emph {
+		for i := s.lo; i < s.hi && i < len(line); i++ {
+			styles[i].emph = true
+		}
+	}
+	var (
+		buf     bytes.Buffer
+		prev    style
+		colored bool
+	)
+	for i, s := range styles {
+		switch {
+		case i == 0 || s.fg != prev.fg:
+			// Reset everything since color sequences may contain attributes
+			// which can not be turned off separately.
+			if i > 0 {
+				color.Freset(&buf)
+			}
+			if s.fg != color.Unknown {
+				color.Fbegin(&buf, s.fg)
+				colored = true
+			}
+			if s.emph {
+				color.Fattr(&buf, color.Reverse)
+			}
+		case s.emph && !prev.emph:
+			color.Fattr(&buf, color.Reverse)
+		case !s.emph && prev.emph:
+			// Keep the color which might be set outside of the line.
+			color.FresetAttr(&buf, color.Reverse)
+		}
+		prev = s
+		buf.WriteByte(line[i])
+	}
+	switch {
+	case buf.Len() == len(line):
+		return line
+	case colored:
+		color.Freset(&buf)
+	case prev.emph:
+		color.FresetAttr(&buf, color.Reverse)
+	}
+	return buf.Bytes()
+}
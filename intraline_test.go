@@ -0,0 +1,95 @@
+package rw
+
+import (
+	"testing"
+
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/syntax"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIntraline(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		a, b string
+		expA []span
+		expB []span
+	}{
+		{
+			name: "equal",
+			a:    "foo(bar)\n",
+			b:    "foo(bar)\n",
+		},
+		{
+			name: "word",
+			a:    "return foo(bar, baz)\n",
+			b:    "return foo(qux, baz)\n",
+			expA: []span{{11, 14}},
+			expB: []span{{11, 14}},
+		},
+		{
+			name: "insertion",
+			a:    "x := y",
+			b:    "x := y + 1",
+			expB: []span{{6, 10}},
+		},
+		{
+			name: "rewritten",
+			a:    "completely different",
+			b:    "nothing in common",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			actA, actB := intraline([]byte(test.a), []byte(test.b))
+			if !cmp.Equal(actA, test.expA, cmp.AllowUnexported(span{})) {
+				t.Errorf("unexpected base spans: %v; want %v", actA, test.expA)
+			}
+			if !cmp.Equal(actB, test.expB, cmp.AllowUnexported(span{})) {
+				t.Errorf("unexpected head spans: %v; want %v", actB, test.expB)
+			}
+		})
+	}
+}
+
+func TestStyleLine(t *testing.T) {
+	line := []byte("return foo(bar)")
+	for _, test := range []struct {
+		name   string
+		fg     color.Color
+		theme  *syntax.Theme
+		tokens []syntax.Span
+		emph   []span
+		styled bool
+	}{
+		{
+			name: "plain",
+		},
+		{
+			name:   "color",
+			fg:     color.Green,
+			styled: true,
+		},
+		{
+			name:   "emphasis",
+			emph:   []span{{11, 14}},
+			styled: true,
+		},
+		{
+			name:   "syntax",
+			theme:  &syntax.DefaultTheme,
+			tokens: []syntax.Span{{Lo: 0, Hi: 6, Kind: syntax.Keyword}},
+			emph:   []span{{11, 14}},
+			styled: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			act := styleLine(line, test.fg, test.theme, test.tokens, test.emph)
+			if exp := string(line); color.FilterString(string(act)) != exp {
+				t.Fatalf("unexpected filtered line: %q; want %q", color.Filter(act), exp)
+			}
+			if styled := string(act) != string(line); styled != test.styled {
+				t.Fatalf("unexpected line styling: %q", act)
+			}
+		})
+	}
+}
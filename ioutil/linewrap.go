@@ -16,6 +16,7 @@ type LineWrapWriter struct {
 	lim   int
 	runes int
 	pad   byte
+	brk   bool
 
 	runeCounter func([]byte) int
 }
@@ -31,6 +32,12 @@ func (w *LineWrapWriter) SetPad(c byte) {
 	w.pad = c
 }
 
+// SetBreakWords makes w to break words which do not fit the limit. By default
+// such words are written as is, exceeding the limit.
+func (w *LineWrapWriter) SetBreakWords(v bool) {
+	w.brk = v
+}
+
 func (w *LineWrapWriter) SetRuneCounter(fn func([]byte) int) {
 	w.runeCounter = fn
 }
@@ -57,6 +64,7 @@ func (w *LineWrapWriter) Write(p []byte) (n int, err error) {
 		var (
 			noLine   bool
 			overflow bool
+			cut      bool // Line is broken not at the separator.
 		)
 		i := bytes.IndexByte(p, '\n')
 		if i == -1 {
@@ -66,11 +74,18 @@ func (w *LineWrapWriter) Write(p []byte) (n int, err error) {
 		c := w.runeCount(p[:i])
 		for w.runes+c > w.lim {
 			j := bytes.LastIndexByte(p[:i], ' ') // TODO any space actually \t, etc.
+			if j == -1 && w.brk {
+				i = w.cutIndex(p[:i])
+				c = w.runeCount(p[:i])
+				noLine = false
+				cut = true
+				break
+			}
 			if j == -1 {
 				overflow = true
 				break
 			}
-			c -= i - j
+			c = w.runeCount(p[:j])
 			i = j
 			noLine = false
 		}
@@ -92,7 +107,9 @@ func (w *LineWrapWriter) Write(p []byte) (n int, err error) {
 			}
 			if w.err == nil && !noLine {
 				_, w.err = w.w.Write(newline)
-				n += 1
+				if !cut {
+					n += 1
+				}
 			}
 		} else {
 			_, w.err = w.buf.WriteTo(w.w)
@@ -106,18 +123,41 @@ func (w *LineWrapWriter) Write(p []byte) (n int, err error) {
 			}
 			if w.err == nil {
 				_, w.err = w.w.Write(newline)
-				n += 1
+				if !cut {
+					n += 1
+				}
 			}
 		}
 		w.runes = 0
 		if i == len(p) {
 			break
 		}
-		p = p[i+1:]
+		if cut {
+			p = p[i:]
+		} else {
+			p = p[i+1:]
+		}
 	}
 	return n, w.err
 }
 
+// cutIndex returns the largest rune boundary index of p such that p up to
+// that index fits the rest of the current line. It returns at least one rune
+// if the current line is empty.
+func (w *LineWrapWriter) cutIndex(p []byte) (k int) {
+	for i := 0; i < len(p); {
+		_, n := utf8.DecodeRune(p[i:])
+		i += n
+		if w.runes+w.runeCount(p[:i]) <= w.lim {
+			k = i
+		}
+	}
+	if k == 0 && w.runes == 0 {
+		_, k = utf8.DecodeRune(p)
+	}
+	return k
+}
+
 func (w *LineWrapWriter) TODOWriteString(s string) (int, error) {
 	return -1, nil
 }
@@ -11,6 +11,7 @@ func TestWrapLineWriter(t *testing.T) {
 		name string
 		size int
 		pad  byte
+		brk  bool
 		in   []string
 		exp  string
 	}{
@@ -81,11 +82,49 @@ func TestWrapLineWriter(t *testing.T) {
 			},
 			exp: "кошка**\nпёсик",
 		},
+		{
+			name: "unicode words",
+			size: 5,
+			in: []string{
+				"abc def ёёёё",
+			},
+			exp: "abc\ndef\nёёёё",
+		},
+		{
+			name: "break words",
+			size: 5,
+			brk:  true,
+			in: []string{
+				"xxxxxxx yy",
+			},
+			exp: "xxxxx\nxx yy",
+		},
+		{
+			name: "break words continued",
+			size: 5,
+			pad:  '-',
+			brk:  true,
+			in: []string{
+				"xxx",
+				"yyyyyyy",
+			},
+			exp: "xxxyy\nyyyyy",
+		},
+		{
+			name: "break words unicode",
+			size: 3,
+			brk:  true,
+			in: []string{
+				"пёсик",
+			},
+			exp: "пёс\nик",
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			var buf bytes.Buffer
 			w := NewLineWrapWriter(&buf, test.size)
 			w.SetPad(test.pad)
+			w.SetBreakWords(test.brk)
 
 			for _, s := range test.in {
 				act, err := io.WriteString(w, s)
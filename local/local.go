@@ -0,0 +1,170 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/vcs"
+)
+
+// NotesRef is a git notes ref where review comments are stored.
+const NotesRef = "refs/notes/rw"
+
+// emptyTree is a hash of the empty git tree. It is used as a base for root
+// commits.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Client is a vcs.Provider which works on a local git repository only.
+// Review comments are stored as git notes attached to the head commit of the
+// review.
+type Client struct {
+	Dir     string
+	Base    string
+	Branch  string
+	Range   string
+	Commits bool
+
+	once sync.Once
+	git  *git.Repository
+	user string
+	err  error
+}
+
+func (c *Client) Init(ctx context.Context) error {
+	c.once.Do(func() {
+		c.git = &git.Repository{
+			Dir: c.Dir,
+		}
+		c.user, _ = c.git.Config(ctx, "user.email")
+		if c.user == "" {
+			c.user, _ = c.git.Config(ctx, "user.name")
+		}
+		if c.Base == "" && c.Range == "" {
+			c.Base, c.err = c.defaultBase(ctx)
+		}
+	})
+	return c.err
+}
+
+func (c *Client) defaultBase(ctx context.Context) (string, error) {
+	for _, b := range []string{"main", "master"} {
+		if _, err := c.git.RevParse(ctx, b); err == nil {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("local: can't detect base branch")
+}
+
+func (c *Client) List(ctx context.Context, fn func(vcs.ReviewItem)) error {
+	if rng := c.Range; rng != "" {
+		base, head := splitRange(rng)
+		if base == "" || head == "" {
+			return fmt.Errorf("local: malformed commit range: %q", rng)
+		}
+		fn(&item{
+			base: base,
+			head: head,
+		})
+		return nil
+	}
+	if c.Commits {
+		rev := c.Branch
+		if rev == "" {
+			rev = "HEAD"
+		}
+		// List only commits which are not in the base branch.
+		rng := c.Base + ".." + rev
+		lines, err := c.git.LogRange(ctx, rng, "%P", "%H", "%h", "%aL", "%s")
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			parent, _ := split2(line[0], ' ')
+			if parent == "" {
+				parent = emptyTree
+			}
+			fn(&item{
+				base:   parent,
+				head:   line[1],
+				commit: true,
+				title:  fmt.Sprintf("%s: %s: %s", line[2], line[3], line[4]),
+			})
+		}
+		return nil
+	}
+	branches, err := c.git.Branches(ctx)
+	if err != nil {
+		return err
+	}
+	for _, b := range branches {
+		if b == c.Base {
+			continue
+		}
+		fn(&item{
+			base: c.Base,
+			head: b,
+		})
+	}
+	return nil
+}
+
+func (c *Client) Select(ctx context.Context, x vcs.ReviewItem) (vcs.Review, error) {
+	switch v := x.(type) {
+	case *item:
+		head, err := c.git.RevParse(ctx, v.head)
+		if err != nil {
+			return nil, err
+		}
+		base := v.base
+		if !v.commit {
+			// Review changes made since the branches diverged.
+			base, err = c.git.MergeBase(ctx, v.base, head)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &review{
+			c:    c,
+			item: v,
+			base: base,
+			head: head,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("local: select: unsupported type: %T", v)
+	}
+}
+
+type item struct {
+	base   string
+	head   string
+	commit bool
+	title  string
+}
+
+func (i *item) String() string {
+	if i.title != "" {
+		return i.title
+	}
+	return i.base + ".." + i.head
+}
+
+// splitRange splits range of commits like "a..b" or "a...b".
+func splitRange(s string) (base, head string) {
+	i := strings.Index(s, "..")
+	if i == -1 {
+		return "", ""
+	}
+	return s[:i], strings.TrimPrefix(s[i+2:], ".")
+}
+
+func split2(s string, c byte) (string, string) {
+	i := strings.IndexByte(s, c)
+	if i == -1 {
+		return s, ""
+	}
+	return s[:i], s[i+1:]
+}
@@ -0,0 +1,147 @@
+package local
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/gobwas/rw/internal/gittest"
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "rw-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	gittest.Run(t, dir, "init", "-q", "-b", "main")
+	gittest.Run(t, dir, "config", "user.email", "test@example.com")
+	gittest.WriteFile(t, dir, "a.txt", "foo\nbar\n")
+	gittest.Run(t, dir, "add", "-A")
+	gittest.Run(t, dir, "commit", "-q", "-m", "initial")
+	gittest.Run(t, dir, "checkout", "-q", "-b", "feature")
+	gittest.WriteFile(t, dir, "a.txt", "foo\nbaz\n")
+	gittest.Run(t, dir, "commit", "-q", "-am", "change")
+	gittest.Run(t, dir, "checkout", "-q", "main")
+
+	selectFeature := func() vcs.Review {
+		c := &Client{
+			Dir: dir,
+		}
+		if err := c.Init(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var items []vcs.ReviewItem
+		err := c.List(ctx, func(x vcs.ReviewItem) {
+			items = append(items, x)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(items); n != 1 {
+			t.Fatalf("unexpected number of items: %d", n)
+		}
+		r, err := c.Select(ctx, items[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	r := selectFeature()
+	files, err := r.ChangedFiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []vcs.ChangedFile{{Name: "a.txt", Added: 1, Deleted: 1, BaseSize: 8, HeadSize: 8}}; !cmp.Equal(files, exp) {
+		t.Fatalf("unexpected changed files: %v", files)
+	}
+	c0, err := r.Comment(ctx, "a.txt", vcs.SideHead, 1, 2, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReplyTo(ctx, c0, "world"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read comments back with a fresh client.
+	cs, err := selectFeature().FileComments(ctx, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		Body   string
+		User   string
+		Lo, Hi int
+		Side   vcs.Side
+		Parent string
+	}
+	var act []result
+	for _, c := range cs {
+		lo, hi := c.Lines()
+		var parent string
+		if p := c.Parent(); p != nil {
+			parent = p.Body()
+		}
+		act = append(act, result{
+			Body:   c.Body(),
+			User:   c.UserLogin(),
+			Lo:     lo,
+			Hi:     hi,
+			Side:   c.Side(),
+			Parent: parent,
+		})
+	}
+	exp := []result{
+		{Body: "hello", User: "test@example.com", Lo: 1, Hi: 2, Side: vcs.SideHead},
+		{Body: "world", User: "test@example.com", Lo: 1, Hi: 2, Side: vcs.SideHead, Parent: "hello"},
+	}
+	if !cmp.Equal(act, exp) {
+		t.Fatalf("unexpected comments:\n%s", cmp.Diff(exp, act))
+	}
+}
+
+func TestClientCommits(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "rw-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	gittest.Run(t, dir, "init", "-q", "-b", "main")
+	gittest.Run(t, dir, "config", "user.email", "test@example.com")
+	gittest.WriteFile(t, dir, "a.txt", "foo\n")
+	gittest.Run(t, dir, "add", "-A")
+	gittest.Run(t, dir, "commit", "-q", "-m", "initial")
+	gittest.Run(t, dir, "checkout", "-q", "-b", "feature")
+	gittest.WriteFile(t, dir, "a.txt", "bar\n")
+	gittest.Run(t, dir, "commit", "-q", "-am", "change")
+
+	c := &Client{
+		Dir:     dir,
+		Commits: true,
+	}
+	if err := c.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var act []string
+	err = c.List(ctx, func(x vcs.ReviewItem) {
+		// Strip abbreviated hash of the commit.
+		_, title := split2(x.(*item).title, ' ')
+		act = append(act, title)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"test: change"}; !cmp.Equal(act, exp) {
+		t.Fatalf("unexpected commits:\n%s", cmp.Diff(exp, act))
+	}
+}
@@ -0,0 +1,275 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/rw/ed"
+	"github.com/gobwas/rw/interdiff"
+	"github.com/gobwas/rw/internal/gitvcs"
+	"github.com/gobwas/rw/vcs"
+)
+
+type review struct {
+	c    *Client
+	item *item
+	base string
+	head string
+
+	mu       sync.Mutex
+	loaded   bool
+	comments map[string][]vcs.Comment
+	index    map[string]*comment
+}
+
+func (r *review) String() string {
+	return r.item.String()
+}
+
+func (r *review) Close() error {
+	return nil
+}
+
+func (r *review) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return gitvcs.ChangedFiles(r.c.git.ChangedFiles(ctx, r.base, r.head))
+}
+
+func (r *review) BaseName() string {
+	return r.base
+}
+func (r *review) HeadName() string {
+	return r.head
+}
+
+// Interdiff implements vcs.Interdiffer.
+func (r *review) Interdiff(ctx context.Context, rev string) (vcs.Interdiff, error) {
+	return interdiff.New(ctx, r.c.git, r.base, rev, r.head)
+}
+
+func (r *review) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return r.c.git.ShowFile(ctx, r.base, file)
+}
+func (r *review) HeadFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return r.c.git.ShowFile(ctx, r.head, file)
+}
+
+func (r *review) Checkout(ctx context.Context) (dir string, cleanup func() error, err error) {
+	return r.c.git.Checkout(ctx, r.head)
+}
+
+func (r *review) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+	return r.comments[file], nil
+}
+
+// load reads comments stored in the note of the head commit.
+// Note contains one JSON encoded entry per line.
+func (r *review) load(ctx context.Context) error {
+	if r.loaded {
+		return nil
+	}
+	note, err := r.c.git.ShowNote(ctx, NotesRef, r.head)
+	if err != nil {
+		return err
+	}
+	es, err := parseEntries(strings.NewReader(note))
+	if err != nil {
+		return err
+	}
+	r.comments = make(map[string][]vcs.Comment)
+	r.index = make(map[string]*comment)
+	for _, e := range es {
+		r.add(e)
+	}
+	r.loaded = true
+	return nil
+}
+
+func (r *review) add(e *entry) *comment {
+	c := &comment{
+		entry: e,
+	}
+	if e.Parent != "" {
+		c.parent = r.index[e.Parent]
+		if c.parent == nil {
+			log.Printf("local: no parent comment %q for %q", e.Parent, e.ID)
+		}
+	}
+	r.index[e.ID] = c
+	r.comments[e.File] = append(r.comments[e.File], c)
+	return c
+}
+
+func (r *review) Edit(ctx context.Context, file string, cmd ed.Command) error {
+	log.Printf("editing file %s", file)
+	var body string
+	switch cmd.Mode {
+	case ed.ModeAdd:
+		body = string(bytes.TrimSpace(cmd.Text))
+		if body == "" {
+			log.Println("empty comment; skipping")
+			return nil
+		}
+	case ed.ModeChange, ed.ModeDelete:
+		body = fmt.Sprintf("```suggestion\n%s```", cmd.Text)
+	default:
+		return nil
+	}
+	_, err := r.Comment(ctx, file, vcs.SideHead, cmd.Start, cmd.End, body)
+	return err
+}
+
+func (r *review) Comment(
+	ctx context.Context,
+	file string,
+	side vcs.Side,
+	lo, hi int,
+	body string,
+) (vcs.Comment, error) {
+	e := &entry{
+		File: file,
+		Side: side.String(),
+		Line: hi,
+		Body: body,
+	}
+	if lo < hi {
+		e.StartLine = lo
+	}
+	return r.store(ctx, e)
+}
+
+func (r *review) ReplyTo(ctx context.Context, parent vcs.Comment, body string) (vcs.Comment, error) {
+	p := parent.(*comment)
+	if p.parent != nil {
+		p = p.parent
+	}
+	e := &entry{
+		Parent:    p.entry.ID,
+		File:      p.entry.File,
+		Side:      p.entry.Side,
+		StartLine: p.entry.StartLine,
+		Line:      p.entry.Line,
+		Body:      body,
+	}
+	return r.store(ctx, e)
+}
+
+func (r *review) store(ctx context.Context, e *entry) (*comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	e.ID = id
+	e.Author = r.c.user
+	e.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	bts, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.c.git.AppendNote(ctx, NotesRef, r.head, string(bts)); err != nil {
+		return nil, err
+	}
+	return r.add(e), nil
+}
+
+func newID() (string, error) {
+	var p [8]byte
+	if _, err := rand.Read(p[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(p[:]), nil
+}
+
+// entry is a comment representation stored in git notes.
+type entry struct {
+	ID        string    `json:"id"`
+	Parent    string    `json:"parent,omitempty"`
+	File      string    `json:"file"`
+	Side      string    `json:"side"`
+	StartLine int       `json:"start_line,omitempty"`
+	Line      int       `json:"line"`
+	Body      string    `json:"body"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// parseEntries parses entries from the note. Empty lines are skipped since
+// `git notes append` separates appended texts with a blank line.
+func parseEntries(r io.Reader) (es []*entry, err error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for line := 1; s.Scan(); line++ {
+		bts := bytes.TrimSpace(s.Bytes())
+		if len(bts) == 0 {
+			continue
+		}
+		e := new(entry)
+		if err := json.Unmarshal(bts, e); err != nil {
+			return nil, fmt.Errorf("local: malformed note entry at line %d: %v", line, err)
+		}
+		es = append(es, e)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return es, nil
+}
+
+type comment struct {
+	*entry
+	parent *comment
+}
+
+func (c *comment) Lines() (lo, hi int) {
+	if c.StartLine != 0 {
+		return c.StartLine, c.Line
+	}
+	return c.Line, c.Line
+}
+func (c *comment) Body() string {
+	return c.entry.Body
+}
+func (c *comment) CreatedAt() time.Time {
+	return c.entry.CreatedAt
+}
+func (c *comment) UpdatedAt() time.Time {
+	return c.entry.CreatedAt
+}
+func (c *comment) UserLogin() string {
+	return c.Author
+}
+func (c *comment) Side() vcs.Side {
+	if c.entry.Side == vcs.SideBase.String() {
+		return vcs.SideBase
+	}
+	return vcs.SideHead
+}
+func (c *comment) Parent() vcs.Comment {
+	if c.parent != nil {
+		return c.parent
+	}
+	return nil
+}
+func (c *comment) ID() string {
+	return c.entry.ID
+}
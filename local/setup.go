@@ -0,0 +1,20 @@
+package local
+
+import (
+	"flag"
+)
+
+func DefineFlags(c *Client, fs *flag.FlagSet) {
+	fs.StringVar(&c.Dir,
+		"dir", "",
+		"path to the git repository (current directory by default)",
+	)
+	fs.StringVar(&c.Base,
+		"base", "",
+		"branch to compare other branches or commits with (main or master by default)",
+	)
+	fs.StringVar(&c.Range,
+		"range", "",
+		"commit range to review, e.g. v1.0..v1.1",
+	)
+}
@@ -0,0 +1,160 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gobwas/rw/color"
+)
+
+// inline returns text with inline markup replaced by terminal styles.
+func inline(text string) string {
+	var buf bytes.Buffer
+	for len(text) > 0 {
+		switch c := text[0]; {
+		case c == '\\' && len(text) > 1 && isPunct(text[1]):
+			buf.WriteByte(text[1])
+			text = text[2:]
+			continue
+
+		case c == '`':
+			n := len(text) - len(strings.TrimLeft(text, "`"))
+			delim := text[:n]
+			if i := strings.Index(text[n:], delim); i != -1 {
+				code := strings.TrimSpace(text[n : n+i])
+				buf.WriteString(color.Sprint(color.Cyan, code))
+				text = text[n+i+n:]
+				continue
+			}
+			buf.WriteString(delim)
+			text = text[n:]
+			continue
+
+		case c == '*' || c == '_':
+			if s, rest, ok := emphasis(text, prevRune(buf.Bytes())); ok {
+				buf.WriteString(s)
+				text = rest
+				continue
+			}
+
+		case c == '[':
+			if s, rest, ok := link(text); ok {
+				buf.WriteString(s)
+				text = rest
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(text)
+		buf.WriteString(text[:size])
+		text = text[size:]
+	}
+	return buf.String()
+}
+
+// emphasis parses emphasis at the beginning of text. Double delimiters make
+// text bold, single ones make it underlined.
+func emphasis(text string, prev rune) (s, rest string, ok bool) {
+	delim := text[:1]
+	if strings.HasPrefix(text, delim+delim) {
+		delim += delim
+	}
+	if delim[0] == '_' && isWord(prev) {
+		// Underscores within words like snake_case are not emphasis.
+		return "", "", false
+	}
+	inner := text[len(delim):]
+	if inner == "" || unicode.IsSpace(rune(inner[0])) {
+		return "", "", false
+	}
+	i := closingDelim(inner, delim)
+	if i == -1 {
+		return "", "", false
+	}
+	styled := inline(inner[:i])
+	if len(delim) == 2 {
+		styled = bold(styled)
+	} else {
+		styled = underline(styled)
+	}
+	return styled, inner[i+len(delim):], true
+}
+
+// closingDelim returns index of the delimiter closing emphasis in text.
+// Closing delimiter must follow non-space character and must not be a part of
+// a longer delimiter run.
+func closingDelim(text, delim string) int {
+	for i := 0; i < len(text); {
+		j := strings.Index(text[i:], delim)
+		if j == -1 {
+			return -1
+		}
+		j += i
+		after, _ := utf8.DecodeRuneInString(text[j+len(delim):])
+		ok := j > 0 && text[j-1] != ' ' && !strings.ContainsRune("*_", after)
+		if ok && delim[0] == '_' && isWord(after) {
+			ok = false
+		}
+		if ok {
+			return j
+		}
+		i = j + len(delim)
+	}
+	return -1
+}
+
+// link parses [text](url) link at the beginning of text.
+func link(text string) (s, rest string, ok bool) {
+	i := strings.Index(text, "](")
+	if i == -1 || strings.ContainsRune(text[1:i], '\n') {
+		return "", "", false
+	}
+	j := strings.IndexByte(text[i+2:], ')')
+	if j == -1 {
+		return "", "", false
+	}
+	var (
+		title = text[1:i]
+		url   = text[i+2 : i+2+j]
+	)
+	if title == "" || title == url {
+		s = underline(url)
+	} else {
+		s = underline(inline(title)) + color.Sprintf(color.Grey, " (%s)", url)
+	}
+	return s, text[i+2+j+1:], true
+}
+
+func bold(s string) string {
+	return attr(s, color.Bold)
+}
+
+func underline(s string) string {
+	return attr(s, color.Underline)
+}
+
+func attr(s string, a color.Attribute) string {
+	var buf bytes.Buffer
+	color.Fattr(&buf, a)
+	buf.WriteString(s)
+	color.FresetAttr(&buf, a)
+	return buf.String()
+}
+
+func prevRune(p []byte) rune {
+	p = color.Filter(p)
+	r, _ := utf8.DecodeLastRune(p)
+	if r == utf8.RuneError {
+		return 0
+	}
+	return r
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) != -1
+}
@@ -0,0 +1,319 @@
+// Package markdown renders a subset of markdown for a terminal output.
+//
+// It supports paragraphs, headings, fenced code blocks, bullet and ordered
+// lists, block quotes, horizontal rules and inline emphasis, code spans and
+// links. Everything else is rendered as a plain text.
+package markdown
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/ioutil"
+)
+
+const tabWidth = 4
+
+// Render writes markdown src to w. Text is wrapped to fit width characters.
+// Lines of fenced code blocks are written as is and are broken only if they
+// don't fit the width.
+func Render(w io.Writer, src string, width int) {
+	src = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(src)
+	renderBlocks(w, strings.Split(src, "\n"), width)
+}
+
+// renderBlocks renders lines as a sequence of blocks. Blank lines between
+// blocks are collapsed to a single one.
+func renderBlocks(w io.Writer, lines []string, width int) {
+	if width < 1 {
+		width = 1
+	}
+	var (
+		started bool
+		blank   bool
+	)
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			blank = true
+			i++
+			continue
+		}
+		if started && blank {
+			io.WriteString(w, "\n")
+		}
+		started = true
+		blank = false
+
+		switch {
+		case isFence(line):
+			i = renderCode(w, lines, i, width)
+		case isQuote(line):
+			i = renderQuote(w, lines, i, width)
+		case isHeading(line):
+			text := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+			wrap(w, bold(inline(text)), width)
+			i++
+		case isRule(line):
+			io.WriteString(w, color.Sprint(color.Grey, strings.Repeat("─", width)))
+			io.WriteString(w, "\n")
+			i++
+		case listMarker(line) != "":
+			i = renderList(w, lines, i, width)
+		default:
+			i = renderParagraph(w, lines, i, width)
+		}
+	}
+}
+
+func renderParagraph(w io.Writer, lines []string, i, width int) int {
+	var text []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || (len(text) > 0 && startsBlock(line)) {
+			break
+		}
+		text = append(text, strings.TrimSpace(line))
+	}
+	wrap(w, inline(strings.Join(text, " ")), width)
+	return i
+}
+
+func renderCode(w io.Writer, lines []string, i, width int) int {
+	var (
+		fence  = strings.TrimSpace(lines[i])
+		marker = fence[:3]
+		indent = len(lines[i]) - len(strings.TrimLeft(lines[i], " "))
+	)
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), marker) {
+			i++
+			break
+		}
+		// Remove indentation of the fence from the code lines.
+		for j := 0; j < indent && strings.HasPrefix(line, " "); j++ {
+			line = line[1:]
+		}
+		line = strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth))
+		for _, p := range breakLine([]byte(line), width) {
+			w.Write(p)
+			io.WriteString(w, "\n")
+		}
+	}
+	return i
+}
+
+func renderQuote(w io.Writer, lines []string, i, width int) int {
+	var inner []string
+	for ; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " ")
+		if !strings.HasPrefix(line, ">") {
+			break
+		}
+		line = strings.TrimPrefix(line[1:], " ")
+		inner = append(inner, line)
+	}
+	pw := ioutil.NewLinePrefixWriter(w, []byte(color.Sprint(color.Grey, "│ ")))
+	renderBlocks(pw, inner, width-2)
+	return i
+}
+
+func renderList(w io.Writer, lines []string, i, width int) int {
+	var (
+		indent = leadingSpaces(lines[i])
+		num    = 1
+	)
+	for i < len(lines) {
+		line := lines[i]
+		marker := listMarker(line)
+		if marker == "" || leadingSpaces(line) != indent {
+			break
+		}
+		// Content lines are the rest of the item's first line and following
+		// lines indented deeper than the item marker.
+		var (
+			offset  = indent + len(marker) + 1
+			content = []string{strings.TrimSpace(line[indent+len(marker):])}
+		)
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if isBlank(line) {
+				if i+1 < len(lines) && leadingSpaces(lines[i+1]) > indent {
+					content = append(content, "")
+					continue
+				}
+				break
+			}
+			if leadingSpaces(line) <= indent && startsBlock(line) {
+				break
+			}
+			content = append(content, dedent(line, offset))
+		}
+
+		bullet := "•"
+		if isOrdered(marker) {
+			bullet = strconv.Itoa(num) + "."
+			num++
+		}
+		var (
+			first = color.Sprint(color.Grey, bullet) + " "
+			rest  = strings.Repeat(" ", utf8.RuneCountInString(bullet)+1)
+			buf   bytes.Buffer
+		)
+		renderBlocks(&buf, content, width-len(rest))
+		for j, p := range color.SplitLines(buf.Bytes()) {
+			if j == 0 {
+				io.WriteString(w, first)
+			} else if len(p) > 0 {
+				io.WriteString(w, rest)
+			}
+			w.Write(p)
+			io.WriteString(w, "\n")
+		}
+		// Loose list items are separated by blank lines.
+		j := i
+		for j < len(lines) && isBlank(lines[j]) {
+			j++
+		}
+		if j == len(lines) || leadingSpaces(lines[j]) != indent {
+			break
+		}
+		if next := listMarker(lines[j]); next == "" || isOrdered(next) != isOrdered(marker) {
+			break
+		}
+		i = j
+	}
+	return i
+}
+
+// wrap writes text wrapped to width followed by a newline.
+func wrap(w io.Writer, text string, width int) {
+	var buf bytes.Buffer
+	lw := ioutil.NewLineWrapWriter(&buf, width)
+	lw.SetBreakWords(true)
+	lw.SetRuneCounter(visibleWidth)
+	io.WriteString(lw, text)
+	io.WriteString(lw, "\n")
+	lw.Flush()
+
+	// Styles must not leak to line prefixes and suffixes.
+	for _, line := range color.SplitLines(buf.Bytes()) {
+		w.Write(line)
+		io.WriteString(w, "\n")
+	}
+}
+
+// breakLine splits line into pieces of width visible characters.
+func breakLine(line []byte, width int) [][]byte {
+	if len(line) == 0 {
+		return [][]byte{nil}
+	}
+	var (
+		ret   [][]byte
+		start int
+		n     int
+	)
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			if j := bytes.IndexByte(line[i:], 'm'); j != -1 {
+				i += j + 1
+				continue
+			}
+		}
+		if n == width {
+			ret = append(ret, line[start:i])
+			start, n = i, 0
+		}
+		_, size := utf8.DecodeRune(line[i:])
+		i += size
+		n++
+	}
+	ret = append(ret, line[start:])
+	return color.SplitLines(bytes.Join(ret, []byte{'\n'}))
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isFence(line string) bool {
+	s := strings.TrimSpace(line)
+	return strings.HasPrefix(s, "```") || strings.HasPrefix(s, "~~~")
+}
+
+func isQuote(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+func isHeading(line string) bool {
+	s := strings.TrimLeft(strings.TrimSpace(line), "#")
+	n := len(strings.TrimSpace(line)) - len(s)
+	return n > 0 && n <= 6 && (s == "" || s[0] == ' ')
+}
+
+func isRule(line string) bool {
+	s := strings.ReplaceAll(strings.TrimSpace(line), " ", "")
+	if len(s) < 3 {
+		return false
+	}
+	for _, c := range []string{"-", "*", "_"} {
+		if strings.Trim(s, c) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// startsBlock reports whether line starts a block other than a paragraph.
+func startsBlock(line string) bool {
+	return isFence(line) || isQuote(line) || isHeading(line) ||
+		isRule(line) || listMarker(line) != ""
+}
+
+// listMarker returns list item marker which line starts with. It returns
+// empty string if line is not a list item.
+func listMarker(line string) string {
+	s := strings.TrimLeft(line, " ")
+	if len(s) < 2 {
+		return ""
+	}
+	switch s[0] {
+	case '-', '*', '+':
+		if s[1] == ' ' {
+			return s[:1]
+		}
+		return ""
+	}
+	var i int
+	for i < len(s) && i < 9 && '0' <= s[i] && s[i] <= '9' {
+		i++
+	}
+	if i > 0 && i+1 < len(s) && (s[i] == '.' || s[i] == ')') && s[i+1] == ' ' {
+		return s[:i+1]
+	}
+	return ""
+}
+
+func isOrdered(marker string) bool {
+	return '0' <= marker[0] && marker[0] <= '9'
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func dedent(line string, n int) string {
+	if m := leadingSpaces(line); m < n {
+		n = m
+	}
+	return line[n:]
+}
+
+func visibleWidth(p []byte) int {
+	return utf8.RuneCount(color.Filter(p))
+}
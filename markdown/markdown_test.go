@@ -0,0 +1,157 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/color"
+)
+
+func TestRender(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		src   string
+		width int
+		exp   string
+	}{
+		{
+			name:  "paragraph",
+			src:   "foo bar\nbaz qux quux",
+			width: 8,
+			exp:   "foo bar\nbaz qux\nquux\n",
+		},
+		{
+			name:  "paragraphs",
+			src:   "foo\n\n\n\nbar",
+			width: 80,
+			exp:   "foo\n\nbar\n",
+		},
+		{
+			name:  "code",
+			src:   "see:\n```go\nif x {\n\treturn  y\n}\n```\nok",
+			width: 80,
+			exp:   "see:\nif x {\n    return  y\n}\nok\n",
+		},
+		{
+			name:  "long code",
+			src:   "```\n0123456789 abc\n```",
+			width: 5,
+			exp:   "01234\n56789\n abc\n",
+		},
+		{
+			name:  "heading",
+			src:   "## Title\ntext",
+			width: 80,
+			exp:   "Title\ntext\n",
+		},
+		{
+			name:  "bullets",
+			src:   "- foo\n- bar baz\n  - x y",
+			width: 7,
+			exp:   "• foo\n• bar\n  baz\n  • x y\n",
+		},
+		{
+			name:  "ordered",
+			src:   "1. foo\n1. bar\n\n- baz",
+			width: 80,
+			exp:   "1. foo\n2. bar\n\n• baz\n",
+		},
+		{
+			name:  "quote",
+			src:   "> foo\n> bar\n\nbaz",
+			width: 80,
+			exp:   "│ foo bar\n\nbaz\n",
+		},
+		{
+			name:  "rule",
+			src:   "foo\n\n---\n\nbar",
+			width: 3,
+			exp:   "foo\n\n───\n\nbar\n",
+		},
+		{
+			name:  "link",
+			src:   "see [docs](https://x.io) and <https://y.io>",
+			width: 80,
+			exp:   "see docs (https://x.io) and <https://y.io>\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			Render(&buf, test.src, test.width)
+			if act := color.FilterString(buf.String()); act != test.exp {
+				t.Errorf("unexpected output:\n%s\nwant:\n%s", act, test.exp)
+			}
+		})
+	}
+}
+
+func TestRenderStylesDoNotLeak(t *testing.T) {
+	var buf bytes.Buffer
+	Render(&buf, "**a b c d**", 3)
+	var reset bytes.Buffer
+	color.Freset(&reset)
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\n")
+		if !strings.HasSuffix(line, reset.String()) {
+			t.Errorf("line %q is not reset", line)
+		}
+	}
+}
+
+func TestInline(t *testing.T) {
+	style := func(s string, a color.Attribute) string {
+		var buf bytes.Buffer
+		color.Fattr(&buf, a)
+		buf.WriteString(s)
+		color.FresetAttr(&buf, a)
+		return buf.String()
+	}
+	for _, test := range []struct {
+		name string
+		in   string
+		exp  string
+	}{
+		{
+			name: "plain",
+			in:   "2 * 3 * 4",
+			exp:  "2 * 3 * 4",
+		},
+		{
+			name: "bold",
+			in:   "**foo** __bar__",
+			exp:  style("foo", color.Bold) + " " + style("bar", color.Bold),
+		},
+		{
+			name: "emphasis",
+			in:   "*foo* _bar_",
+			exp:  style("foo", color.Underline) + " " + style("bar", color.Underline),
+		},
+		{
+			name: "snake case",
+			in:   "snake_case_name",
+			exp:  "snake_case_name",
+		},
+		{
+			name: "code",
+			in:   "`**x**`",
+			exp:  color.Sprint(color.Cyan, "**x**"),
+		},
+		{
+			name: "escape",
+			in:   `\*foo\*`,
+			exp:  "*foo*",
+		},
+		{
+			name: "link",
+			in:   "[a](b)",
+			exp:  style("a", color.Underline) + color.Sprint(color.Grey, " (b)"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if act := inline(test.in); act != test.exp {
+				t.Errorf("unexpected inline(%q):\n%q\nwant:\n%q", test.in, act, test.exp)
+			}
+		})
+	}
+}
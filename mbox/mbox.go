@@ -0,0 +1,146 @@
+package mbox
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gobwas/rw/git"
+	"github.com/gobwas/rw/vcs"
+)
+
+// Client is a vcs.Provider which reads patch series from the mbox file.
+// Patches are applied one by one in the repository to reconstruct base and
+// head revisions of each patch. Comments are collected during the review and
+// rendered as an inline reply email on close.
+type Client struct {
+	File     string
+	Project  string
+	CacheDir string
+	CloneURL string
+	Branch   string
+	Base     string
+	Output   string
+	From     string
+
+	once    sync.Once
+	git     *git.Repository
+	patches []*patch
+	user    *mail.Address
+	err     error
+}
+
+const cacheOrigin = "rw-origin"
+
+func (c *Client) Init(ctx context.Context) error {
+	c.once.Do(func() {
+		c.err = c.init(ctx)
+	})
+	return c.err
+}
+
+func (c *Client) init(ctx context.Context) error {
+	if c.File == "" {
+		return fmt.Errorf("mbox: file is not set")
+	}
+	f, err := os.Open(c.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ms, err := parseMbox(f)
+	if err != nil {
+		return err
+	}
+	if len(ms) == 0 {
+		return fmt.Errorf("mbox: no patches found in %s", c.File)
+	}
+
+	if uri := c.CloneURL; uri != "" {
+		var dir string
+		if cache := c.CacheDir; cache != "" && c.Project != "" {
+			dir = filepath.Join(cache, c.Project)
+		}
+		c.git, err = git.Cache(ctx, dir, cacheOrigin, uri, c.Branch)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Work with repo in process's current directory.
+		c.git = &git.Repository{}
+	}
+
+	if err := c.initUser(ctx); err != nil {
+		return err
+	}
+
+	base := c.Base
+	if base == "" {
+		base = c.Branch
+	}
+	if base == "" {
+		base = "HEAD"
+	}
+	base, err = c.git.RevParse(ctx, base)
+	if err != nil {
+		return fmt.Errorf("mbox: can't resolve base revision: %v", err)
+	}
+	for _, m := range ms {
+		head, err := c.git.ApplyPatch(ctx, base, m.diff,
+			m.title+"\n\n"+m.body,
+			git.Signature{
+				Name:  m.author.Name,
+				Email: m.author.Address,
+				When:  m.date,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("mbox: can't apply patch %q: %v", m.subject, err)
+		}
+		c.patches = append(c.patches, &patch{
+			c:    c,
+			msg:  m,
+			base: base,
+			head: head,
+		})
+		base = head
+	}
+	return nil
+}
+
+func (c *Client) initUser(ctx context.Context) (err error) {
+	if c.From != "" {
+		c.user, err = mail.ParseAddress(c.From)
+		return err
+	}
+	name, _ := c.git.Config(ctx, "user.name")
+	email, _ := c.git.Config(ctx, "user.email")
+	if email == "" {
+		return fmt.Errorf("mbox: can't detect sender address; set user.email or from flag")
+	}
+	c.user = &mail.Address{
+		Name:    name,
+		Address: email,
+	}
+	return nil
+}
+
+func (c *Client) List(ctx context.Context, fn func(vcs.ReviewItem)) error {
+	for _, p := range c.patches {
+		fn(p)
+	}
+	return nil
+}
+
+func (c *Client) Select(ctx context.Context, x vcs.ReviewItem) (vcs.Review, error) {
+	switch v := x.(type) {
+	case *patch:
+		return v, nil
+
+	default:
+		return nil, fmt.Errorf("mbox: select: unsupported type: %T", v)
+	}
+}
@@ -0,0 +1,233 @@
+package mbox
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobwas/rw/internal/gittest"
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSubject(t *testing.T) {
+	for _, test := range []struct {
+		subject string
+		title   string
+		num     int
+		total   int
+	}{
+		{
+			subject: "[PATCH] fix foo",
+			title:   "fix foo",
+		},
+		{
+			subject: "[PATCH v2 2/3] fix foo",
+			title:   "fix foo",
+			num:     2,
+			total:   3,
+		},
+		{
+			subject: "[RFC][PATCH 01/10] net: fix [bar]",
+			title:   "net: fix [bar]",
+			num:     1,
+			total:   10,
+		},
+	} {
+		t.Run(test.subject, func(t *testing.T) {
+			title, num, total := parseSubject(test.subject)
+			if title != test.title || num != test.num || total != test.total {
+				t.Fatalf(
+					"unexpected result: %q %d/%d; want %q %d/%d",
+					title, num, total, test.title, test.num, test.total,
+				)
+			}
+		})
+	}
+}
+
+const testPatch = `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,4 +1,4 @@
+ one
+-two
++TWO
+ three
+ four
+@@ -10,2 +10,3 @@ ten
+ ten
+ eleven
++twelve
+`
+
+func TestWriteReply(t *testing.T) {
+	m := &message{
+		id:      "<1@example.com>",
+		subject: "[PATCH 1/2] upper two",
+		from:    "Alice <alice@example.com>",
+		cc:      "list@example.com",
+		author: &mail.Address{
+			Name:    "Alice",
+			Address: "alice@example.com",
+		},
+		date: time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC),
+		diff: []byte(testPatch),
+	}
+	root := &comment{id: "1", file: "a.txt", side: vcs.SideHead, lo: 2, hi: 2, body: "Why?"}
+	comments := map[string][]*comment{
+		"a.txt": {
+			root,
+			&comment{id: "2", file: "a.txt", side: vcs.SideHead, lo: 2, hi: 2, body: "Really.", parent: root},
+			&comment{id: "3", file: "a.txt", side: vcs.SideBase, lo: 1, hi: 1, body: "Nit."},
+			&comment{id: "4", file: "a.txt", side: vcs.SideHead, lo: 30, hi: 31, body: "Far away."},
+		},
+	}
+	var buf bytes.Buffer
+	err := writeReply(&buf,
+		&mail.Address{Name: "Bob", Address: "bob@example.com"},
+		time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC),
+		m, comments,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `From: "Bob" <bob@example.com>
+To: Alice <alice@example.com>
+Cc: list@example.com
+Subject: Re: [PATCH 1/2] upper two
+Date: Tue, 02 Nov 2021 10:00:00 +0000
+In-Reply-To: <1@example.com>
+References: <1@example.com>
+MIME-Version: 1.0
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: 8bit
+
+On Mon, Nov 1, 2021 at 10:00, Alice wrote:
+
+> diff --git a/a.txt b/a.txt
+> index 1111111..2222222 100644
+> --- a/a.txt
+> +++ b/a.txt
+> @@ -1,4 +1,4 @@
+>  one
+
+Nit.
+
+> -two
+> +TWO
+
+Why?
+
+Really.
+
+
+> a.txt:30-31 (head)
+
+Far away.
+
+`
+	if act := buf.String(); act != exp {
+		t.Fatalf("unexpected reply:\n%s", cmp.Diff(exp, act))
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "rw-mbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	upstream := filepath.Join(dir, "upstream")
+	if err := os.Mkdir(upstream, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gittest.Run(t, upstream, "init", "-q", "-b", "main")
+	gittest.WriteFile(t, upstream, "a.txt", "foo\nbar\n")
+	gittest.Run(t, upstream, "add", "-A")
+	gittest.Run(t, upstream, "commit", "-q", "-m", "initial")
+	gittest.Run(t, upstream, "checkout", "-q", "-b", "feature")
+	gittest.WriteFile(t, upstream, "a.txt", "foo\nbaz\n")
+	gittest.Run(t, upstream, "commit", "-q", "-am", "change bar")
+	gittest.WriteFile(t, upstream, "b.txt", "new\n")
+	gittest.Run(t, upstream, "add", "-A")
+	gittest.Run(t, upstream, "commit", "-q", "-m", "add b")
+	series := gittest.Run(t, upstream, "format-patch", "--stdout", "--cover-letter", "main")
+	gittest.Run(t, upstream, "checkout", "-q", "main")
+
+	file := filepath.Join(dir, "series.mbox")
+	gittest.WriteFile(t, dir, "series.mbox", series+"\n")
+
+	c := &Client{
+		File:     file,
+		CloneURL: upstream,
+		Output:   filepath.Join(dir, "reply.eml"),
+		From:     "Bob <bob@example.com>",
+	}
+	if err := c.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(c.git.Dir)
+
+	var items []string
+	err = c.List(ctx, func(x vcs.ReviewItem) {
+		items = append(items, x.String())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{
+		"1/2: test: change bar",
+		"2/2: test: add b",
+	}
+	if !cmp.Equal(items, exp) {
+		t.Fatalf("unexpected items:\n%s", cmp.Diff(exp, items))
+	}
+
+	r, err := c.Select(ctx, c.patches[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := r.ChangedFiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []vcs.ChangedFile{{Name: "b.txt", Status: vcs.FileStatusAdded, Added: 1, HeadSize: 4}}; !cmp.Equal(files, exp) {
+		t.Fatalf("unexpected changed files: %v", files)
+	}
+	base, err := r.BaseFile(ctx, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bts, err := ioutil.ReadAll(base)
+	base.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := string(bts), "foo\nbaz\n"; act != exp {
+		t.Fatalf("unexpected base file contents: %q; want %q", act, exp)
+	}
+
+	if _, err := r.Comment(ctx, "b.txt", vcs.SideHead, 1, 1, "Why?"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := ioutil.ReadFile(c.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(reply, []byte("> +new\n\nWhy?\n")) {
+		t.Fatalf("unexpected reply:\n%s", reply)
+	}
+}
@@ -0,0 +1,187 @@
+package mbox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// message represents single patch message from the mbox file.
+type message struct {
+	id         string
+	references string
+	subject    string
+	title      string
+	num        int
+	total      int
+	from       string
+	to         string
+	cc         string
+	author     *mail.Address
+	date       time.Time
+	body       string
+	diff       []byte
+}
+
+var (
+	subjectPrefix = regexp.MustCompile(`^\s*(?:\[[^\]]*\]\s*)+`)
+	patchNumber   = regexp.MustCompile(`(\d+)/(\d+)`)
+)
+
+// parseMbox parses patch messages from the mbox formatted stream. Messages
+// which don't contain a diff (such as cover letters) are skipped. Returned
+// messages are ordered by their number in the series.
+func parseMbox(r io.Reader) ([]*message, error) {
+	raw, err := splitMbox(r)
+	if err != nil {
+		return nil, err
+	}
+	var ms []*message
+	for i, bts := range raw {
+		m, err := parseMessage(bts)
+		if err != nil {
+			return nil, fmt.Errorf("mbox: message #%d: %v", i, err)
+		}
+		if m.diff == nil {
+			continue
+		}
+		ms = append(ms, m)
+	}
+	sort.SliceStable(ms, func(i, j int) bool {
+		return ms[i].num < ms[j].num
+	})
+	return ms, nil
+}
+
+// splitMbox splits mbox stream into raw messages. It unescapes mboxrd quoted
+// "From " lines.
+func splitMbox(r io.Reader) (ms [][]byte, err error) {
+	var (
+		s     = bufio.NewScanner(r)
+		buf   bytes.Buffer
+		blank = true
+	)
+	s.Buffer(nil, 1<<20)
+	flush := func() {
+		if buf.Len() > 0 {
+			ms = append(ms, append([]byte(nil), buf.Bytes()...))
+		}
+		buf.Reset()
+	}
+	for s.Scan() {
+		line := s.Bytes()
+		if blank && bytes.HasPrefix(line, []byte("From ")) {
+			flush()
+			blank = false
+			continue
+		}
+		blank = len(line) == 0
+		if !blank && line[0] == '>' && bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+			line = line[1:]
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return ms, nil
+}
+
+func parseMessage(bts []byte) (*message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(bts))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		h   = msg.Header
+		dec mime.WordDecoder
+	)
+	subject, err := dec.DecodeHeader(h.Get("Subject"))
+	if err != nil {
+		return nil, err
+	}
+	m := &message{
+		id:         h.Get("Message-Id"),
+		references: h.Get("References"),
+		subject:    subject,
+		from:       h.Get("From"),
+		to:         h.Get("To"),
+		cc:         h.Get("Cc"),
+	}
+	m.title, m.num, m.total = parseSubject(subject)
+	if m.author, err = mail.ParseAddress(m.from); err != nil {
+		return nil, fmt.Errorf("bad From header: %v", err)
+	}
+	if m.date, err = h.Date(); err != nil {
+		return nil, fmt.Errorf("bad Date header: %v", err)
+	}
+
+	body := msg.Body
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	text, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	m.body, m.diff = splitPatch(string(text))
+
+	return m, nil
+}
+
+// parseSubject strips the "[PATCH v2 1/3]"-like prefix from the subject and
+// returns number of the patch within the series.
+func parseSubject(s string) (title string, num, total int) {
+	prefix := subjectPrefix.FindString(s)
+	title = strings.TrimSpace(s[len(prefix):])
+	if m := patchNumber.FindStringSubmatch(prefix); m != nil {
+		num, _ = strconv.Atoi(m[1])
+		total, _ = strconv.Atoi(m[2])
+	}
+	return title, num, total
+}
+
+// splitPatch splits text of the patch message into commit message and diff.
+// Diffstat and signature are stripped.
+func splitPatch(text string) (body string, diff []byte) {
+	var (
+		b   strings.Builder
+		d   bytes.Buffer
+		cut bool
+	)
+	for _, line := range strings.SplitAfter(text, "\n") {
+		switch {
+		case d.Len() > 0 || strings.HasPrefix(line, "diff --git "):
+			if line == "-- \n" {
+				// Signature separator; the rest is not a part of the diff.
+				return strings.TrimSpace(b.String()), d.Bytes()
+			}
+			d.WriteString(line)
+		case cut:
+		case strings.TrimRight(line, "\n") == "---":
+			cut = true
+		default:
+			b.WriteString(line)
+		}
+	}
+	if d.Len() == 0 {
+		return strings.TrimSpace(b.String()), nil
+	}
+	return strings.TrimSpace(b.String()), d.Bytes()
+}
@@ -0,0 +1,198 @@
+package mbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gobwas/rw/ed"
+	"github.com/gobwas/rw/internal/gitvcs"
+	"github.com/gobwas/rw/vcs"
+)
+
+type patch struct {
+	c    *Client
+	msg  *message
+	base string
+	head string
+
+	mu       sync.Mutex
+	seq      int
+	comments map[string][]*comment
+}
+
+func (p *patch) String() string {
+	m := p.msg
+	if m.num == 0 {
+		return fmt.Sprintf("%s: %s", m.author.Name, m.title)
+	}
+	return fmt.Sprintf("%d/%d: %s: %s", m.num, m.total, m.author.Name, m.title)
+}
+
+// Close writes collected comments as an inline reply email.
+func (p *patch) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.comments) == 0 {
+		return nil
+	}
+	name := p.c.Output
+	if name == "" {
+		name = fmt.Sprintf("%04d-reply.eml", p.msg.num)
+	}
+	var buf bytes.Buffer
+	err := writeReply(&buf, p.c.user, time.Now(), p.msg, p.comments)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Reply saved to %s\n", name)
+	return nil
+}
+
+func (p *patch) ChangedFiles(ctx context.Context) ([]vcs.ChangedFile, error) {
+	return gitvcs.ChangedFiles(p.c.git.ChangedFiles(ctx, p.base, p.head))
+}
+
+func (p *patch) FileComments(ctx context.Context, file string) ([]vcs.Comment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cs := p.comments[file]
+	ret := make([]vcs.Comment, len(cs))
+	for i, c := range cs {
+		ret[i] = c
+	}
+	return ret, nil
+}
+
+func (p *patch) BaseName() string {
+	return p.base
+}
+func (p *patch) HeadName() string {
+	return p.head
+}
+
+func (p *patch) BaseFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return p.c.git.ShowFile(ctx, p.base, file)
+}
+func (p *patch) HeadFile(ctx context.Context, file string) (io.ReadCloser, error) {
+	return p.c.git.ShowFile(ctx, p.head, file)
+}
+
+func (p *patch) Checkout(ctx context.Context) (dir string, cleanup func() error, err error) {
+	return p.c.git.Checkout(ctx, p.head)
+}
+
+func (p *patch) Edit(ctx context.Context, file string, cmd ed.Command) error {
+	log.Printf("editing file %s", file)
+	var body string
+	switch cmd.Mode {
+	case ed.ModeAdd:
+		body = string(bytes.TrimSpace(cmd.Text))
+		if body == "" {
+			log.Println("empty comment; skipping")
+			return nil
+		}
+	case ed.ModeChange:
+		body = fmt.Sprintf("Suggested change:\n\n%s", cmd.Text)
+	case ed.ModeDelete:
+		body = "Suggest to remove these lines."
+	default:
+		return nil
+	}
+	_, err := p.Comment(ctx, file, vcs.SideHead, cmd.Start, cmd.End, body)
+	return err
+}
+
+func (p *patch) Comment(
+	ctx context.Context,
+	file string,
+	side vcs.Side,
+	lo, hi int,
+	body string,
+) (vcs.Comment, error) {
+	return p.add(&comment{
+		file: file,
+		side: side,
+		lo:   lo,
+		hi:   hi,
+		body: body,
+	}), nil
+}
+
+func (p *patch) ReplyTo(ctx context.Context, parent vcs.Comment, body string) (vcs.Comment, error) {
+	x := parent.(*comment)
+	if x.parent != nil {
+		x = x.parent
+	}
+	return p.add(&comment{
+		file:   x.file,
+		side:   x.side,
+		lo:     x.lo,
+		hi:     x.hi,
+		body:   body,
+		parent: x,
+	}), nil
+}
+
+func (p *patch) add(c *comment) *comment {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.comments == nil {
+		p.comments = make(map[string][]*comment)
+	}
+	p.seq++
+	c.id = strconv.Itoa(p.seq)
+	c.user = p.c.user.Address
+	c.created = time.Now()
+	p.comments[c.file] = append(p.comments[c.file], c)
+	return c
+}
+
+type comment struct {
+	id      string
+	file    string
+	side    vcs.Side
+	lo      int
+	hi      int
+	body    string
+	user    string
+	created time.Time
+	parent  *comment
+}
+
+func (c *comment) Lines() (lo, hi int) {
+	return c.lo, c.hi
+}
+func (c *comment) Body() string {
+	return c.body
+}
+func (c *comment) Side() vcs.Side {
+	return c.side
+}
+func (c *comment) CreatedAt() time.Time {
+	return c.created
+}
+func (c *comment) UpdatedAt() time.Time {
+	return c.created
+}
+func (c *comment) UserLogin() string {
+	return c.user
+}
+func (c *comment) Parent() vcs.Comment {
+	if c.parent != nil {
+		return c.parent
+	}
+	return nil
+}
+func (c *comment) ID() string {
+	return c.id
+}
@@ -0,0 +1,251 @@
+package mbox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+// writeReply writes an RFC 2822 inline reply to the patch message. Commented
+// hunks of the patch are quoted with "> " prefix and comments are placed right
+// after the lines they refer to.
+func writeReply(
+	dst io.Writer,
+	from *mail.Address,
+	now time.Time,
+	m *message,
+	comments map[string][]*comment,
+) error {
+	w := bufio.NewWriter(dst)
+
+	subject := m.subject
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+	header := func(k, v string) {
+		if v != "" {
+			fmt.Fprintf(w, "%s: %s\n", k, v)
+		}
+	}
+	header("From", from.String())
+	header("To", m.from)
+	header("Cc", joinAddresses(m.to, m.cc))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("In-Reply-To", m.id)
+	header("References", strings.TrimSpace(m.references+" "+m.id))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	fmt.Fprintf(w, "\nOn %s, %s wrote:\n",
+		m.date.Format("Mon, Jan 2, 2006 at 15:04"),
+		m.author.Name,
+	)
+
+	var (
+		files = parseDiff(m.diff)
+		seen  = make(map[string]bool, len(files))
+	)
+	for _, f := range files {
+		seen[f.path] = true
+		writeFileReply(w, f, threads(comments[f.path]))
+	}
+	var rest []string
+	for file := range comments {
+		if !seen[file] {
+			rest = append(rest, file)
+		}
+	}
+	sort.Strings(rest)
+	for _, file := range rest {
+		writeFileReply(w, &fileDiff{path: file}, threads(comments[file]))
+	}
+
+	return w.Flush()
+}
+
+func writeFileReply(w io.Writer, f *fileDiff, ts []vcs.Thread) {
+	if len(ts) == 0 {
+		return
+	}
+	anchors := make(map[*diffLine][]vcs.Thread)
+	var orphans []vcs.Thread
+	for _, t := range ts {
+		if x := f.find(t.Side(), hiLine(t)); x != nil {
+			anchors[x] = append(anchors[x], t)
+		} else {
+			orphans = append(orphans, t)
+		}
+	}
+	fmt.Fprintln(w)
+	for _, line := range f.header {
+		fmt.Fprintf(w, "> %s\n", line)
+	}
+	for _, h := range f.hunks {
+		last := -1
+		for i, x := range h.lines {
+			if anchors[x] != nil {
+				last = i
+			}
+		}
+		if last == -1 {
+			continue
+		}
+		fmt.Fprintf(w, "> %s\n", h.header)
+		for _, x := range h.lines[:last+1] {
+			fmt.Fprintf(w, "> %s\n", x.text)
+			for _, t := range anchors[x] {
+				writeThread(w, t)
+			}
+		}
+	}
+	for _, t := range orphans {
+		lo, hi := t.Lines()
+		lines := strconv.Itoa(hi)
+		if lo != hi {
+			lines = fmt.Sprintf("%d-%d", lo, hi)
+		}
+		fmt.Fprintf(w, "\n> %s:%s (%s)\n", f.path, lines, t.Side())
+		writeThread(w, t)
+	}
+}
+
+func writeThread(w io.Writer, t vcs.Thread) {
+	for _, c := range t {
+		fmt.Fprintf(w, "\n%s\n", strings.TrimRight(c.Body(), "\n"))
+	}
+	fmt.Fprintln(w)
+}
+
+func threads(cs []*comment) []vcs.Thread {
+	xs := make([]vcs.Comment, len(cs))
+	for i, c := range cs {
+		xs[i] = c
+	}
+	ts := vcs.BuildThreads(xs)
+	sort.SliceStable(ts, func(i, j int) bool {
+		return hiLine(ts[i]) < hiLine(ts[j])
+	})
+	return ts
+}
+
+func hiLine(t vcs.Thread) int {
+	_, hi := t.Lines()
+	return hi
+}
+
+func joinAddresses(xs ...string) string {
+	var ret []string
+	for _, x := range xs {
+		if x = strings.TrimSpace(x); x != "" {
+			ret = append(ret, x)
+		}
+	}
+	return strings.Join(ret, ", ")
+}
+
+type fileDiff struct {
+	path   string
+	header []string
+	hunks  []*hunk
+}
+
+// find returns diff line which has given line number on the given side.
+func (f *fileDiff) find(side vcs.Side, n int) *diffLine {
+	for _, h := range f.hunks {
+		for _, x := range h.lines {
+			if (side == vcs.SideBase && x.old == n) || (side == vcs.SideHead && x.new == n) {
+				return x
+			}
+		}
+	}
+	return nil
+}
+
+type hunk struct {
+	header string
+	lines  []*diffLine
+}
+
+// diffLine represents a line of the hunk. Zero old or new line number means
+// that line is not present on that side.
+type diffLine struct {
+	text string
+	old  int
+	new  int
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// parseDiff parses git formatted diff into per-file hunks.
+func parseDiff(diff []byte) (fs []*fileDiff) {
+	var (
+		f        *fileDiff
+		h        *hunk
+		old, new int
+	)
+	for _, line := range strings.Split(strings.TrimRight(string(diff), "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			f = &fileDiff{
+				header: []string{line},
+			}
+			if i := strings.Index(line, " b/"); i != -1 {
+				f.path = line[i+3:]
+			}
+			h = nil
+			fs = append(fs, f)
+
+		case f == nil:
+			// Skip garbage before the first file.
+
+		case h == nil && !strings.HasPrefix(line, "@@"):
+			if strings.HasPrefix(line, "+++ b/") {
+				f.path = strings.TrimPrefix(line, "+++ b/")
+			}
+			f.header = append(f.header, line)
+
+		case strings.HasPrefix(line, "@@"):
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			old, _ = strconv.Atoi(m[1])
+			new, _ = strconv.Atoi(m[2])
+			h = &hunk{
+				header: line,
+			}
+			f.hunks = append(f.hunks, h)
+
+		default:
+			x := &diffLine{
+				text: line,
+			}
+			switch {
+			case strings.HasPrefix(line, "-"):
+				x.old = old
+				old++
+			case strings.HasPrefix(line, "+"):
+				x.new = new
+				new++
+			case strings.HasPrefix(line, "\\"):
+			default:
+				x.old = old
+				x.new = new
+				old++
+				new++
+			}
+			h.lines = append(h.lines, x)
+		}
+	}
+	return fs
+}
@@ -0,0 +1,28 @@
+package mbox
+
+import (
+	"flag"
+)
+
+func DefineFlags(c *Client, fs *flag.FlagSet) {
+	fs.StringVar(&c.File,
+		"file", "",
+		"path to the mbox file with patch series",
+	)
+	fs.StringVar(&c.CloneURL,
+		"clone-url", "",
+		"url to clone project from (repo in current directory is used by default)",
+	)
+	fs.StringVar(&c.Base,
+		"base", "",
+		"revision to apply patch series on (branch or HEAD by default)",
+	)
+	fs.StringVar(&c.Output,
+		"output", "",
+		"path to write reply email to (NNNN-reply.eml by default)",
+	)
+	fs.StringVar(&c.From,
+		"from", "",
+		"reply sender address (git user.name and user.email by default)",
+	)
+}
@@ -0,0 +1,84 @@
+package rw
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/rw/vcs"
+)
+
+var (
+	DefaultPager     = "less"
+	DefaultPagerArgs = Args{"-R", "-X"}
+)
+
+// pager returns pager command and its arguments. Explicitly configured pager
+// has precedence over the $PAGER environment variable.
+func (r *Review) pager() (string, []string) {
+	if r.Pager != "" {
+		return r.Pager, r.PagerArgs
+	}
+	if fs := strings.Fields(os.Getenv("PAGER")); len(fs) > 0 {
+		return fs[0], fs[1:]
+	}
+	return DefaultPager, DefaultPagerArgs
+}
+
+// page shows p through the pager.
+func (r *Review) page(ctx context.Context, p []byte) error {
+	name, args := r.pager()
+	log.Println("executing", name, argsString(args))
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(p)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if filepath.Base(name) == "less" && os.Getenv("LESS") == "" {
+		// Make less to preserve colors even if it is set via $PAGER without
+		// any options.
+		cmd.Env = append(os.Environ(), "LESS=R")
+	}
+	return cmd.Run()
+}
+
+// output writes p to stdout. If p doesn't fit the terminal height without
+// reserve lines printed after it, it is shown through the pager.
+func (r *Review) output(ctx context.Context, p []byte, reserve int) error {
+	if bytes.Count(p, []byte{'\n'}) <= TermInfo.Height-reserve {
+		_, err := os.Stdout.Write(p)
+		return err
+	}
+	return r.page(ctx, p)
+}
+
+// viewFile pages head version of the file annotated with all its comments.
+func (r *Review) viewFile(ctx context.Context, review vcs.Review, f vcs.ChangedFile) error {
+	src, err := headFile(ctx, review, f)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	comments, err := review.FileComments(ctx, f.Name)
+	if err != nil {
+		return err
+	}
+	tmp, _, err := annotate(src, comments)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+	bts, err := ioutil.ReadAll(tmp)
+	if err != nil {
+		return err
+	}
+	return r.page(ctx, bts)
+}
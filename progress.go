@@ -0,0 +1,343 @@
+package rw
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gobwas/prompt"
+	"github.com/gobwas/rw/vcs"
+)
+
+// progress tracks files and hunks reviewed at a particular head revision. It
+// is stored as JSON within the cache directory. Methods of nil progress are
+// no-ops.
+type progress struct {
+	path   string
+	rev    string
+	viewer vcs.FileViewer
+
+	// since is the previous head revision when reviewing an interdiff. Hunks
+	// of an interdiff are tracked separately since their base lines differ.
+	since string
+
+	Files map[string]*fileProgress `json:"files"`
+}
+
+// fileProgress holds review progress of a single file.
+type fileProgress struct {
+	// Reviewed is true when all changes of the file were reviewed.
+	Reviewed bool `json:"reviewed,omitempty"`
+
+	// Viewed is true when the file is known to be marked as viewed on the vcs
+	// side. It tells apart files unmarked there from the ones never synced.
+	Viewed bool `json:"viewed,omitempty"`
+
+	// Hunks holds reviewed edit buffers identified by their start lines.
+	Hunks map[string]bool `json:"hunks,omitempty"`
+}
+
+// loadProgress returns review progress of the head revision of the review.
+// It returns nil if tracking is disabled or the head revision is unknown.
+func (r *Review) loadProgress(ctx context.Context, review vcs.Review) (*progress, error) {
+	if !r.Progress || r.CacheDir == "" {
+		return nil, nil
+	}
+	rev, err := headRevision(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	if rev == "" {
+		log.Printf("unknown head revision of %s; not tracking progress", review)
+		return nil, nil
+	}
+	p := &progress{
+		path:  filepath.Join(r.CacheDir, "progress", hash(review.String()), rev+".json"),
+		rev:   rev,
+		Files: make(map[string]*fileProgress),
+	}
+	bts, err := ioutil.ReadFile(p.path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(bts, p); err != nil {
+			return nil, fmt.Errorf("malformed progress file %s: %v", p.path, err)
+		}
+	}
+	if fv, ok := review.(vcs.FileViewer); ok {
+		p.viewer = fv
+		if err := p.sync(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// headRevision returns commit hash of the head version of the review or
+// empty string if it's unknown.
+func headRevision(ctx context.Context, review vcs.Review) (string, error) {
+	if hr, ok := review.(vcs.HeadRevision); ok {
+		return hr.HeadRevision(ctx)
+	}
+	if s := review.HeadName(); len(s) >= 40 && isHash(s) {
+		return s, nil
+	}
+	return "", nil
+}
+
+// sync merges files marked as viewed on the vcs side with the files reviewed
+// locally. Failures are not fatal since the local progress is still usable.
+func (p *progress) sync(ctx context.Context) error {
+	viewed, err := p.viewer.ViewedFiles(ctx)
+	if err != nil {
+		log.Printf("can't fetch viewed files: %v", err)
+		return nil
+	}
+	index := make(map[string]bool, len(viewed))
+	for _, file := range viewed {
+		index[file] = true
+		f := p.file(file)
+		f.Reviewed = true
+		f.Viewed = true
+	}
+	for file, f := range p.Files {
+		switch {
+		case index[file]:
+		case f.Viewed:
+			// File was unmarked on the vcs side since the last sync.
+			*f = fileProgress{}
+		case f.Reviewed:
+			p.setViewed(ctx, file, f, true)
+		}
+	}
+	return p.save()
+}
+
+func (p *progress) file(name string) *fileProgress {
+	f := p.Files[name]
+	if f == nil {
+		f = new(fileProgress)
+		p.Files[name] = f
+	}
+	return f
+}
+
+func (p *progress) setViewed(ctx context.Context, name string, f *fileProgress, viewed bool) {
+	if err := p.viewer.SetViewed(ctx, name, viewed); err != nil {
+		log.Printf("can't mark %s as viewed=%t: %v", name, viewed, err)
+		return
+	}
+	f.Viewed = viewed
+}
+
+// FileReviewed reports whether all changes of the file were reviewed.
+func (p *progress) FileReviewed(name string) bool {
+	if p == nil {
+		return false
+	}
+	f := p.Files[name]
+	return f != nil && f.Reviewed
+}
+
+// HunkReviewed reports whether all edit buffers of the hunk were reviewed.
+func (p *progress) HunkReviewed(name string, h *hunk) bool {
+	if p == nil {
+		return false
+	}
+	f := p.Files[name]
+	if f == nil {
+		return false
+	}
+	if f.Reviewed {
+		return true
+	}
+	reviewed := true
+	h.buffers(func(e *editBuffer) {
+		reviewed = reviewed && f.Hunks[p.bufferKey(e)]
+	})
+	return reviewed
+}
+
+// MarkHunk marks edit buffers of the hunk as reviewed.
+func (p *progress) MarkHunk(name string, h *hunk) error {
+	if p == nil {
+		return nil
+	}
+	f := p.file(name)
+	if f.Hunks == nil {
+		f.Hunks = make(map[string]bool)
+	}
+	h.buffers(func(e *editBuffer) {
+		f.Hunks[p.bufferKey(e)] = true
+	})
+	return p.save()
+}
+
+// MarkFile marks the file as reviewed or not. Unmarked file loses its
+// reviewed hunks as well.
+func (p *progress) MarkFile(ctx context.Context, name string, reviewed bool) error {
+	if p == nil {
+		return nil
+	}
+	f := p.file(name)
+	f.Reviewed = reviewed
+	if !reviewed {
+		f.Hunks = nil
+	}
+	if p.viewer != nil && f.Viewed != reviewed {
+		p.setViewed(ctx, name, f, reviewed)
+	}
+	return p.save()
+}
+
+// SkipHunks returns the first buffer starting from b which starts a hunk not
+// reviewed yet.
+func (p *progress) SkipHunks(name string, q *quick, b *editBuffer, contextBefore, contextAfter int) *editBuffer {
+	for b != nil {
+		h := newHunk(q, b, contextBefore, contextAfter)
+		if !p.HunkReviewed(name, h) {
+			return b
+		}
+		b = q.Next(h.hi)
+	}
+	return nil
+}
+
+func (p *progress) save() error {
+	bts, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file first to not leave a broken file behind.
+	tmp := p.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, bts, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
+
+// bufferKey returns identifier of the edit buffer which is stable across
+// runs for the same revisions.
+func (p *progress) bufferKey(e *editBuffer) string {
+	key := fmt.Sprintf("%d,%d", e.baseStart, e.headStart)
+	if p.since != "" {
+		key = p.since + ":" + key
+	}
+	return key
+}
+
+// lastReviewed returns the most recently reviewed head revision other than
+// the current one. It returns empty string if there is no such revision.
+func (p *progress) lastReviewed() (string, error) {
+	infos, err := ioutil.ReadDir(filepath.Dir(p.path))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	var (
+		last  string
+		mtime time.Time
+	)
+	for _, info := range infos {
+		rev := strings.TrimSuffix(info.Name(), ".json")
+		if rev == p.rev || len(rev) < 40 || !isHash(rev) || !info.ModTime().After(mtime) {
+			continue
+		}
+		bts, err := ioutil.ReadFile(filepath.Join(filepath.Dir(p.path), info.Name()))
+		if err != nil {
+			return "", err
+		}
+		var x progress
+		if err := json.Unmarshal(bts, &x); err != nil {
+			log.Printf("malformed progress file %s: %v", info.Name(), err)
+			continue
+		}
+		if x.reviewed() {
+			last, mtime = rev, info.ModTime()
+		}
+	}
+	return last, nil
+}
+
+// reviewed reports whether any file or hunk was reviewed.
+func (p *progress) reviewed() bool {
+	for _, f := range p.Files {
+		if f.Reviewed || len(f.Hunks) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pickFiles asks which of the changed files to review. Files reviewed before
+// are marked in the options and may be skipped at all.
+func (r *Review) pickFiles(ctx context.Context, review vcs.Review, p *progress) ([]vcs.ChangedFile, error) {
+	files, err := review.ChangedFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var reviewed int
+	for _, f := range files {
+		if p.FileReviewed(f.Name) {
+			reviewed++
+		}
+	}
+	if reviewed > 0 {
+		skip, err := prompt.Confirm(ctx, fmt.Sprintf(
+			"%d of %d file(s) are reviewed. Skip them?", reviewed, len(files),
+		))
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			rest := files[:0]
+			for _, f := range files {
+				if !p.FileReviewed(f.Name) {
+					rest = append(rest, f)
+				}
+			}
+			files = rest
+		}
+		if len(files) == 0 {
+			return nil, nil
+		}
+	}
+	opts := make([]string, len(files))
+	for i, f := range files {
+		opts[i] = fileLabel(f)
+		if p.FileReviewed(f.Name) {
+			opts[i] += " (reviewed)"
+		}
+	}
+	xs, err := r.selectMultiple(ctx, "Pick files to review:", opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]vcs.ChangedFile, len(xs))
+	for i, x := range xs {
+		ret[i] = files[x]
+	}
+	return ret, nil
+}
+
+// reviewedHunks returns number of reviewed hunks of the file among all of
+// them.
+func (r *Review) reviewedHunks(p *progress, file string, q *quick) (reviewed, total int) {
+	for _, b := range hunkStarts(q, r.contextBefore(), r.contextAfter()) {
+		total++
+		if p.HunkReviewed(file, newHunk(q, b, r.contextBefore(), r.contextAfter())) {
+			reviewed++
+		}
+	}
+	return reviewed, total
+}
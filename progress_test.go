@@ -0,0 +1,152 @@
+package rw
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+type viewerReview struct {
+	vcs.Review
+
+	viewed map[string]bool
+}
+
+func (r *viewerReview) String() string   { return "test" }
+func (r *viewerReview) HeadName() string { return strings.Repeat("a", 40) }
+
+func (r *viewerReview) ViewedFiles(context.Context) (ret []string, _ error) {
+	for file, viewed := range r.viewed {
+		if viewed {
+			ret = append(ret, file)
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+func (r *viewerReview) SetViewed(_ context.Context, file string, viewed bool) error {
+	r.viewed[file] = viewed
+	return nil
+}
+
+func TestProgressSync(t *testing.T) {
+	ctx := context.Background()
+	r := &Review{
+		Progress: true,
+		CacheDir: t.TempDir(),
+	}
+	review := &viewerReview{
+		viewed: map[string]bool{
+			"a": true,
+		},
+	}
+	p, err := r.loadProgress(ctx, review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.FileReviewed("a") {
+		t.Errorf("file viewed remotely is not reviewed")
+	}
+	if err := p.MarkFile(ctx, "b", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.MarkFile(ctx, "c", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unmark one file remotely and mark other ones while being offline.
+	review.viewed["a"] = false
+	review.viewed["c"] = false
+	p.Files["c"].Viewed = false
+	p.viewer = nil
+	if err := p.MarkFile(ctx, "d", true); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err = r.loadProgress(ctx, review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reviewed []string
+	for _, file := range []string{"a", "b", "c", "d"} {
+		if p.FileReviewed(file) {
+			reviewed = append(reviewed, file)
+		}
+	}
+	if exp := []string{"b", "c", "d"}; !cmp.Equal(reviewed, exp) {
+		t.Errorf("unexpected reviewed files:\n%s", cmp.Diff(exp, reviewed))
+	}
+	viewed, _ := review.ViewedFiles(ctx)
+	if exp := []string{"b", "c", "d"}; !cmp.Equal(viewed, exp) {
+		t.Errorf("unexpected viewed files:\n%s", cmp.Diff(exp, viewed))
+	}
+}
+
+func TestProgressDisabled(t *testing.T) {
+	r := &Review{
+		CacheDir: t.TempDir(),
+	}
+	p, err := r.loadProgress(context.Background(), &viewerReview{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != nil {
+		t.Fatalf("unexpected progress")
+	}
+	if err := p.MarkFile(context.Background(), "a", true); err != nil {
+		t.Fatal(err)
+	}
+	if p.FileReviewed("a") {
+		t.Errorf("nil progress reports reviewed file")
+	}
+}
+
+func TestProgressLastReviewed(t *testing.T) {
+	var (
+		ctx = context.Background()
+		dir = t.TempDir()
+		now = time.Now()
+	)
+	for i, rev := range []string{
+		strings.Repeat("1", 40),
+		strings.Repeat("2", 40),
+		strings.Repeat("3", 40),
+	} {
+		p := &progress{
+			path:  filepath.Join(dir, rev+".json"),
+			rev:   rev,
+			Files: make(map[string]*fileProgress),
+		}
+		if i < 2 {
+			// The last revision is opened but not reviewed.
+			if err := p.MarkFile(ctx, "a", true); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := p.save(); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(p.path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &progress{
+		path: filepath.Join(dir, strings.Repeat("4", 40)+".json"),
+		rev:  strings.Repeat("4", 40),
+	}
+	act, err := p.lastReviewed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := strings.Repeat("2", 40); act != exp {
+		t.Errorf("unexpected last reviewed revision: %s; want %s", act, exp)
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/gobwas/rw/ed"
 	"github.com/gobwas/rw/ioutil"
 	"github.com/gobwas/rw/listutil"
+	"github.com/gobwas/rw/markdown"
+	"github.com/gobwas/rw/syntax"
 	"github.com/gobwas/rw/timeutil"
 	"github.com/gobwas/rw/vcs"
 )
@@ -30,6 +32,13 @@ type quick struct {
 	commentIDs  map[string]uint
 	commentID   uint
 
+	// expandResolved is true if resolved threads are shown in full.
+	expandResolved bool
+
+	// orphans holds outdated threads which can't be relocated onto the
+	// current version of the file.
+	orphans []vcs.Thread
+
 	buffers list.List // List<*editBuffer>
 
 	baseEdits map[int]bool
@@ -45,6 +54,23 @@ type quick struct {
 	headLines int
 
 	headOffset int
+
+	// split is true if base and head are rendered side by side in columns
+	// of columnWidth characters.
+	split       bool
+	columnWidth int
+
+	// headText holds lines of the head file to render suggestions.
+	headText [][]byte
+
+	// theme is non-nil if syntax highlighting is enabled.
+	theme      *syntax.Theme
+	baseTokens [][]syntax.Span
+	headTokens [][]syntax.Span
+
+	// summary is non-nil if the file can't be reviewed as text. It describes
+	// changes instead of rendered edits.
+	summary []byte
 }
 
 type threadList struct {
@@ -157,8 +183,13 @@ func newQuick(base *os.File, comments []vcs.Comment) *quick {
 	var (
 		baseThreads avl.Tree
 		headThreads avl.Tree
+		orphans     []vcs.Thread
 	)
 	for _, t := range vcs.BuildThreads(comments) {
+		if start, _ := t.Lines(); start == 0 {
+			orphans = append(orphans, t)
+			continue
+		}
 		var ts *avl.Tree
 		switch t.Side() {
 		case vcs.SideBase:
@@ -236,6 +267,15 @@ func newQuick(base *os.File, comments []vcs.Comment) *quick {
 			})
 		}
 	}
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return compareThreadsByCreationTime(orphans[i], orphans[j]) < 0
+	})
+	for _, t := range orphans {
+		for _, c := range t {
+			commendIDs[c.ID()] = commentID
+			commentID++
+		}
+	}
 
 	return &quick{
 		base:        &ioutil.LineSeeker{Source: base},
@@ -243,6 +283,7 @@ func newQuick(base *os.File, comments []vcs.Comment) *quick {
 		headEdits:   make(map[int]bool),
 		baseThreads: baseThreads,
 		headThreads: headThreads,
+		orphans:     orphans,
 		commentIDs:  commendIDs,
 		commentID:   commentID,
 	}
@@ -306,6 +347,14 @@ func joinBuffers(prev, next *editBuffer) *editBuffer {
 	}
 }
 
+// Highlight enables syntax highlighting of the base and head files contents
+// using given lexer. It must be called before Render().
+func (q *quick) Highlight(lex syntax.Lexer, theme *syntax.Theme, base, head []byte) {
+	q.theme = theme
+	q.baseTokens = syntax.Lines(base, lex)
+	q.headTokens = syntax.Lines(head, lex)
+}
+
 func (q *quick) Render(edits []ed.Command) {
 	edits = append(([]ed.Command)(nil), edits...)
 	sort.Slice(edits, func(i, j int) bool {
@@ -345,19 +394,39 @@ func (q *quick) renderCommand(buf *bytes.Buffer, cmd ed.Command) {
 		// NOTE: lines are added always after cmd.Start.
 		// So need to flush one more line.
 		q.advanceHead(1)
-		q.insertLines(buf, cmd)
+		q.change(buf, nil, textLines(cmd.Text))
 
 	case ed.ModeChange:
-		for q.baseLine <= cmd.End {
-			q.deleteLine(buf)
+		// Read deleted lines first to pair them with the added ones and
+		// highlight changed words.
+		var base [][]byte
+		for n := q.baseLine; n <= cmd.End; n++ {
+			base = append(base, q.readBase())
 		}
-		q.insertLines(buf, cmd)
+		q.change(buf, base, textLines(cmd.Text))
 
 	case ed.ModeDelete:
 		// NOTE: lines deleted are in inclusive range [cmd.Start, cmd.End].
-		for q.baseLine <= cmd.End {
-			q.deleteLine(buf)
+		var base [][]byte
+		for n := q.baseLine; n <= cmd.End; n++ {
+			base = append(base, q.readBase())
 		}
+		q.change(buf, base, nil)
+	}
+}
+
+// change prints base lines as deleted and head lines as inserted.
+func (q *quick) change(w io.Writer, base, head [][]byte) {
+	baseSpans, headSpans := pairSpans(base, head)
+	if q.split {
+		q.splitChange(w, base, head, baseSpans, headSpans)
+		return
+	}
+	for i, line := range base {
+		q.deleteLine(w, line, baseSpans[i])
+	}
+	for i, line := range head {
+		q.insertLine(w, line, headSpans[i])
 	}
 }
 
@@ -369,15 +438,11 @@ func (q *quick) RenderBuffer(e *editBuffer) {
 }
 
 func (q *quick) AppendComment(c vcs.Comment) {
-	var ts *avl.Tree
-	switch c.Side() {
-	case vcs.SideBase:
-		ts = &q.baseThreads
-	case vcs.SideHead:
-		ts = &q.headThreads
-	default:
-		panic("unexpected vcs thread side")
+	if start, _ := c.Lines(); start == 0 {
+		q.appendOrphan(c)
+		return
 	}
+	ts := q.threads(c.Side())
 
 	// Search for a threadList having same start line.
 	start, _ := c.Lines()
@@ -421,6 +486,138 @@ func (q *quick) AppendComment(c vcs.Comment) {
 	q.commentID++
 }
 
+// UpdateComment replaces the comment having the same id as c.
+func (q *quick) UpdateComment(c vcs.Comment) {
+	q.updateThreads(c, func(t vcs.Thread) vcs.Thread {
+		ret := make(vcs.Thread, len(t))
+		for i, x := range t {
+			if x.ID() == c.ID() {
+				x = c
+			}
+			ret[i] = x
+		}
+		return ret
+	})
+}
+
+// DeleteComment removes c and replies to it from the threads.
+func (q *quick) DeleteComment(c vcs.Comment) {
+	q.updateThreads(c, func(t vcs.Thread) vcs.Thread {
+		var ret vcs.Thread
+		for _, x := range t {
+			if isReplyOf(x, c) {
+				delete(q.commentIDs, x.ID())
+				continue
+			}
+			ret = append(ret, x)
+		}
+		return ret
+	})
+}
+
+// isReplyOf reports whether x is c or a reply to c, direct or not.
+func isReplyOf(x, c vcs.Comment) bool {
+	for ; x != nil; x = x.Parent() {
+		if x.ID() == c.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+// updateThreads replaces each thread located at the same lines as c with the
+// result of fn. Threads for which fn returns empty thread are removed.
+func (q *quick) updateThreads(c vcs.Comment, fn func(vcs.Thread) vcs.Thread) {
+	start, _ := c.Lines()
+	if start == 0 {
+		ret := q.orphans[:0]
+		for _, t := range q.orphans {
+			if t = fn(t); len(t) > 0 {
+				ret = append(ret, t)
+			}
+		}
+		q.orphans = ret
+		return
+	}
+	var (
+		ts    = q.threads(c.Side())
+		query = &threadsQuery{
+			startLine: start,
+		}
+	)
+	list, _ := ts.Search(query).(*threadList)
+	if list == nil {
+		return
+	}
+	var rest []vcs.Thread
+	list.forEach(func(t vcs.Thread) {
+		if t = fn(t); len(t) > 0 {
+			rest = append(rest, t)
+		}
+	})
+	if len(rest) == 0 {
+		*ts, _ = ts.Delete(query)
+		return
+	}
+	list.list.Init()
+	for _, t := range rest {
+		list.list.PushBack(t)
+	}
+}
+
+func (q *quick) threads(side vcs.Side) *avl.Tree {
+	switch side {
+	case vcs.SideBase:
+		return &q.baseThreads
+	case vcs.SideHead:
+		return &q.headThreads
+	default:
+		panic("unexpected vcs thread side")
+	}
+}
+
+func (q *quick) appendOrphan(c vcs.Comment) {
+	parent := c.Parent()
+	if parent == nil {
+		q.orphans = append(q.orphans, vcs.Thread{c})
+	} else if i := orphanIndex(q.orphans, parent); i != -1 {
+		q.orphans[i] = append(q.orphans[i], c)
+	} else {
+		panic("no thread found for child comment")
+	}
+	log.Printf("assigned comment id for comment %q: %x", c.ID(), q.commentID)
+	q.commentIDs[c.ID()] = q.commentID
+	q.commentID++
+}
+
+// orphanIndex returns index of the thread containing comment c.
+func orphanIndex(ts []vcs.Thread, c vcs.Comment) int {
+	for i, t := range ts {
+		for _, x := range t {
+			if x.ID() == c.ID() {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Orphans returns outdated threads which can't be shown within the file.
+func (q *quick) Orphans() []vcs.Thread {
+	return q.orphans
+}
+
+// PrintOrphans writes a section listing orphaned threads, if any.
+func (q *quick) PrintOrphans(w io.Writer) {
+	if len(q.orphans) == 0 {
+		return
+	}
+	color.Fprintf(w, color.Yellow, "Orphaned threads:\n")
+	for _, t := range q.orphans {
+		q.writeThread(w, t, 2, 80)
+	}
+}
+
 func (q *quick) Front() *editBuffer {
 	return bufferFromElement(q.buffers.Front())
 }
@@ -482,6 +679,20 @@ func (q *quick) printThread(w io.Writer, t vcs.Thread) {
 	default:
 		panic("unexpected vcs thread side")
 	}
+	q.writeThread(w, t, prefixPad, 80)
+}
+
+// writeThread writes thread box indented by prefixPad spaces. Box is not
+// wider than limit characters including the indentation. Resolved threads are
+// collapsed to the title of the first comment unless q.expandResolved is set.
+func (q *quick) writeThread(w io.Writer, t vcs.Thread, prefixPad, limit int) {
+	var (
+		size     = len(t)
+		collapse = t.Resolved() && !q.expandResolved
+	)
+	if collapse {
+		t = t[:1]
+	}
 	var (
 		prefix = append(bytes.Repeat([]byte{' '}, prefixPad), "| "...)
 		suffix = []byte(" |")
@@ -493,25 +704,50 @@ func (q *quick) printThread(w io.Writer, t vcs.Thread) {
 			"\r", "\n",
 		)
 
+		// 2 is for borders.
+		// 2 is for padding to borders.
+		padding = prefixPad + 2 + 2
+
 		maxLine int
 	)
 	for i, c := range t {
-		body := strings.TrimSpace(c.Body())
-		body = replacer.Replace(body)
-		if n := ioutil.MaxLineRunesInString(color.FilterString(body)); n > maxLine {
-			maxLine = n
+		if !collapse {
+			body := strings.TrimSpace(c.Body())
+			body = replacer.Replace(body)
+			body = q.formatBody(c, body)
+
+			var buf strings.Builder
+			markdown.Render(&buf, body, limit-padding)
+			body = buf.String()
+			if n := ioutil.MaxLineRunesInString(color.FilterString(body)); n > maxLine {
+				maxLine = n
+			}
+			bodies[i] = body
 		}
-		bodies[i] = body + "\n"
 
 		elapsed := timeutil.FormatSince(c.CreatedAt())
 		if elapsed != "now" {
 			elapsed += " ago"
 		}
+		var mark string
+		if i == 0 && vcs.IsOutdated(c) {
+			mark = color.Sprintf(color.Yellow, " (outdated)")
+		}
+		end := ":"
+		switch {
+		case collapse:
+			mark += color.Sprintf(color.Grey, " (resolved, %d comment(s))", size)
+			end = ""
+		case i == 0 && t.Resolved():
+			mark += color.Sprintf(color.Grey, " (resolved)")
+		}
 		title := fmt.Sprintf(
-			"%s @%s, %s:\n",
+			"%s @%s, %s%s%s\n",
 			color.Sprintf(color.Grey, "[%s]", q.CommentID(c)),
 			c.UserLogin(),
 			elapsed,
+			mark,
+			end,
 		)
 		if n := ioutil.MaxLineRunesInString(color.FilterString(title)); n > maxLine {
 			maxLine = n
@@ -519,10 +755,7 @@ func (q *quick) printThread(w io.Writer, t vcs.Thread) {
 		titles[i] = title
 	}
 
-	// 2 is for borders.
-	// 2 is for padding to borders.
-	padding := prefixPad + 2 + 2
-	width := 80 - padding // Comment body width.
+	width := limit - padding // Comment body width.
 	if maxLine < width {
 		width = maxLine
 	}
@@ -577,32 +810,51 @@ func (q *quick) printHeadThreads(w io.Writer, line int) {
 }
 
 func (q *quick) printLine(w io.Writer, baseLine, headLine int, line []byte) {
+	if q.split {
+		q.printSplitLine(w, baseLine, headLine, line)
+		return
+	}
 	fmt.Fprintf(w,
 		"  % 4d  % 4d %s\n",
 		baseLine,
 		headLine,
-		bytes.TrimRight(line, "\n"),
+		q.styleLine(line, color.Unknown, q.baseTokens, baseLine, nil),
 	)
 	q.printBaseThreads(w, baseLine)
 	q.printHeadThreads(w, headLine)
 }
-func (q *quick) printHeadLine(w io.Writer, num int, line []byte) {
+func (q *quick) printHeadLine(w io.Writer, num int, line []byte, spans []span) {
 	color.Fprintf(w, color.Green,
 		"+       % 4d %s\n",
 		q.headLine,
-		bytes.TrimRight(line, "\n"),
+		q.styleLine(line, color.Green, q.headTokens, num, spans),
 	)
 	q.printHeadThreads(w, num)
 }
-func (q *quick) printBaseLine(w io.Writer, num int, line []byte) {
+func (q *quick) printBaseLine(w io.Writer, num int, line []byte, spans []span) {
 	color.Fprintf(w, color.Red,
 		"- % 4d       %s\n",
 		q.baseLine,
-		bytes.TrimRight(line, "\n"),
+		q.styleLine(line, color.Red, q.baseTokens, num, spans),
 	)
 	q.printBaseThreads(w, num)
 }
 
+// styleLine returns line with num number styled with syntax tokens if
+// highlighting is enabled and emph spans emphasized.
+func (q *quick) styleLine(line []byte, fg color.Color, tokens [][]syntax.Span, num int, emph []span) []byte {
+	line = bytes.TrimRight(line, "\n")
+	if q.theme == nil {
+		// Line is colored entirely by the caller.
+		return styleLine(line, color.Unknown, nil, nil, emph)
+	}
+	var ts []syntax.Span
+	if i := num - 1; 0 <= i && i < len(tokens) {
+		ts = tokens[i]
+	}
+	return styleLine(line, fg, q.theme, ts, emph)
+}
+
 func (q *quick) HasLinesBefore(e *editBuffer, expand int) bool {
 	prev := q.Prev(e)
 	if prev == nil {
@@ -758,12 +1010,23 @@ func (q *quick) expand(w io.Writer, lo, hi, headOffset int) (n int) {
 	return n
 }
 
-func (q *quick) deleteLine(w io.Writer) {
+// readBase reads next base line. Returned line is a copy and is not
+// overwritten by subsequent reads.
+func (q *quick) readBase() []byte {
 	line, err := q.base.ReadLine()
 	if err != nil {
 		panic(err)
 	}
-	q.printBaseLine(w, q.baseLine, line)
+	return append(([]byte)(nil), line...)
+}
+
+func (q *quick) deleteLine(w io.Writer, line []byte, spans []span) {
+	q.printBaseLine(w, q.baseLine, line, spans)
+	q.nextBase()
+}
+
+// nextBase marks current base line as deleted and moves to the next one.
+func (q *quick) nextBase() {
 	q.baseEdits[q.baseLine] = true
 
 	q.baseLine++
@@ -776,17 +1039,27 @@ func (q *quick) advanceHead(n int) {
 	q.headLine++
 }
 
-func (q *quick) insertLines(w io.Writer, cmd ed.Command) {
+func textLines(text []byte) (lines [][]byte) {
 	var line []byte
-	for text := cmd.Text; len(text) > 0; {
+	for len(text) > 0 {
 		line, text = split2(text, '\n')
-		q.printHeadLine(w, q.headLine, line)
-		q.headEdits[q.headLine] = true
-
-		q.headLine++
-		q.headLines++
-		q.headOffset++
+		lines = append(lines, line)
 	}
+	return lines
+}
+
+func (q *quick) insertLine(w io.Writer, line []byte, spans []span) {
+	q.printHeadLine(w, q.headLine, line, spans)
+	q.nextHead()
+}
+
+// nextHead marks current head line as inserted and moves to the next one.
+func (q *quick) nextHead() {
+	q.headEdits[q.headLine] = true
+
+	q.headLine++
+	q.headLines++
+	q.headOffset++
 }
 
 func parseLineRange(s string) (side vcs.Side, lo, hi int, err error) {
@@ -0,0 +1,74 @@
+package rw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+type testComment struct {
+	id     string
+	body   string
+	line   int
+	side   vcs.Side
+	parent *testComment
+	time   time.Time
+}
+
+func (c *testComment) Lines() (lo, hi int)  { return c.line, c.line }
+func (c *testComment) Body() string         { return c.body }
+func (c *testComment) Side() vcs.Side       { return c.side }
+func (c *testComment) CreatedAt() time.Time { return c.time }
+func (c *testComment) UpdatedAt() time.Time { return c.time }
+func (c *testComment) UserLogin() string    { return "user" }
+func (c *testComment) ID() string           { return c.id }
+func (c *testComment) Parent() vcs.Comment {
+	if c.parent == nil {
+		return nil
+	}
+	return c.parent
+}
+
+func TestQuickUpdateDeleteComment(t *testing.T) {
+	var (
+		now = time.Now()
+
+		root = &testComment{id: "1", body: "root", line: 2, side: vcs.SideHead, time: now}
+		rep  = &testComment{id: "2", body: "reply", line: 2, side: vcs.SideHead, parent: root, time: now.Add(time.Second)}
+		lone = &testComment{id: "3", body: "lone", line: 5, side: vcs.SideHead, time: now}
+		orph = &testComment{id: "4", body: "orphan", side: vcs.SideHead, time: now}
+	)
+	q := newQuick(nil, []vcs.Comment{root, rep, lone, orph})
+
+	bodies := func() (ret []string) {
+		for _, th := range append(q.HeadThreadsBetween(1, 10), q.Orphans()...) {
+			for _, c := range th {
+				ret = append(ret, c.Body())
+			}
+		}
+		return ret
+	}
+
+	q.UpdateComment(&testComment{id: "2", body: "edited", line: 2, side: vcs.SideHead, parent: root, time: rep.time})
+	if exp, act := []string{"root", "edited", "lone", "orphan"}, bodies(); !cmp.Equal(act, exp) {
+		t.Fatalf("unexpected bodies after update:\n%s", cmp.Diff(exp, act))
+	}
+
+	q.DeleteComment(lone)
+	q.DeleteComment(orph)
+	if exp, act := []string{"root", "edited"}, bodies(); !cmp.Equal(act, exp) {
+		t.Fatalf("unexpected bodies after delete:\n%s", cmp.Diff(exp, act))
+	}
+	if _, has := q.commentIDs[lone.ID()]; has {
+		t.Errorf("deleted comment still has short id")
+	}
+	q.DeleteComment(root)
+	if act := bodies(); len(act) != 0 {
+		t.Fatalf("replies are left after deleting their parent: %q", act)
+	}
+	if _, has := q.commentIDs[rep.ID()]; has {
+		t.Errorf("deleted reply still has short id")
+	}
+}
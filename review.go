@@ -14,7 +14,6 @@ import (
 	"os"
 	"os/exec"
 	"path"
-	"path/filepath"
 	"sort"
 	"strings"
 	"text/template"
@@ -24,8 +23,10 @@ import (
 
 	"github.com/gobwas/prompt"
 	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/diffutil"
 	"github.com/gobwas/rw/ed"
 	rwioutil "github.com/gobwas/rw/ioutil"
+	"github.com/gobwas/rw/syntax"
 	"github.com/gobwas/rw/vcs"
 )
 
@@ -42,8 +43,11 @@ var (
 	DefaultEditor     = "vimdiff"
 	DefaultEditorArgs Args
 
-	DefaultMode    = ModeQuick
-	DefaultContext = 3
+	DefaultMode          = ModeQuick
+	DefaultContext       = 3
+	DefaultDiffAlgorithm = DiffAlgorithmMyers
+	DefaultLayout        = LayoutUnified
+	DefaultMaxFileSize   = int64(1 << 20)
 
 	TermInfo termInfo
 )
@@ -175,12 +179,119 @@ func (m Mode) String() string {
 	}
 }
 
+// Layout specifies how changes are rendered in quick mode.
+type Layout uint
+
+const (
+	LayoutUnknown Layout = iota
+	LayoutUnified
+	LayoutSplit
+)
+
+func (l *Layout) Set(s string) error {
+	switch s {
+	case "unified":
+		*l = LayoutUnified
+	case "split":
+		*l = LayoutSplit
+	default:
+		return fmt.Errorf("unknown layout: %q", s)
+	}
+	return nil
+}
+
+func (l Layout) String() string {
+	switch l {
+	case LayoutUnified:
+		return "unified"
+	case LayoutSplit:
+		return "split"
+	default:
+		return "<unknown>"
+	}
+}
+
+// DiffAlgorithm specifies how differences between files are computed.
+type DiffAlgorithm uint
+
+const (
+	DiffAlgorithmUnknown DiffAlgorithm = iota
+	DiffAlgorithmMyers
+	DiffAlgorithmPatience
+	DiffAlgorithmHistogram
+
+	// DiffAlgorithmExternal makes rw to use external `diff` tool.
+	DiffAlgorithmExternal
+)
+
+func (a *DiffAlgorithm) Set(s string) error {
+	switch s {
+	case "myers":
+		*a = DiffAlgorithmMyers
+	case "patience":
+		*a = DiffAlgorithmPatience
+	case "histogram":
+		*a = DiffAlgorithmHistogram
+	case "external":
+		*a = DiffAlgorithmExternal
+	default:
+		return fmt.Errorf("unknown diff algorithm: %q", s)
+	}
+	return nil
+}
+
+func (a DiffAlgorithm) String() string {
+	switch a {
+	case DiffAlgorithmMyers:
+		return "myers"
+	case DiffAlgorithmPatience:
+		return "patience"
+	case DiffAlgorithmHistogram:
+		return "histogram"
+	case DiffAlgorithmExternal:
+		return "external"
+	default:
+		return "<unknown>"
+	}
+}
+
 type Review struct {
 	Provider vcs.Provider
 	Mode     Mode
+	Layout   Layout
+	TUI      bool
 	Preview  bool
 	Comments bool
 
+	// ExpandResolved makes quick mode to show resolved threads in full
+	// instead of collapsing them.
+	ExpandResolved bool
+
+	// Progress enables tracking of reviewed files and hunks within CacheDir.
+	Progress bool
+	CacheDir string
+
+	// Interdiff makes quick mode to show only changes made since the last
+	// reviewed head revision. It requires Progress to be enabled.
+	Interdiff bool
+
+	// SplitCommits makes to pick commits of the review and review them one
+	// by one.
+	SplitCommits bool
+
+	// Text makes binary, Git LFS pointer and oversized files to be reviewed
+	// as text. Otherwise only summary of their changes is shown. Files larger
+	// than MaxFileSize bytes are oversized; zero means no limit.
+	Text        bool
+	MaxFileSize int64
+
+	DiffAlgorithm DiffAlgorithm
+
+	// Syntax enables syntax highlighting in quick mode. Theme holds colors
+	// for the highlighted tokens; zero Theme means syntax.DefaultTheme.
+	Syntax bool
+	Theme  syntax.Theme
+
 	ContextBefore int
 	ContextAfter  int
 
@@ -189,6 +300,9 @@ type Review struct {
 
 	Finder     string
 	FinderArgs Args
+
+	Pager     string
+	PagerArgs Args
 }
 
 type termInfo struct {
@@ -304,6 +418,27 @@ func (r *Review) mode() Mode {
 	return DefaultMode
 }
 
+func (r *Review) layout() Layout {
+	if l := r.Layout; l != LayoutUnknown {
+		return l
+	}
+	return DefaultLayout
+}
+
+func (r *Review) diffAlgorithm() DiffAlgorithm {
+	if a := r.DiffAlgorithm; a != DiffAlgorithmUnknown {
+		return a
+	}
+	return DefaultDiffAlgorithm
+}
+
+func (r *Review) theme() *syntax.Theme {
+	if r.Theme != (syntax.Theme{}) {
+		return &r.Theme
+	}
+	return &syntax.DefaultTheme
+}
+
 func (r *Review) editorArgs() Args {
 	if p := r.EditorArgs; p != nil {
 		return p
@@ -311,165 +446,154 @@ func (r *Review) editorArgs() Args {
 	return DefaultEditorArgs
 }
 
-func (r *Review) Start(ctx context.Context) error {
+func (r *Review) Start(ctx context.Context) (err error) {
+	if r.Interdiff && r.mode() != ModeQuick {
+		return fmt.Errorf("interdiff is supported in quick mode only")
+	}
+	if r.Interdiff && r.SplitCommits {
+		return fmt.Errorf("interdiff can't be used along with review of commits")
+	}
 	review, err := r.selectReview(ctx)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		// NOTE: some providers may send pending changes on close.
+		if e := review.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+	if pr, ok := review.(vcs.PendingReview); ok {
+		if err := pr.StartReview(ctx); err != nil {
+			return err
+		}
+	}
+	if r.SplitCommits {
+		err = r.reviewCommits(ctx, review)
+	} else {
+		err = r.review(ctx, review)
+	}
+	if err == errQuit {
+		return nil
+	}
+	return err
+}
+
+// review reviews changes of the review in the configured mode.
+func (r *Review) review(ctx context.Context, review vcs.Review) (err error) {
+	prog, err := r.loadProgress(ctx, review)
+	if err != nil {
+		return err
+	}
+	if r.Interdiff {
+		review, err = r.interdiff(ctx, review, prog)
+		if err != nil {
+			return err
+		}
+	}
 	switch r.mode() {
 	case ModeQuick:
-		return r.reviewQuick(ctx, review)
+		// Quick mode finishes pending review by itself since it can be
+		// finished in the middle of the review.
+		return r.reviewQuick(ctx, review, prog)
 	case ModeDiff:
-		return r.reviewDiff(ctx, review)
+		err = r.reviewDiff(ctx, review, prog)
 	case ModeCheckout:
-		return r.reviewCheckout(ctx, review)
+		err = r.reviewCheckout(ctx, review, prog)
 	default:
 		return fmt.Errorf("unknown review mode")
 	}
-}
-
-func (r *Review) reviewQuick(ctx context.Context, review vcs.Review) (err error) {
-	changedFiles, err := review.ChangedFiles(ctx)
 	if err != nil {
 		return err
 	}
-	xs, err := r.selectMultiple(ctx, "Pick files to review:", changedFiles)
+	if pr, ok := unwrap(review).(vcs.PendingReview); ok {
+		return r.finishReview(ctx, pr)
+	}
+	return nil
+}
+
+func (r *Review) reviewQuick(ctx context.Context, review vcs.Review, prog *progress) (err error) {
+	files, err := r.pickFiles(ctx, review, prog)
 	if err != nil {
 		return err
 	}
-	files := pick(changedFiles, xs...)
 
 	tmp := temp{
 		name: "rw",
 	}
-	for _, file := range files {
-		baseSrc, err := review.BaseFile(ctx, file)
-		if err != nil {
-			return err
-		}
-		headSrc, err := review.HeadFile(ctx, file)
-		if err != nil {
-			return err
-		}
-		roBase, err := tmp.createFile(baseSrc, "base", file, 0444)
-		if err != nil {
-			return err
-		}
-		roHead, err := tmp.createFile(headSrc, "head", file, 0444)
+	if r.TUI && term.IsTerminal(int(os.Stdin.Fd())) {
+		return r.reviewQuickTUI(ctx, review, &tmp, files, prog)
+	}
+	for _, f := range files {
+		file := f.Name
+		q, err := r.loadQuick(ctx, review, &tmp, f, false)
 		if err != nil {
 			return err
 		}
-		var comments []vcs.Comment
-		if r.Comments {
-			comments, err = review.FileComments(ctx, file)
+		if q.summary != nil {
+			basePath, headPath := diffPaths(f)
+			color.Fprintf(os.Stdout, color.White, "index %s..%s\n",
+				shortenRef(review.BaseName()),
+				shortenRef(review.HeadName()),
+			)
+			color.Fprintf(os.Stdout, color.White, "--- %s\n", basePath)
+			color.Fprintf(os.Stdout, color.White, "+++ %s\n", headPath)
+			os.Stdout.Write(q.summary)
+			text, err := prompt.Confirm(ctx, fmt.Sprintf("Review %s as text?", file))
 			if err != nil {
 				return err
 			}
+			if !text {
+				if err := prog.MarkFile(ctx, file, true); err != nil {
+					return err
+				}
+				continue
+			}
+			if q, err = r.loadQuick(ctx, review, &tmp, f, true); err != nil {
+				return err
+			}
 		}
-		var edits []ed.Command
-		err = diff(ctx, roBase.Name(), roHead.Name(), func(cmd ed.Command) {
-			edits = appendEdit(edits, cmd)
-		})
-		if err != nil {
-			return err
+		var skip bool
+		if n, total := r.reviewedHunks(prog, file, q); n > 0 && n < total {
+			skip, err = prompt.Confirm(ctx, fmt.Sprintf(
+				"%d of %d hunk(s) of %s are reviewed. Skip them?", n, total, file,
+			))
+			if err != nil {
+				return err
+			}
 		}
-
-		q := newQuick(roBase, comments)
-		q.Render(edits)
-
 		for {
+			basePath, headPath := diffPaths(f)
 			color.Fprintf(os.Stdout, color.White, "index %s..%s\n",
 				shortenRef(review.BaseName()),
 				shortenRef(review.HeadName()),
 			)
-			color.Fprintf(os.Stdout, color.White, "--- %s\n",
-				filepath.Join("a", file),
-			)
-			color.Fprintf(os.Stdout, color.White, "+++ %s\n",
-				filepath.Join("b", file),
-			)
+			color.Fprintf(os.Stdout, color.White, "--- %s\n", basePath)
+			color.Fprintf(os.Stdout, color.White, "+++ %s\n", headPath)
+			q.PrintOrphans(os.Stdout)
 
 			b := q.Front()
+			if skip {
+				b = prog.SkipHunks(file, q, b, r.contextBefore(), r.contextAfter())
+			}
 			for b != nil {
-				var (
-					lo = b
-					hi = b
-				)
-				for prev, next := b, q.Next(b); next != nil && baseDistance(prev, next) <= r.contextAfter(); {
-					hi = next
-					prev = next
-					next = q.Next(prev)
-				}
-
-			join:
-				var (
-					buffers     []io.WriterTo
-					baseLines   int
-					headLines   int
-					staticLines int
-				)
-				for prev, curr := (*editBuffer)(nil), lo; curr != nil; prev, curr = curr, q.Next(curr) {
-					baseLines += curr.baseLines
-					headLines += curr.headLines
+				h := newHunk(q, b, r.contextBefore(), r.contextAfter())
 
-					if prev != nil {
-						var buf bytes.Buffer
-						staticLines += q.ExpandBetween(&buf, prev, curr)
-						buffers = append(buffers, buffer(buf.Bytes()))
-					}
-					buffers = append(buffers, curr)
-
-					if curr == hi {
-						break
-					}
+			render:
+				var buf bytes.Buffer
+				h.Render(&buf)
+				if prog.HunkReviewed(file, h) {
+					color.Fprintln(&buf, color.Grey, "(reviewed)")
 				}
-				var (
-					before bytes.Buffer
-					after  bytes.Buffer
-
-					contextBefore = r.contextBefore()
-					contextAfter  = r.contextAfter()
-				)
-
-			expand:
-				var (
-					beforeLines = q.ExpandBefore(&before, lo, contextBefore)
-					afterLines  = q.ExpandAfter(&after, hi, contextAfter)
-					expandLines = beforeLines + afterLines
-
-					baseStart = lo.baseStart - beforeLines
-					baseTotal = baseLines + staticLines + expandLines
-					baseStop  = baseStart + baseTotal
-
-					headStart = lo.headStart - beforeLines
-					headTotal = headLines + staticLines + expandLines
-					headStop  = headStart + headTotal
-				)
-				fmt.Fprintf(os.Stdout, "@@ -%d,%d +%d,%d:\n",
-					baseStart, baseTotal,
-					headStart, headTotal,
-				)
-
-				before.WriteTo(os.Stdout)
-				for _, b := range buffers {
-					b.WriteTo(os.Stdout)
+				// Hunk is followed by the separator line and the quiz.
+				reserve := 1 + quizHeight(hunkQuiz(review, prog, file))
+				if err := r.output(ctx, buf.Bytes(), reserve); err != nil {
+					return err
 				}
-				after.WriteTo(os.Stdout)
-
 				color.Println(color.Grey, strings.Repeat("~", 80))
 
 			command:
-				// TODO: move this to r.quiz()
-				quiz := prompt.QuizOptions(
-					"p", "Previous hunk",
-					"n", "Next hunk",
-					"q", "Quit immediately",
-					"c", "Comment changes",
-					"r", "Reply to a comment",
-					"b", "Expand context before hunk",
-					"a", "Expand context after hunk",
-					"d", "Checkout a file and open a diff in an editor",
-				)
+				quiz := hunkQuiz(review, prog, file)
 				p := prompt.Quiz{
 					Message: "What to do with this hunk",
 					Options: quiz,
@@ -486,18 +610,9 @@ func (r *Review) reviewQuick(ctx context.Context, review vcs.Review) (err error)
 				switch quiz[i].Short {
 				case "r":
 					// TODO: move this to r.quiz()
-					var quiz []prompt.Option
-					for _, t := range q.ThreadsBetween(baseStart, baseStop, headStart, headStop) {
-						for _, c := range t {
-							quiz = append(quiz, prompt.Option{
-								Short: q.CommentID(c),
-								Data:  c,
-							})
-						}
-					}
 					s := prompt.Quiz{
 						Message: "Reply to:",
-						Options: quiz,
+						Options: commentOptions(q, append(h.Threads(), q.Orphans()...), nil),
 						//Prompt: prompt.Prompt{
 						//	Output: writerFunc(func(p []byte) (int, error) {
 						//		return color.Write(color.Blue, p)
@@ -518,12 +633,93 @@ func (r *Review) reviewQuick(ctx context.Context, review vcs.Review) (err error)
 						return err
 					}
 					q.AppendComment(rep)
+					h.Rerender()
+					continue
+
+				case "t":
+					s := prompt.Quiz{
+						Message: "Resolve or unresolve thread of:",
+						Options: commentOptions(q, append(h.Threads(), q.Orphans()...), nil),
+					}
+					i, err := s.Single(ctx)
+					if err != nil {
+						return err
+					}
+					c := s.Options[i].Data.(vcs.Comment)
+					if _, err := toggleResolved(ctx, unwrap(review).(vcs.ThreadResolver), c); err != nil {
+						return err
+					}
+					h.Rerender()
+					continue
 
-					for e := lo; e != q.Next(hi); e = q.Next(e) {
-						q.RenderBuffer(e)
+				case "e", "x":
+					var (
+						ce  = unwrap(review).(vcs.CommentEditor)
+						del = quiz[i].Short == "x"
+					)
+					own, err := ownComments(ctx, ce)
+					if err != nil {
+						return err
+					}
+					opts := commentOptions(q, append(h.Threads(), q.Orphans()...), own)
+					if len(opts) == 0 {
+						fmt.Println("No own comments in this hunk.")
+						goto command
+					}
+					s := prompt.Quiz{
+						Message: "Pick a comment:",
+						Options: opts,
+					}
+					i, err := s.Single(ctx)
+					if err != nil {
+						return err
+					}
+					c := s.Options[i].Data.(vcs.Comment)
+					if del {
+						if err := ce.DeleteComment(ctx, c); err != nil {
+							return err
+						}
+						q.DeleteComment(c)
+						h.Rerender()
+						continue
+					}
+					color.Println(color.Grey, c.Body())
+					body, err := prompt.ReadLine(ctx, "> ")
+					if err != nil {
+						return err
+					}
+					upd, err := ce.UpdateComment(ctx, c, body)
+					if err != nil {
+						return err
 					}
+					q.UpdateComment(upd)
+					h.Rerender()
 					continue
 
+				case "s":
+					opts := commentOptions(q, append(h.Threads(), q.Orphans()...), hasSuggestion)
+					if len(opts) == 0 {
+						fmt.Println("No suggestions in this hunk.")
+						goto command
+					}
+					s := prompt.Quiz{
+						Message: "Apply suggestion of:",
+						Options: opts,
+					}
+					i, err := s.Single(ctx)
+					if err != nil {
+						return err
+					}
+					c := s.Options[i].Data.(vcs.Comment)
+					ref, err := applySuggestion(ctx, unwrap(review).(vcs.SuggestionApplier), file, c)
+					if err != nil {
+						// Suggestion may conflict with previously applied ones.
+						fmt.Printf("Can't apply suggestion: %v\n", err)
+						goto command
+					}
+					fmt.Printf("Suggestion committed as %s.\n", color.Sprint(color.White, shortenRef(ref)))
+					goto command
+
 				case "c":
 				readRange:
 					line, err := prompt.ReadLine(ctx, "Line(s) to comment: ")
@@ -541,59 +737,75 @@ func (r *Review) reviewQuick(ctx context.Context, review vcs.Review) (err error)
 					}
 					c, err := review.Comment(ctx, file, side, lineLo, lineHi, line)
 					if err != nil {
-						return err
+						// Lines may be not commentable for the review.
+						fmt.Printf("Can't comment: %v\n", err)
+						goto command
 					}
 					q.AppendComment(c)
-					for e := lo; e != q.Next(hi); e = q.Next(e) {
-						q.RenderBuffer(e)
-					}
+					h.Rerender()
 					continue
 
 				case "b":
-					if !q.HasLinesBefore(lo, beforeLines) {
-						if p := q.Prev(lo); p != nil {
-							lo = p
-							goto join
-						}
-					} else {
-						contextBefore += 5
-					}
-					goto expand
+					h.ExpandBefore()
+					goto render
 
 				case "a":
-					if !q.HasLinesAfter(hi, afterLines) {
-						if n := q.Next(hi); n != nil {
-							hi = n
-							goto join
-						}
-					} else {
-						contextAfter += 5
-					}
-					goto expand
+					h.ExpandAfter()
+					goto render
 
 				case "p":
-					prev := q.Prev(lo)
+					prev := q.Prev(h.lo)
 					if prev != nil {
 						b = prev
 						continue
 					}
 				case "n":
-					next := q.Next(hi)
+					if err := prog.MarkHunk(file, h); err != nil {
+						return err
+					}
+					next := q.Next(h.hi)
+					if skip {
+						next = prog.SkipHunks(file, q, next, r.contextBefore(), r.contextAfter())
+					}
 					if next != nil {
 						b = next
 						continue
 					}
+					if err := prog.MarkFile(ctx, file, true); err != nil {
+						return err
+					}
+
+				case "m":
+					reviewed := !prog.FileReviewed(file)
+					if err := prog.MarkFile(ctx, file, reviewed); err != nil {
+						return err
+					}
+					if reviewed {
+						fmt.Printf("Marked %s as reviewed.\n", color.Sprint(color.White, file))
+					} else {
+						fmt.Printf("Marked %s as not reviewed.\n", color.Sprint(color.White, file))
+					}
+					goto command
 
 				case "d":
 					// Checkout a head file to see a diff in an editor.
-					f := checkoutFileLine(file, headStart)
-					if err := r.checkout(ctx, review, f); err != nil {
+					f := checkoutFileLine(file, h.headStart)
+					if err := r.checkout(ctx, review, nil, f); err != nil {
+						return err
+					}
+					goto command
+
+				case "v":
+					if err := r.viewFile(ctx, review, f); err != nil {
 						return err
 					}
 					goto command
 
+				case "f":
+					return r.finishReview(ctx, unwrap(review).(vcs.PendingReview))
+
 				case "q":
-					return nil
+					return errQuit
 				}
 				fmt.Printf("Reviewed all changes in %s.\n\n", color.Sprint(color.White, file))
 				break
@@ -602,10 +814,225 @@ func (r *Review) reviewQuick(ctx context.Context, review vcs.Review) (err error)
 		}
 
 	}
+	if pr, ok := unwrap(review).(vcs.PendingReview); ok {
+		return r.finishReview(ctx, pr)
+	}
 
 	return nil
 }
 
+// hunkQuiz returns options of the quiz asked after each hunk of the file.
+func hunkQuiz(review vcs.Review, prog *progress, file string) []prompt.Option {
+	quiz := prompt.QuizOptions(
+		"p", "Previous hunk",
+		"n", "Next hunk",
+		"q", "Quit immediately",
+		"c", "Comment changes",
+		"r", replyTitle(review),
+		"b", "Expand context before hunk",
+		"a", "Expand context after hunk",
+		"d", "Checkout a file and open a diff in an editor",
+		"v", "View the whole file with comments",
+	)
+	if _, ok := unwrap(review).(vcs.CommentEditor); ok {
+		quiz = append(quiz, prompt.QuizOptions(
+			"e", "Edit own comment",
+			"x", "Delete own comment",
+		)...)
+	}
+	if _, ok := unwrap(review).(vcs.SuggestionApplier); ok {
+		quiz = append(quiz, prompt.QuizOptions(
+			"s", "Apply a suggestion and commit it",
+		)...)
+	}
+	if _, ok := unwrap(review).(vcs.ThreadResolver); ok {
+		quiz = append(quiz, prompt.QuizOptions(
+			"t", "Resolve or unresolve a thread",
+		)...)
+	}
+	if prog != nil {
+		title := "Mark the file as reviewed"
+		if prog.FileReviewed(file) {
+			title = "Mark the file as not reviewed"
+		}
+		quiz = append(quiz, prompt.QuizOptions(
+			"m", title,
+		)...)
+	}
+	if _, ok := unwrap(review).(vcs.PendingReview); ok {
+		quiz = append(quiz, prompt.QuizOptions(
+			"f", "Finish the review and submit a verdict",
+		)...)
+	}
+	return quiz
+}
+
+// quizHeight returns number of lines printed when asking a quiz with given
+// options: the message, the options and the input line.
+func quizHeight(opts []prompt.Option) int {
+	return len(opts) + 2
+}
+
+// replyTitle returns title of the reply action. It notes that reply is posted
+// as a separate comment if the review has no comment threads.
+func replyTitle(review vcs.Review) string {
+	if q, ok := unwrap(review).(vcs.QuotedReplies); ok && q.QuotedReplies() {
+		return "Reply to a comment (no threads here: posted as a new comment quoting it)"
+	}
+	return "Reply to a comment"
+}
+
+// commentOptions returns quiz options to pick a comment of given threads by
+// its short id. Non-nil filter limits the comments to pick from.
+func commentOptions(q *quick, ts []vcs.Thread, filter func(vcs.Comment) bool) (opts []prompt.Option) {
+	for _, t := range ts {
+		for _, c := range t {
+			if filter != nil && !filter(c) {
+				continue
+			}
+			opts = append(opts, prompt.Option{
+				Short: q.CommentID(c),
+				Data:  c,
+			})
+		}
+	}
+	return opts
+}
+
+// ownComments returns a filter of comments made by the authenticated user.
+func ownComments(ctx context.Context, ce vcs.CommentEditor) (func(vcs.Comment) bool, error) {
+	login, err := ce.UserLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(c vcs.Comment) bool {
+		return c.UserLogin() == login
+	}, nil
+}
+
+// applySuggestion commits the change suggested in comment c.
+func applySuggestion(ctx context.Context, sa vcs.SuggestionApplier, file string, c vcs.Comment) (string, error) {
+	text, ok := suggestionText(c.Body())
+	if !ok {
+		return "", fmt.Errorf("no suggestion in the comment")
+	}
+	return sa.ApplySuggestion(ctx, file, c, text)
+}
+
+// toggleResolved resolves the thread of c if it's not resolved and vice versa.
+// It returns the new resolution state of the thread.
+func toggleResolved(ctx context.Context, tr vcs.ThreadResolver, c vcs.Comment) (resolved bool, err error) {
+	if (vcs.Thread{c}).Resolved() {
+		return false, tr.UnresolveThread(ctx, c)
+	}
+	return true, tr.ResolveThread(ctx, c)
+}
+
+// loadQuick prepares base and head versions of the file and returns quick
+// model with rendered changes between them.
+func (r *Review) loadQuick(ctx context.Context, review vcs.Review, tmp *temp, f vcs.ChangedFile, asText bool) (*quick, error) {
+	file := f.Name
+	var comments []vcs.Comment
+	if r.Comments {
+		var err error
+		comments, err = review.FileComments(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if summary := r.sizeSummary(f); summary != nil && !asText {
+		// Summarized file has no hunks, so its base is never read.
+		q := newQuick(nil, comments)
+		q.summary = summary
+		return q, nil
+	}
+	baseSrc, err := baseFile(ctx, review, f)
+	if err != nil {
+		return nil, err
+	}
+	headSrc, err := headFile(ctx, review, f)
+	if err != nil {
+		return nil, err
+	}
+	roBase, err := tmp.createFile(baseSrc, "base", file, 0444)
+	if err != nil {
+		return nil, err
+	}
+	roHead, err := tmp.createFile(headSrc, "head", file, 0444)
+	if err != nil {
+		return nil, err
+	}
+	if !asText {
+		summary, err := r.fileSummary(f, roBase.Name(), roHead.Name())
+		if err != nil {
+			return nil, err
+		}
+		if summary != nil {
+			q := newQuick(roBase, comments)
+			q.summary = summary
+			return q, nil
+		}
+	}
+	var edits []ed.Command
+	err = r.diff(ctx, roBase.Name(), roHead.Name(), func(cmd ed.Command) {
+		edits = appendEdit(edits, cmd)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := ioutil.ReadFile(roHead.Name())
+	if err != nil {
+		return nil, err
+	}
+	q := newQuick(roBase, comments)
+	q.expandResolved = r.ExpandResolved
+	q.headText = diffutil.Lines(head)
+	if lex := syntax.ForFile(file); r.Syntax && lex != nil {
+		base, err := ioutil.ReadFile(roBase.Name())
+		if err != nil {
+			return nil, err
+		}
+		q.Highlight(lex, r.theme(), base, head)
+	}
+	if r.layout() == LayoutSplit {
+		q.SplitLayout(TermInfo.Width)
+	}
+	q.Render(edits)
+
+	return q, nil
+}
+
+func (r *Review) finishReview(ctx context.Context, review vcs.PendingReview) error {
+	quiz := prompt.QuizOptions(
+		"c", "Comment",
+		"a", "Approve",
+		"r", "Request changes",
+	)
+	p := prompt.Quiz{
+		Message: "Submit the review as",
+		Options: quiz,
+	}
+	i, err := p.Single(ctx)
+	if err != nil {
+		return err
+	}
+	var v vcs.Verdict
+	switch quiz[i].Short {
+	case "c":
+		v = vcs.VerdictComment
+	case "a":
+		v = vcs.VerdictApprove
+	case "r":
+		v = vcs.VerdictRequestChanges
+	}
+	body, err := prompt.ReadLine(ctx, "Summary: ")
+	if err != nil {
+		return err
+	}
+	return review.SubmitReview(ctx, v, body)
+}
+
 func (r *Review) launchEditor(ctx context.Context, info reviewInfo) error {
 	args, err := compileArgs(r.editorArgs(), info)
 	if err != nil {
@@ -912,7 +1339,36 @@ func sanitizePath(s string) string {
 	return strings.Replace(s, string(os.PathSeparator), ".", -1)
 }
 
-func diff(ctx context.Context, prev, next string, fn func(ed.Command)) error {
+// diff computes difference between prev and next files and calls fn with
+// each ed command needed to transform prev into next.
+func (r *Review) diff(ctx context.Context, prev, next string, fn func(ed.Command)) error {
+	var alg diffutil.Algorithm
+	switch r.diffAlgorithm() {
+	case DiffAlgorithmExternal:
+		return diffExternal(ctx, prev, next, fn)
+	case DiffAlgorithmPatience:
+		alg = diffutil.Patience
+	case DiffAlgorithmHistogram:
+		alg = diffutil.Histogram
+	default:
+		alg = diffutil.Myers
+	}
+	a, err := ioutil.ReadFile(prev)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(next)
+	if err != nil {
+		return err
+	}
+	if err := diffutil.Ed(a, b, alg, fn); err != nil {
+		log.Printf("%v; falling back to external diff tool", err)
+		return diffExternal(ctx, prev, next, fn)
+	}
+	return nil
+}
+
+func diffExternal(ctx context.Context, prev, next string, fn func(ed.Command)) error {
 	log.Println("executing", "diff", "--ed", "--text", prev, next)
 	cmd := exec.CommandContext(ctx, "diff", "--ed", "--text", prev, next)
 	pipe, err := cmd.StdoutPipe()
@@ -983,11 +1439,12 @@ func shortenRef(s string) string {
 	return s[:7]
 }
 
+// isHash reports whether s looks like a full or abbreviated commit hash.
 func isHash(s string) bool {
 	const toLower = 'a' - 'A'
 	for i := 0; i < len(s); i++ {
 		c := s[i] | toLower
-		if 'a' <= c && c <= 'z' {
+		if 'a' <= c && c <= 'f' {
 			continue
 		}
 		if '0' <= c && c <= '9' {
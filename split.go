@@ -0,0 +1,189 @@
+package rw
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gobwas/avl"
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/ioutil"
+	"github.com/gobwas/rw/syntax"
+	"github.com/gobwas/rw/vcs"
+)
+
+// splitSeparator separates base and head columns in split layout.
+const splitSeparator = " | "
+
+const (
+	// minColumnWidth is the minimum width of a column in split layout.
+	minColumnWidth = 30
+
+	// cellPrefixWidth is the width of line mark and number printed before
+	// each line in split layout.
+	cellPrefixWidth = 7
+
+	tabWidth = 4
+)
+
+// cell represents a line printed in a column of split layout.
+type cell struct {
+	num    int // Zero num means empty cell.
+	mark   byte
+	line   []byte
+	fg     color.Color
+	tokens [][]syntax.Span
+	emph   []span
+}
+
+// SplitLayout makes q to render base and head side by side within given
+// terminal width. It must be called before Render().
+func (q *quick) SplitLayout(width int) {
+	q.split = true
+	q.columnWidth = (width - len(splitSeparator)) / 2
+	if q.columnWidth < minColumnWidth {
+		q.columnWidth = minColumnWidth
+	}
+}
+
+func (q *quick) splitChange(w io.Writer, base, head [][]byte, baseSpans, headSpans [][]span) {
+	for i := 0; i < len(base) || i < len(head); i++ {
+		var left, right cell
+		if i < len(base) {
+			left = cell{
+				num:    q.baseLine,
+				mark:   '-',
+				line:   base[i],
+				fg:     color.Red,
+				tokens: q.baseTokens,
+				emph:   baseSpans[i],
+			}
+		}
+		if i < len(head) {
+			right = cell{
+				num:    q.headLine,
+				mark:   '+',
+				line:   head[i],
+				fg:     color.Green,
+				tokens: q.headTokens,
+				emph:   headSpans[i],
+			}
+		}
+		q.printRow(w, left, right)
+		if i < len(base) {
+			q.nextBase()
+		}
+		if i < len(head) {
+			q.nextHead()
+		}
+	}
+}
+
+func (q *quick) printSplitLine(w io.Writer, baseLine, headLine int, line []byte) {
+	q.printRow(w,
+		cell{
+			num:    baseLine,
+			mark:   ' ',
+			line:   line,
+			tokens: q.baseTokens,
+		},
+		cell{
+			num:    headLine,
+			mark:   ' ',
+			line:   line,
+			tokens: q.headTokens,
+		},
+	)
+}
+
+// printRow prints left and right cells side by side. Threads started at the
+// cells lines are printed below each cell within its column.
+func (q *quick) printRow(w io.Writer, left, right cell) {
+	writeColumns(w, q.columnWidth, q.formatCell(left), q.formatCell(right))
+
+	var lt, rt bytes.Buffer
+	if left.num > 0 {
+		q.writeColumnThreads(&lt, q.baseThreads, left.num)
+	}
+	if right.num > 0 {
+		q.writeColumnThreads(&rt, q.headThreads, right.num)
+	}
+	if lt.Len() > 0 || rt.Len() > 0 {
+		writeColumns(w, q.columnWidth, lt.Bytes(), rt.Bytes())
+	}
+}
+
+func (q *quick) writeColumnThreads(w io.Writer, tree avl.Tree, line int) {
+	ts, _ := tree.Search(&threadsQuery{
+		startLine: line,
+	}).(*threadList)
+	ts.forEach(func(t vcs.Thread) {
+		q.writeThread(w, t, 2, q.columnWidth)
+	})
+}
+
+// formatCell returns cell contents wrapped to fit the column width.
+func (q *quick) formatCell(c cell) []byte {
+	if c.num == 0 {
+		return nil
+	}
+	var ts []syntax.Span
+	if i := c.num - 1; 0 <= i && i < len(c.tokens) {
+		ts = c.tokens[i]
+	}
+	text := styleLine(bytes.TrimRight(c.line, "\n"), c.fg, q.theme, ts, c.emph)
+	text = expandTabs(text)
+
+	var buf bytes.Buffer
+	lw := ioutil.NewLineWrapWriter(&buf, q.columnWidth-cellPrefixWidth)
+	lw.SetBreakWords(true)
+	lw.SetRuneCounter(visibleWidth)
+	lw.Write(text)
+	lw.Flush()
+
+	lines := color.SplitLines(buf.Bytes())
+	if len(lines) == 0 {
+		lines = [][]byte{nil}
+	}
+	var ret bytes.Buffer
+	for i, line := range lines {
+		if i == 0 {
+			color.Fprintf(&ret, c.fg, "%c % 4d ", c.mark, c.num)
+		} else {
+			ret.WriteString(strings.Repeat(" ", cellPrefixWidth))
+		}
+		ret.Write(line)
+		ret.WriteByte('\n')
+	}
+	return ret.Bytes()
+}
+
+// writeColumns writes lines of left and right side by side. Left lines are
+// padded to the given width.
+func writeColumns(w io.Writer, width int, left, right []byte) {
+	var (
+		ls = color.SplitLines(left)
+		rs = color.SplitLines(right)
+	)
+	for i := 0; i < len(ls) || i < len(rs); i++ {
+		var l, r []byte
+		if i < len(ls) {
+			l = ls[i]
+		}
+		if i < len(rs) {
+			r = rs[i]
+		}
+		w.Write(l)
+		if n := width - visibleWidth(l); n > 0 {
+			io.WriteString(w, strings.Repeat(" ", n))
+		}
+		color.Fprintf(w, color.Grey, splitSeparator)
+		w.Write(r)
+		io.WriteString(w, "\n")
+	}
+}
+
+func visibleWidth(p []byte) int {
+	return utf8.RuneCount(color.Filter(p))
+}
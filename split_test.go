@@ -0,0 +1,26 @@
+package rw
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/color"
+)
+
+func TestWriteColumns(t *testing.T) {
+	var buf bytes.Buffer
+	writeColumns(&buf, 5,
+		[]byte(color.Sprint(color.Red, "ab")+"\nabcde\n"),
+		[]byte("x\n"),
+	)
+	act := color.FilterString(buf.String())
+	exp := strings.Join([]string{
+		"ab   " + splitSeparator + "x",
+		"abcde" + splitSeparator,
+		"",
+	}, "\n")
+	if act != exp {
+		t.Fatalf("unexpected columns:\n%s\nwant:\n%s", act, exp)
+	}
+}
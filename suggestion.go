@@ -0,0 +1,126 @@
+package rw
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/vcs"
+)
+
+const (
+	suggestionOpen  = "```suggestion"
+	suggestionClose = "```"
+)
+
+// bodyPart is a part of comment body which is either a plain text or a
+// suggestion block contents.
+type bodyPart struct {
+	text       string
+	suggestion bool
+}
+
+// splitSuggestions splits comment body into parts separating suggestion
+// blocks from the rest of the text. Unterminated suggestion block is treated
+// as a plain text.
+func splitSuggestions(body string) (parts []bodyPart) {
+	var (
+		text  []string
+		block []string
+		open  bool
+	)
+	flush := func(lines []string, suggestion bool) {
+		if len(lines) == 0 && !suggestion {
+			return
+		}
+		parts = append(parts, bodyPart{
+			text:       strings.Join(lines, "\n"),
+			suggestion: suggestion,
+		})
+	}
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !open && trimmed == suggestionOpen:
+			flush(text, false)
+			text = nil
+			open = true
+		case open && trimmed == suggestionClose:
+			flush(block, true)
+			block = nil
+			open = false
+		case open:
+			block = append(block, line)
+		default:
+			text = append(text, line)
+		}
+	}
+	if open {
+		text = append(text, suggestionOpen)
+		text = append(text, block...)
+	}
+	flush(text, false)
+	return parts
+}
+
+// suggestionText returns text of the first suggestion block of the comment
+// body. Returned text is either empty or ends with a newline.
+func suggestionText(body string) ([]byte, bool) {
+	for _, p := range splitSuggestions(body) {
+		if !p.suggestion {
+			continue
+		}
+		if p.text == "" {
+			return nil, true
+		}
+		return []byte(p.text + "\n"), true
+	}
+	return nil, false
+}
+
+func hasSuggestion(c vcs.Comment) bool {
+	_, ok := suggestionText(c.Body())
+	return ok
+}
+
+// formatBody returns comment body with suggestion blocks rendered as a diff
+// against the commented lines of the head file. The diff is fenced to be kept
+// as is by the markdown renderer.
+func (q *quick) formatBody(c vcs.Comment, body string) string {
+	lo, hi := c.Lines()
+	if c.Side() != vcs.SideHead || lo == 0 || hi > len(q.headText) {
+		return body
+	}
+	parts := splitSuggestions(body)
+	if len(parts) == 1 && !parts[0].suggestion {
+		return body
+	}
+	var sb strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if !p.suggestion {
+			sb.WriteString(p.text)
+			continue
+		}
+		sb.WriteString("```diff\n")
+		for _, line := range q.headText[lo-1 : hi] {
+			line = bytes.TrimRight(line, "\n")
+			sb.WriteString(color.Sprintf(color.Red, "-%s", expandTabs(line)))
+			sb.WriteString("\n")
+		}
+		if p.text != "" {
+			for _, line := range strings.Split(p.text, "\n") {
+				sb.WriteString(color.Sprintf(color.Green, "+%s", expandTabs([]byte(line))))
+				sb.WriteString("\n")
+			}
+		}
+		sb.WriteString("```\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func expandTabs(line []byte) []byte {
+	return bytes.ReplaceAll(line, []byte{'\t'}, bytes.Repeat([]byte{' '}, tabWidth))
+}
@@ -0,0 +1,101 @@
+package rw
+
+import (
+	"testing"
+
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/vcs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitSuggestions(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		body string
+		exp  []bodyPart
+	}{
+		{
+			name: "plain",
+			body: "just a comment",
+			exp: []bodyPart{
+				{text: "just a comment"},
+			},
+		},
+		{
+			name: "suggestion",
+			body: "how about:\n```suggestion\nfoo()\nbar()\n```\nthanks",
+			exp: []bodyPart{
+				{text: "how about:"},
+				{text: "foo()\nbar()", suggestion: true},
+				{text: "thanks"},
+			},
+		},
+		{
+			name: "deletion",
+			body: "```suggestion\n```",
+			exp: []bodyPart{
+				{suggestion: true},
+			},
+		},
+		{
+			name: "unterminated",
+			body: "```suggestion\nfoo()",
+			exp: []bodyPart{
+				{text: "```suggestion\nfoo()"},
+			},
+		},
+		{
+			name: "other block",
+			body: "```go\nfoo()\n```",
+			exp: []bodyPart{
+				{text: "```go\nfoo()\n```"},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			act := splitSuggestions(test.body)
+			if !cmp.Equal(act, test.exp, cmp.AllowUnexported(bodyPart{})) {
+				t.Errorf(
+					"unexpected parts:\n%s",
+					cmp.Diff(test.exp, act, cmp.AllowUnexported(bodyPart{})),
+				)
+			}
+		})
+	}
+}
+
+func TestSuggestionText(t *testing.T) {
+	text, ok := suggestionText("nit:\n```suggestion\n\tfoo()\n```")
+	if !ok {
+		t.Fatalf("no suggestion found")
+	}
+	if act, exp := string(text), "\tfoo()\n"; act != exp {
+		t.Errorf("unexpected text: %q; want %q", act, exp)
+	}
+	if _, ok := suggestionText("lgtm"); ok {
+		t.Errorf("unexpected suggestion found")
+	}
+}
+
+func TestFormatBody(t *testing.T) {
+	q := &quick{
+		headText: [][]byte{
+			[]byte("one\n"),
+			[]byte("two\n"),
+			[]byte("three\n"),
+		},
+	}
+	c := &testComment{
+		line: 2,
+		side: vcs.SideHead,
+	}
+	act := q.formatBody(c, "nit:\n```suggestion\nTWO\n```")
+	exp := "nit:\n" +
+		"```diff\n" +
+		color.Sprintf(color.Red, "-two") + "\n" +
+		color.Sprintf(color.Green, "+TWO") + "\n" +
+		"```"
+	if act != exp {
+		t.Errorf("unexpected body:\n%q\nwant:\n%q", act, exp)
+	}
+}
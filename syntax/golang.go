@@ -0,0 +1,87 @@
+package syntax
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+)
+
+var goTypes = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true,
+	"int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+	"string": true, "uint": true, "uint8": true, "uint16": true,
+	"uint32": true, "uint64": true, "uintptr": true, "any": true,
+}
+
+var goBuiltins = map[string]bool{
+	"append": true, "cap": true, "close": true, "complex": true,
+	"copy": true, "delete": true, "imag": true, "len": true, "make": true,
+	"new": true, "panic": true, "print": true, "println": true,
+	"real": true, "recover": true,
+}
+
+var goLiterals = map[string]bool{
+	"true": true, "false": true, "nil": true, "iota": true,
+}
+
+// LexGo tokenizes Go source code using go/scanner.
+func LexGo(src []byte, fn func(Span)) {
+	var (
+		fset = token.NewFileSet()
+		file = fset.AddFile("", fset.Base(), len(src))
+		s    scanner.Scanner
+	)
+	// Errors are ignored since src may be not a valid Go code.
+	s.Init(file, src, nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		var kind Kind
+		switch {
+		case tok.IsKeyword():
+			kind = Keyword
+		case tok == token.STRING || tok == token.CHAR:
+			kind = String
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			kind = Number
+		case tok == token.COMMENT:
+			kind = Comment
+		case tok == token.IDENT && goTypes[lit]:
+			kind = Type
+		case tok == token.IDENT && goBuiltins[lit]:
+			kind = Builtin
+		case tok == token.IDENT && goLiterals[lit]:
+			kind = Literal
+		default:
+			continue
+		}
+		var (
+			lo = file.Offset(pos)
+			hi = lo + len(lit)
+		)
+		// Raw strings and general comments have carriage returns removed
+		// from the literal, so search for their actual ends in src.
+		switch {
+		case tok == token.STRING && lit[0] == '`':
+			hi = indexEnd(src, lo+1, "`")
+		case tok == token.COMMENT && lit[1] == '*':
+			hi = indexEnd(src, lo+2, "*/")
+		}
+		if hi > len(src) {
+			hi = len(src)
+		}
+		fn(Span{Lo: lo, Hi: hi, Kind: kind})
+	}
+}
+
+// indexEnd returns offset right after the first occurrence of sep in src
+// starting from offset lo. It returns length of src if there is no sep.
+func indexEnd(src []byte, lo int, sep string) int {
+	if i := bytes.Index(src[lo:], []byte(sep)); i != -1 {
+		return lo + i + len(sep)
+	}
+	return len(src)
+}
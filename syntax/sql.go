@@ -0,0 +1,107 @@
+package syntax
+
+import (
+	"bytes"
+	"strings"
+)
+
+var sqlKeywords = wordSet(`
+	add all alter and any as asc begin between by case cascade check column
+	commit constraint create cross database default delete desc distinct drop
+	else end exists explain foreign from full function grant group having if
+	in index inner insert intersect into is join key left like limit not
+	offset on or order outer primary procedure references replace returning
+	revoke right rollback schema select set table then to transaction trigger
+	truncate union unique update using values view when where with
+`)
+
+var sqlTypes = wordSet(`
+	bigint binary bit blob bool boolean char date datetime decimal double
+	float int integer interval json jsonb numeric real serial smallint text
+	time timestamp timestamptz tinyint uuid varbinary varchar
+`)
+
+var sqlLiterals = wordSet(`true false null`)
+
+func wordSet(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+// LexSQL tokenizes SQL statements. Keywords are matched case insensitively.
+func LexSQL(src []byte, fn func(Span)) {
+	for i := 0; i < len(src); {
+		var (
+			c    = src[i]
+			j    = i + 1
+			kind Kind
+		)
+		switch {
+		case c == '-' && j < len(src) && src[j] == '-':
+			if n := bytes.IndexByte(src[j:], '\n'); n != -1 {
+				j += n
+			} else {
+				j = len(src)
+			}
+			kind = Comment
+
+		case c == '/' && j < len(src) && src[j] == '*':
+			j = indexEnd(src, j+1, "*/")
+			kind = Comment
+
+		case c == '\'':
+			// Quotes inside of string are escaped by doubling them.
+			for j < len(src) {
+				if src[j] == '\'' {
+					if j+1 < len(src) && src[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			kind = String
+
+		case c == '"' || c == '`':
+			// Quoted identifiers.
+			j = indexEnd(src, j, string(c))
+
+		case isDigit(c):
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			kind = Number
+
+		case isWordStart(c):
+			for j < len(src) && (isWordStart(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			w := strings.ToLower(string(src[i:j]))
+			switch {
+			case sqlKeywords[w]:
+				kind = Keyword
+			case sqlTypes[w]:
+				kind = Type
+			case sqlLiterals[w]:
+				kind = Literal
+			}
+		}
+		if kind != Plain {
+			fn(Span{Lo: i, Hi: j, Kind: kind})
+		}
+		i = j
+	}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
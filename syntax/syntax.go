@@ -0,0 +1,162 @@
+// Package syntax implements simple lexers used to highlight source code.
+package syntax
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/rw/color"
+)
+
+// Kind represents a kind of a lexical token.
+type Kind uint8
+
+const (
+	Plain Kind = iota
+	Keyword
+	Type
+	String
+	Number
+	Comment
+	Literal
+	Key
+	Builtin
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Plain:
+		return "plain"
+	case Keyword:
+		return "keyword"
+	case Type:
+		return "type"
+	case String:
+		return "string"
+	case Number:
+		return "number"
+	case Comment:
+		return "comment"
+	case Literal:
+		return "literal"
+	case Key:
+		return "key"
+	case Builtin:
+		return "builtin"
+	default:
+		return "<unknown>"
+	}
+}
+
+// Span represents a token of given kind at byte range [Lo, Hi).
+type Span struct {
+	Lo, Hi int
+	Kind   Kind
+}
+
+// Lexer tokenizes src and calls fn with each recognized token. Tokens must be
+// passed in order of their appearance in src. Plain tokens may be omitted.
+type Lexer func(src []byte, fn func(Span))
+
+// ForFile returns lexer suitable for the file with given name. It returns nil
+// if file type is not supported.
+func ForFile(name string) Lexer {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".go":
+		return LexGo
+	case ".yaml", ".yml":
+		return LexYAML
+	case ".sql":
+		return LexSQL
+	default:
+		return nil
+	}
+}
+
+// Lines tokenizes src with lex and returns tokens of each line of src. Tokens
+// spanning multiple lines are split into per line parts. Offsets of the
+// returned spans are relative to the beginning of the line.
+func Lines(src []byte, lex Lexer) [][]Span {
+	var (
+		ret   = make([][]Span, bytes.Count(src, []byte{'\n'})+1)
+		line  int
+		start int // Offset of the line beginning.
+	)
+	lex(src, func(s Span) {
+		for s.Lo < s.Hi {
+			for {
+				i := bytes.IndexByte(src[start:], '\n')
+				if i == -1 || start+i >= s.Lo {
+					break
+				}
+				start += i + 1
+				line++
+			}
+			end := s.Hi
+			if i := bytes.IndexByte(src[s.Lo:s.Hi], '\n'); i != -1 {
+				end = s.Lo + i
+			}
+			if s.Kind != Plain && s.Lo < end {
+				ret[line] = append(ret[line], Span{
+					Lo:   s.Lo - start,
+					Hi:   end - start,
+					Kind: s.Kind,
+				})
+			}
+			if end == s.Hi {
+				break
+			}
+			s.Lo = end + 1
+		}
+	})
+	return ret
+}
+
+// Theme holds colors used to highlight tokens of each kind. Unknown color
+// means that token is not highlighted.
+type Theme struct {
+	Keyword color.Color
+	Type    color.Color
+	String  color.Color
+	Number  color.Color
+	Comment color.Color
+	Literal color.Color
+	Key     color.Color
+	Builtin color.Color
+}
+
+var DefaultTheme = Theme{
+	Keyword: color.Blue,
+	Type:    color.Cyan,
+	String:  color.Yellow,
+	Number:  color.Magenta,
+	Comment: color.Grey,
+	Literal: color.Magenta,
+	Key:     color.Cyan,
+	Builtin: color.Unknown,
+}
+
+// Color returns color for the token of kind k.
+func (t *Theme) Color(k Kind) color.Color {
+	switch k {
+	case Keyword:
+		return t.Keyword
+	case Type:
+		return t.Type
+	case String:
+		return t.String
+	case Number:
+		return t.Number
+	case Comment:
+		return t.Comment
+	case Literal:
+		return t.Literal
+	case Key:
+		return t.Key
+	case Builtin:
+		return t.Builtin
+	default:
+		return color.Unknown
+	}
+}
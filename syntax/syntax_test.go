@@ -0,0 +1,127 @@
+package syntax
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type lexeme struct {
+	Kind Kind
+	Text string
+}
+
+func TestLexers(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		lex  Lexer
+		src  string
+		exp  []lexeme
+	}{
+		{
+			name: "go",
+			lex:  LexGo,
+			src:  "func f(s string) bool {\n\treturn len(s) != 0 // Check.\n}\n",
+			exp: []lexeme{
+				{Keyword, "func"},
+				{Type, "string"},
+				{Type, "bool"},
+				{Keyword, "return"},
+				{Builtin, "len"},
+				{Number, "0"},
+				{Comment, "// Check."},
+			},
+		},
+		{
+			name: "go raw string with cr",
+			lex:  LexGo,
+			src:  "x := `a\r\nb` + 1",
+			exp: []lexeme{
+				{String, "`a\r\nb`"},
+				{Number, "1"},
+			},
+		},
+		{
+			name: "yaml",
+			lex:  LexYAML,
+			src: "---\n" +
+				"name: \"rw\" # Name.\n" +
+				"items:\n" +
+				"  - debug: true\n" +
+				"    count: 42\n" +
+				"text: |\n" +
+				"  key: value\n" +
+				"other: &a plain\n",
+			exp: []lexeme{
+				{Keyword, "---"},
+				{Key, "name"},
+				{String, `"rw"`},
+				{Comment, "# Name."},
+				{Key, "items"},
+				{Key, "debug"},
+				{Literal, "true"},
+				{Key, "count"},
+				{Number, "42"},
+				{Key, "text"},
+				{String, "key: value"},
+				{Key, "other"},
+				{Type, "&a"},
+			},
+		},
+		{
+			name: "sql",
+			lex:  LexSQL,
+			src:  "SELECT id, 'it''s' FROM t -- All.\nWHERE x = NULL AND n > 10;",
+			exp: []lexeme{
+				{Keyword, "SELECT"},
+				{String, "'it''s'"},
+				{Keyword, "FROM"},
+				{Comment, "-- All."},
+				{Keyword, "WHERE"},
+				{Literal, "NULL"},
+				{Keyword, "AND"},
+				{Number, "10"},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var act []lexeme
+			test.lex([]byte(test.src), func(s Span) {
+				act = append(act, lexeme{s.Kind, test.src[s.Lo:s.Hi]})
+			})
+			if !cmp.Equal(act, test.exp) {
+				t.Errorf("unexpected tokens:\n%s", cmp.Diff(test.exp, act))
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	src := []byte("a := 1 /* multi\nline */ + 2\n")
+	act := Lines(src, LexGo)
+	exp := [][]Span{
+		{
+			{Lo: 5, Hi: 6, Kind: Number},
+			{Lo: 7, Hi: 15, Kind: Comment},
+		},
+		{
+			{Lo: 0, Hi: 7, Kind: Comment},
+			{Lo: 10, Hi: 11, Kind: Number},
+		},
+		nil,
+	}
+	if !cmp.Equal(act, exp) {
+		t.Errorf("unexpected lines:\n%s", cmp.Diff(exp, act))
+	}
+}
+
+func TestForFile(t *testing.T) {
+	for _, name := range []string{"main.go", "a/b/config.YML", "schema.sql"} {
+		if ForFile(name) == nil {
+			t.Errorf("no lexer for %q", name)
+		}
+	}
+	if ForFile("README") != nil {
+		t.Errorf("unexpected lexer for README")
+	}
+}
@@ -0,0 +1,148 @@
+package syntax
+
+import (
+	"bytes"
+	"regexp"
+)
+
+var (
+	yamlKey    = regexp.MustCompile(`^(?:[^\s#'"\-?:,\[\]{}][^#:]*?|-[^\s#:][^#:]*?|"[^"]*"|'[^']*')\s*:(?:\s|$)`)
+	yamlNumber = regexp.MustCompile(`^[-+]?(?:\d[\d_]*(?:\.\d*)?(?:[eE][-+]?\d+)?|\.\d+|0x[0-9a-fA-F]+|0o[0-7]+|\.inf|\.nan)$`)
+)
+
+var yamlLiterals = map[string]bool{
+	"true": true, "false": true, "True": true, "False": true,
+	"TRUE": true, "FALSE": true, "yes": true, "no": true,
+	"null": true, "Null": true, "NULL": true, "~": true,
+}
+
+// LexYAML tokenizes YAML documents. It works line by line and recognizes
+// mapping keys, scalars, comments and block scalars.
+func LexYAML(src []byte, fn func(Span)) {
+	var (
+		offset int
+		// block is an indentation of the line which started a block scalar;
+		// it is -1 if there is no block scalar.
+		block = -1
+	)
+	for len(src) > 0 {
+		line, rest := split(src)
+		indent := len(line) - len(bytes.TrimLeft(line, " \t"))
+		trimmed := bytes.TrimRight(line, " \t\r")
+
+		switch {
+		case block != -1 && (indent > block || len(trimmed) == 0):
+			if indent < len(trimmed) {
+				fn(Span{Lo: offset + indent, Hi: offset + len(trimmed), Kind: String})
+			}
+
+		case bytes.Equal(trimmed, []byte("---")) || bytes.Equal(trimmed, []byte("...")):
+			block = -1
+			fn(Span{Lo: offset, Hi: offset + 3, Kind: Keyword})
+
+		default:
+			block = -1
+			if lexYAMLLine(line, indent, offset, fn) {
+				block = indent
+			}
+		}
+		offset += len(line) + 1
+		src = rest
+	}
+}
+
+// lexYAMLLine lexes a single line and reports whether the line starts a
+// block scalar.
+func lexYAMLLine(line []byte, i, offset int, fn func(Span)) (block bool) {
+	// Skip sequence entry indicators.
+	for i+1 < len(line) && line[i] == '-' && (line[i+1] == ' ' || line[i+1] == '\t') {
+		i += 2
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+	}
+	if m := yamlKey.FindIndex(line[i:]); m != nil {
+		n := bytes.LastIndexByte(line[i:i+m[1]], ':')
+		key := bytes.TrimRight(line[i:i+n], " \t")
+		fn(Span{Lo: offset + i, Hi: offset + i + len(key), Kind: Key})
+		i += m[1]
+	}
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	if i == len(line) {
+		return false
+	}
+	switch c := line[i]; c {
+	case '#':
+		fn(Span{Lo: offset + i, Hi: offset + len(line), Kind: Comment})
+		return false
+
+	case '"', '\'':
+		j := i + 1
+		for j < len(line) {
+			if line[j] == '\\' && c == '"' {
+				j += 2
+				continue
+			}
+			if line[j] == c {
+				j++
+				break
+			}
+			j++
+		}
+		if j > len(line) {
+			j = len(line)
+		}
+		fn(Span{Lo: offset + i, Hi: offset + j, Kind: String})
+		i = j
+
+	case '|', '>':
+		block = true
+		i++
+
+	case '&', '*', '!':
+		j := i
+		for j < len(line) && line[j] != ' ' && line[j] != '\t' {
+			j++
+		}
+		fn(Span{Lo: offset + i, Hi: offset + j, Kind: Type})
+		return lexYAMLLine(line, j, offset, fn)
+	}
+	// Find the comment which must be separated from the value by space.
+	end := len(line)
+	for j := i; j < len(line); j++ {
+		if line[j] == '#' && (j == 0 || line[j-1] == ' ' || line[j-1] == '\t') {
+			fn(Span{Lo: offset + j, Hi: offset + len(line), Kind: Comment})
+			end = j
+			break
+		}
+	}
+	if block {
+		return block
+	}
+	value := bytes.TrimSpace(line[i:end])
+	if len(value) == 0 {
+		return false
+	}
+	var kind Kind
+	switch {
+	case yamlLiterals[string(value)]:
+		kind = Literal
+	case yamlNumber.Match(value):
+		kind = Number
+	default:
+		return false
+	}
+	lo := i + bytes.Index(line[i:end], value)
+	fn(Span{Lo: offset + lo, Hi: offset + lo + len(value), Kind: kind})
+	return false
+}
+
+func split(p []byte) (line, rest []byte) {
+	i := bytes.IndexByte(p, '\n')
+	if i == -1 {
+		return p, nil
+	}
+	return p[:i], p[i+1:]
+}
@@ -0,0 +1,918 @@
+package rw
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/gobwas/rw/color"
+	"github.com/gobwas/rw/vcs"
+)
+
+// tuiPanelLines is the maximum number of input lines shown in the panel.
+const tuiPanelLines = 8
+
+// tuiKeys describes keys shown in the status bar. Keys with non-nil available
+// are shown only if it returns true.
+var tuiKeys = []struct {
+	keys      string
+	title     string
+	available func(t *tui) bool
+}{
+	{"n/p", "hunk", nil},
+	{"N/P", "file", nil},
+	{"j/k", "scroll", nil},
+	{"c", "comment", nil},
+	{"r", "reply", nil},
+	{"b/a", "expand", nil},
+	{"d", "diff", nil},
+	{"v", "view", nil},
+	{"T", "as text", func(t *tui) bool {
+		return t.q.summary != nil
+	}},
+	{"e/x", "edit/delete", func(t *tui) bool {
+		_, ok := unwrap(t.review).(vcs.CommentEditor)
+		return ok
+	}},
+	{"s", "apply", func(t *tui) bool {
+		_, ok := unwrap(t.review).(vcs.SuggestionApplier)
+		return ok
+	}},
+	{"t", "resolve", func(t *tui) bool {
+		_, ok := unwrap(t.review).(vcs.ThreadResolver)
+		return ok
+	}},
+	{"m", "mark reviewed", func(t *tui) bool {
+		return t.prog != nil
+	}},
+	{"f", "finish", func(t *tui) bool {
+		_, ok := unwrap(t.review).(vcs.PendingReview)
+		return ok
+	}},
+	{"q", "quit", nil},
+}
+
+// tui implements full-screen interactive interface for quick mode. It
+// switches terminal into raw mode and reacts on single keystrokes.
+type tui struct {
+	r      *Review
+	review vcs.Review
+	tmp    *temp
+	files  []vcs.ChangedFile
+	prog   *progress
+
+	fd    int
+	state *term.State
+	in    *bufio.Reader
+	out   *bufio.Writer
+
+	fileIndex int
+	q         *quick
+	starts    []*editBuffer // Buffers starting hunks of the current file.
+	hunkIndex int
+	hunk      *hunk
+	skip      bool // Skip reviewed hunks of the current file.
+
+	lines  [][]byte // Rendered lines of the current hunk.
+	scroll int
+	page   int // Number of content lines fit the screen.
+
+	status string
+	panel  *panel
+	done   bool
+	quit   bool
+}
+
+// panel represents an input area shown at the bottom of the screen.
+type panel struct {
+	title string
+	input []rune
+
+	// multiline panel inserts newline on Enter and is submitted on Ctrl-D.
+	multiline bool
+	// choice panel is submitted immediately on any key press.
+	choice bool
+
+	submit func(ctx context.Context, text string) error
+}
+
+func (r *Review) reviewQuickTUI(ctx context.Context, review vcs.Review, tmp *temp, files []vcs.ChangedFile, prog *progress) (err error) {
+	t := &tui{
+		r:      r,
+		review: review,
+		tmp:    tmp,
+		files:  files,
+		prog:   prog,
+
+		fd:  int(os.Stdin.Fd()),
+		in:  bufio.NewReader(os.Stdin),
+		out: bufio.NewWriter(os.Stdout),
+	}
+	if err := t.enter(); err != nil {
+		return err
+	}
+	defer func() {
+		if e := t.leave(); e != nil && err == nil {
+			err = e
+		}
+	}()
+	if len(files) == 0 {
+		return nil
+	}
+	if err := t.openFile(ctx, 0, false); err != nil {
+		return err
+	}
+	for !t.done {
+		if err := t.draw(); err != nil {
+			return err
+		}
+		k, err := readKey(t.in)
+		if err != nil {
+			return err
+		}
+		if err := t.handle(ctx, k); err != nil {
+			return err
+		}
+	}
+	if t.quit {
+		return errQuit
+	}
+	return nil
+}
+
+// enter switches terminal to raw mode and alternate screen.
+func (t *tui) enter() (err error) {
+	t.state, err = term.MakeRaw(t.fd)
+	if err != nil {
+		return err
+	}
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	return t.out.Flush()
+}
+
+// leave restores terminal state changed by enter().
+func (t *tui) leave() error {
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	if err := t.out.Flush(); err != nil {
+		return err
+	}
+	return term.Restore(t.fd, t.state)
+}
+
+func (t *tui) file() string {
+	return t.files[t.fileIndex].Name
+}
+
+func (t *tui) openFile(ctx context.Context, i int, last bool) (err error) {
+	return t.loadFile(ctx, i, last, false)
+}
+
+// loadFile opens i-th file. Files which can't be reviewed as text are shown
+// as a summary unless asText is true.
+func (t *tui) loadFile(ctx context.Context, i int, last, asText bool) (err error) {
+	t.fileIndex = i
+	t.q, err = t.r.loadQuick(ctx, t.review, t.tmp, t.files[i], asText)
+	if err != nil {
+		return err
+	}
+	t.starts = hunkStarts(t.q, t.r.contextBefore(), t.r.contextAfter())
+	t.skip = false
+	if last && len(t.starts) > 0 {
+		t.openHunk(len(t.starts) - 1)
+		return nil
+	}
+	t.openHunk(0)
+	if n, total := t.r.reviewedHunks(t.prog, t.file(), t.q); n > 0 && n < total {
+		t.skipPanel(n, total)
+	}
+	return nil
+}
+
+// skipPanel asks whether to skip reviewed hunks of the current file.
+func (t *tui) skipPanel(n, total int) {
+	t.panel = &panel{
+		title:  fmt.Sprintf("%d of %d hunk(s) are reviewed. Skip them? [y/n]", n, total),
+		choice: true,
+		submit: func(ctx context.Context, k string) error {
+			if k != "y" {
+				return nil
+			}
+			t.skip = true
+			for i := range t.starts {
+				if !t.reviewed(i) {
+					t.openHunk(i)
+					break
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// reviewed reports whether i-th hunk of the current file is reviewed.
+func (t *tui) reviewed(i int) bool {
+	h := newHunk(t.q, t.starts[i], t.r.contextBefore(), t.r.contextAfter())
+	return t.prog.HunkReviewed(t.file(), h)
+}
+
+func (t *tui) openHunk(i int) {
+	t.hunkIndex = i
+	t.hunk = nil
+	if i < len(t.starts) {
+		t.hunk = newHunk(t.q, t.starts[i], t.r.contextBefore(), t.r.contextAfter())
+	}
+	t.scroll = 0
+	t.renderHunk()
+}
+
+func (t *tui) renderHunk() {
+	var buf bytes.Buffer
+	if t.hunkIndex == 0 {
+		// Show orphaned threads at the top of the file.
+		t.q.PrintOrphans(&buf)
+	}
+	if t.hunk != nil {
+		t.hunk.Render(&buf)
+		if t.prog.HunkReviewed(t.file(), t.hunk) {
+			color.Fprintln(&buf, color.Grey, "(reviewed)")
+		}
+	} else if t.q.summary != nil {
+		buf.Write(t.q.summary)
+		color.Fprintln(&buf, color.Grey, "(press T to review as text)")
+	} else {
+		buf.WriteString("No changes.\n")
+	}
+	t.lines = color.SplitLines(buf.Bytes())
+}
+
+func (t *tui) nextHunk(ctx context.Context) error {
+	if t.hunk != nil {
+		if err := t.prog.MarkHunk(t.file(), t.hunk); err != nil {
+			return err
+		}
+		for i := t.hunkIndex + 1; i < len(t.starts); i++ {
+			// Skip hunks joined to the current one.
+			if t.starts[i].baseStart <= t.hunk.hi.baseStart {
+				continue
+			}
+			if t.skip && t.reviewed(i) {
+				continue
+			}
+			t.openHunk(i)
+			return nil
+		}
+	}
+	if err := t.prog.MarkFile(ctx, t.file(), true); err != nil {
+		return err
+	}
+	return t.nextFile(ctx)
+}
+
+func (t *tui) prevHunk(ctx context.Context) error {
+	if t.hunk != nil {
+		for i := t.hunkIndex - 1; i >= 0; i-- {
+			if t.starts[i].baseStart < t.hunk.lo.baseStart {
+				t.openHunk(i)
+				return nil
+			}
+		}
+	}
+	if t.fileIndex == 0 {
+		t.status = "This is the first hunk."
+		return nil
+	}
+	return t.openFile(ctx, t.fileIndex-1, true)
+}
+
+func (t *tui) nextFile(ctx context.Context) error {
+	if t.fileIndex == len(t.files)-1 {
+		t.status = "Reviewed all changes."
+		return nil
+	}
+	return t.openFile(ctx, t.fileIndex+1, false)
+}
+
+func (t *tui) prevFile(ctx context.Context) error {
+	if t.fileIndex == 0 {
+		t.status = "This is the first file."
+		return nil
+	}
+	return t.openFile(ctx, t.fileIndex-1, false)
+}
+
+func (t *tui) handle(ctx context.Context, k string) error {
+	if t.panel != nil {
+		return t.handlePanel(ctx, k)
+	}
+	t.status = ""
+	switch k {
+	case "j", "down":
+		t.scroll++
+	case "k", "up":
+		t.scroll--
+	case " ", "pgdown":
+		t.scroll += t.page
+	case "pgup":
+		t.scroll -= t.page
+	case "g", "home":
+		t.scroll = 0
+	case "G", "end":
+		t.scroll = len(t.lines)
+
+	case "n":
+		return t.nextHunk(ctx)
+	case "p":
+		return t.prevHunk(ctx)
+	case "T":
+		if t.q.summary != nil {
+			return t.loadFile(ctx, t.fileIndex, false, true)
+		}
+	case "N":
+		return t.nextFile(ctx)
+	case "P":
+		return t.prevFile(ctx)
+
+	case "b":
+		if t.hunk != nil {
+			t.hunk.ExpandBefore()
+			t.renderHunk()
+		}
+	case "a":
+		if t.hunk != nil {
+			t.hunk.ExpandAfter()
+			t.renderHunk()
+		}
+
+	case "c":
+		t.commentPanel()
+	case "r":
+		t.replyPanel()
+	case "t":
+		if tr, ok := unwrap(t.review).(vcs.ThreadResolver); ok {
+			t.resolvePanel(tr)
+		}
+	case "s":
+		if sa, ok := unwrap(t.review).(vcs.SuggestionApplier); ok {
+			t.suggestionPanel(sa)
+		}
+	case "e":
+		if ce, ok := unwrap(t.review).(vcs.CommentEditor); ok {
+			t.editPanel(ctx, ce)
+		}
+	case "x":
+		if ce, ok := unwrap(t.review).(vcs.CommentEditor); ok {
+			t.deletePanel(ctx, ce)
+		}
+	case "d":
+		if t.hunk == nil {
+			return nil
+		}
+		return t.suspend(func() error {
+			f := checkoutFileLine(t.file(), t.hunk.headStart)
+			return t.r.checkout(ctx, t.review, nil, f)
+		})
+	case "v":
+		return t.suspend(func() error {
+			return t.r.viewFile(ctx, t.review, t.files[t.fileIndex])
+		})
+	case "m":
+		if t.prog == nil {
+			return nil
+		}
+		reviewed := !t.prog.FileReviewed(t.file())
+		if err := t.prog.MarkFile(ctx, t.file(), reviewed); err != nil {
+			return err
+		}
+		t.renderHunk()
+		if reviewed {
+			t.status = "File marked as reviewed."
+		} else {
+			t.status = "File marked as not reviewed."
+		}
+	case "f":
+		if pr, ok := unwrap(t.review).(vcs.PendingReview); ok {
+			t.finishPanel(pr)
+		}
+
+	case "q":
+		t.done = true
+	case "ctrl-c":
+		// Unlike "q", it doesn't continue with the next review, if any.
+		t.done = true
+		t.quit = true
+	}
+	return nil
+}
+
+func (t *tui) handlePanel(ctx context.Context, k string) error {
+	p := t.panel
+	if p.choice && k != "esc" && k != "ctrl-c" {
+		t.panel = nil
+		return p.submit(ctx, k)
+	}
+	switch k {
+	case "esc", "ctrl-c":
+		t.panel = nil
+		t.status = "Cancelled."
+	case "backspace":
+		if n := len(p.input); n > 0 {
+			p.input = p.input[:n-1]
+		}
+	case "enter":
+		if p.multiline {
+			p.input = append(p.input, '\n')
+			return nil
+		}
+		t.panel = nil
+		return p.submit(ctx, string(p.input))
+	case "ctrl-d":
+		t.panel = nil
+		return p.submit(ctx, string(p.input))
+	default:
+		if r, n := utf8.DecodeRuneInString(k); n == len(k) && r != utf8.RuneError {
+			p.input = append(p.input, r)
+		}
+	}
+	return nil
+}
+
+func (t *tui) commentPanel() {
+	if t.hunk == nil {
+		return
+	}
+	var p *panel
+	p = &panel{
+		title: "Line(s) to comment, e.g. +12 or -3:5 (Enter to continue, Esc to cancel):",
+		submit: func(ctx context.Context, text string) error {
+			side, lo, hi, err := parseLineRange(strings.TrimSpace(text))
+			if err != nil {
+				t.status = fmt.Sprintf("Bad input: %v", err)
+				t.panel = p
+				return nil
+			}
+			t.panel = &panel{
+				title:     fmt.Sprintf("Comment on %s (Ctrl-D to send, Esc to cancel):", text),
+				multiline: true,
+				submit: func(ctx context.Context, body string) error {
+					c, err := t.review.Comment(ctx, t.file(), side, lo, hi, body)
+					if err != nil {
+						// Lines may be not commentable for the review.
+						t.fail("Can't comment", err)
+						return nil
+					}
+					t.q.AppendComment(c)
+					t.hunk.Rerender()
+					t.renderHunk()
+					t.status = "Comment added."
+					return nil
+				},
+			}
+			return nil
+		},
+	}
+	t.panel = p
+}
+
+func (t *tui) replyPanel() {
+	t.pickComment("Reply to", "No comments to reply to.", nil, func(ctx context.Context, id string, c vcs.Comment) error {
+		t.panel = &panel{
+			title:     fmt.Sprintf("%s [%s] (Ctrl-D to send, Esc to cancel):", replyTitle(t.review), id),
+			multiline: true,
+			submit: func(ctx context.Context, body string) error {
+				rep, err := t.review.ReplyTo(ctx, c, body)
+				if err != nil {
+					t.fail("Can't reply", err)
+					return nil
+				}
+				t.q.AppendComment(rep)
+				t.rerender()
+				t.status = "Reply added."
+				return nil
+			},
+		}
+		return nil
+	})
+}
+
+func (t *tui) resolvePanel(tr vcs.ThreadResolver) {
+	t.pickComment("Resolve or unresolve thread of", "No threads to resolve.", nil, func(ctx context.Context, id string, c vcs.Comment) error {
+		resolved, err := toggleResolved(ctx, tr, c)
+		if err != nil {
+			t.fail("Can't resolve thread", err)
+			return nil
+		}
+		t.rerender()
+		if resolved {
+			t.status = fmt.Sprintf("Thread of [%s] resolved.", id)
+		} else {
+			t.status = fmt.Sprintf("Thread of [%s] unresolved.", id)
+		}
+		return nil
+	})
+}
+
+func (t *tui) suggestionPanel(sa vcs.SuggestionApplier) {
+	t.pickComment("Apply suggestion of", "No suggestions to apply.", hasSuggestion, func(ctx context.Context, id string, c vcs.Comment) error {
+		ref, err := applySuggestion(ctx, sa, t.file(), c)
+		if err != nil {
+			// Suggestion may conflict with previously applied ones.
+			t.fail("Can't apply suggestion", err)
+			return nil
+		}
+		t.status = fmt.Sprintf("Suggestion [%s] committed as %s.", id, shortenRef(ref))
+		return nil
+	})
+}
+
+func (t *tui) editPanel(ctx context.Context, ce vcs.CommentEditor) {
+	own, err := ownComments(ctx, ce)
+	if err != nil {
+		t.fail("Can't get own comments", err)
+		return
+	}
+	t.pickComment("Edit", "No own comments to edit.", own, func(ctx context.Context, id string, c vcs.Comment) error {
+		t.panel = &panel{
+			title:     fmt.Sprintf("Edit [%s] (Ctrl-D to save, Esc to cancel):", id),
+			input:     []rune(c.Body()),
+			multiline: true,
+			submit: func(ctx context.Context, body string) error {
+				upd, err := ce.UpdateComment(ctx, c, body)
+				if err != nil {
+					t.fail("Can't update comment", err)
+					return nil
+				}
+				t.q.UpdateComment(upd)
+				t.rerender()
+				t.status = "Comment updated."
+				return nil
+			},
+		}
+		return nil
+	})
+}
+
+func (t *tui) deletePanel(ctx context.Context, ce vcs.CommentEditor) {
+	own, err := ownComments(ctx, ce)
+	if err != nil {
+		t.fail("Can't get own comments", err)
+		return
+	}
+	t.pickComment("Delete", "No own comments to delete.", own, func(ctx context.Context, id string, c vcs.Comment) error {
+		if err := ce.DeleteComment(ctx, c); err != nil {
+			t.fail("Can't delete comment", err)
+			return nil
+		}
+		t.q.DeleteComment(c)
+		t.rerender()
+		t.status = fmt.Sprintf("Comment [%s] deleted.", id)
+		return nil
+	})
+}
+
+// pickComment opens a panel to pick a comment of the current hunk or an
+// orphaned thread by its short id. Non-nil filter limits the comments to pick
+// from. Picked comment is passed to fn.
+func (t *tui) pickComment(title, empty string, filter func(vcs.Comment) bool, fn func(context.Context, string, vcs.Comment) error) {
+	var (
+		ids      []string
+		comments = make(map[string]vcs.Comment)
+		threads  = t.q.Orphans()
+	)
+	if t.hunk != nil {
+		threads = append(t.hunk.Threads(), threads...)
+	}
+	for _, th := range threads {
+		for _, c := range th {
+			if filter != nil && !filter(c) {
+				continue
+			}
+			id := t.q.CommentID(c)
+			ids = append(ids, id)
+			comments[id] = c
+		}
+	}
+	if len(ids) == 0 {
+		t.status = empty
+		return
+	}
+	var p *panel
+	p = &panel{
+		title: title + " (" + strings.Join(ids, ", ") + "):",
+		submit: func(ctx context.Context, text string) error {
+			id := strings.Trim(strings.TrimSpace(text), "[]#")
+			c, has := comments[id]
+			if !has {
+				t.status = fmt.Sprintf("No such comment: %q", id)
+				t.panel = p
+				return nil
+			}
+			return fn(ctx, id, c)
+		},
+	}
+	t.panel = p
+}
+
+// fail shows err in the status bar. Only the first line of err is shown
+// since the status bar is a single line; git errors are not.
+func (t *tui) fail(what string, err error) {
+	msg := strings.SplitN(err.Error(), "\n", 2)[0]
+	t.status = fmt.Sprintf("%s: %s", what, msg)
+}
+
+// rerender renders the current hunk again, for example, to show changed
+// threads.
+func (t *tui) rerender() {
+	if t.hunk != nil {
+		t.hunk.Rerender()
+	}
+	t.renderHunk()
+}
+
+func (t *tui) finishPanel(pr vcs.PendingReview) {
+	t.panel = &panel{
+		title:  "Submit the review as: [c]omment, [a]pprove, [r]equest changes",
+		choice: true,
+		submit: func(ctx context.Context, k string) error {
+			var v vcs.Verdict
+			switch k {
+			case "c":
+				v = vcs.VerdictComment
+			case "a":
+				v = vcs.VerdictApprove
+			case "r":
+				v = vcs.VerdictRequestChanges
+			default:
+				t.status = "Cancelled."
+				return nil
+			}
+			t.panel = &panel{
+				title:     fmt.Sprintf("Summary of the %s review (Ctrl-D to submit):", v),
+				multiline: true,
+				submit: func(ctx context.Context, body string) error {
+					if err := pr.SubmitReview(ctx, v, body); err != nil {
+						// Submission can be retried since sent comments
+						// are not sent again.
+						t.fail("Can't submit review", err)
+						return nil
+					}
+					t.done = true
+					return nil
+				},
+			}
+			return nil
+		},
+	}
+}
+
+// suspend temporarily restores terminal state to run fn.
+func (t *tui) suspend(fn func() error) error {
+	if err := t.leave(); err != nil {
+		return err
+	}
+	err := fn()
+	if e := t.enter(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+func (t *tui) draw() error {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = TermInfo.Width, TermInfo.Height
+	}
+	var panel [][]byte
+	if t.panel != nil {
+		panel = t.panel.render(width)
+	}
+	// One line for the header and one for the status bar.
+	t.page = height - 2 - len(panel)
+	if t.page < 1 {
+		t.page = 1
+	}
+	if last := len(t.lines) - t.page; t.scroll > last {
+		t.scroll = last
+	}
+	if t.scroll < 0 {
+		t.scroll = 0
+	}
+
+	f := t.files[t.fileIndex]
+	header := color.Sprintf(color.White,
+		"%s (%s..%s)",
+		fileTitle(f),
+		shortenRef(t.review.BaseName()),
+		shortenRef(t.review.HeadName()),
+	)
+	if f.Status != vcs.FileStatusModified {
+		header += color.Sprint(color.Grey, " "+f.Status.String())
+	}
+	if t.prog.FileReviewed(t.file()) {
+		header += color.Sprint(color.Grey, " reviewed")
+	}
+	rows := make([][]byte, 0, height)
+	rows = append(rows, []byte(header))
+	for i := 0; i < t.page; i++ {
+		var line []byte
+		if j := t.scroll + i; j < len(t.lines) {
+			line = t.lines[j]
+		}
+		rows = append(rows, line)
+	}
+	rows = append(rows, panel...)
+	rows = append(rows, t.statusBar(width))
+
+	t.out.WriteString("\x1b[H")
+	for i, row := range rows {
+		if i > 0 {
+			t.out.WriteString("\r\n")
+		}
+		t.out.WriteString("\x1b[2K")
+		t.out.Write(truncateLine(row, width))
+	}
+	return t.out.Flush()
+}
+
+func (t *tui) statusBar(width int) []byte {
+	hunks := len(t.starts)
+	var position string
+	switch {
+	case len(t.lines) <= t.page:
+		position = "all"
+	case t.scroll == 0:
+		position = "top"
+	case t.scroll+t.page >= len(t.lines):
+		position = "bot"
+	default:
+		position = fmt.Sprintf("%d%%", 100*(t.scroll+t.page)/len(t.lines))
+	}
+	text := t.status
+	if text == "" {
+		text = t.help()
+	}
+	s := fmt.Sprintf(" file %d/%d | hunk %d/%d | %s | %s",
+		t.fileIndex+1, len(t.files),
+		t.hunkIndex+1, hunks,
+		position,
+		text,
+	)
+	if hunks == 0 {
+		s = fmt.Sprintf(" file %d/%d | no hunks | %s", t.fileIndex+1, len(t.files), text)
+	}
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		s += strings.Repeat(" ", n)
+	}
+	var buf bytes.Buffer
+	color.Fattr(&buf, color.Reverse)
+	buf.WriteString(s)
+	color.FresetAttr(&buf, color.Reverse)
+	return buf.Bytes()
+}
+
+// help returns description of keys available for the current file.
+func (t *tui) help() string {
+	var keys []string
+	for _, k := range tuiKeys {
+		if k.available == nil || k.available(t) {
+			keys = append(keys, k.keys+" "+k.title)
+		}
+	}
+	return strings.Join(keys, "  ")
+}
+
+func (p *panel) render(width int) [][]byte {
+	rows := [][]byte{
+		[]byte(color.Sprint(color.Grey, strings.Repeat("-", width))),
+		[]byte(color.Sprint(color.Yellow, p.title)),
+	}
+	if p.choice {
+		return rows
+	}
+	var buf bytes.Buffer
+	buf.WriteString("> ")
+	buf.WriteString(strings.ReplaceAll(string(p.input), "\n", "\n  "))
+	color.Fattr(&buf, color.Reverse)
+	buf.WriteByte(' ')
+	color.FresetAttr(&buf, color.Reverse)
+
+	lines := bytes.Split(buf.Bytes(), []byte{'\n'})
+	if n := len(lines); n > tuiPanelLines {
+		lines = lines[n-tuiPanelLines:]
+	}
+	return append(rows, lines...)
+}
+
+// truncateLine returns line which fits the given screen width. Tabs are
+// expanded and color sequences are kept intact.
+func truncateLine(line []byte, width int) []byte {
+	var (
+		buf     bytes.Buffer
+		n       int
+		escaped bool
+	)
+	for len(line) > 0 {
+		if line[0] == '\x1b' {
+			if j := bytes.IndexByte(line, 'm'); j != -1 {
+				buf.Write(line[:j+1])
+				line = line[j+1:]
+				escaped = true
+				continue
+			}
+		}
+		r, size := utf8.DecodeRune(line)
+		line = line[size:]
+		switch r {
+		case '\r':
+			continue
+		case '\t':
+			for i := 0; i < tabWidth && n < width; i++ {
+				buf.WriteByte(' ')
+				n++
+			}
+			continue
+		}
+		if n == width {
+			break
+		}
+		buf.WriteRune(r)
+		n++
+	}
+	if escaped {
+		color.Freset(&buf)
+	}
+	return buf.Bytes()
+}
+
+// readKey reads a single key press from r. Special keys are returned by
+// their names such as "up", "enter" or "ctrl-c"; other keys are returned as
+// is.
+func readKey(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case '\x1b':
+		if r.Buffered() == 0 {
+			return "esc", nil
+		}
+		// Bytes following bare escape belong to the next key press.
+		if p, _ := r.Peek(1); p[0] != '[' && p[0] != 'O' {
+			return "esc", nil
+		}
+		r.ReadByte()
+		var seq []byte
+		for {
+			c, err := r.ReadByte()
+			if err != nil {
+				return "", err
+			}
+			seq = append(seq, c)
+			if 0x40 <= c && c <= 0x7e {
+				break
+			}
+		}
+		switch string(seq) {
+		case "A":
+			return "up", nil
+		case "B":
+			return "down", nil
+		case "C":
+			return "right", nil
+		case "D":
+			return "left", nil
+		case "H", "1~":
+			return "home", nil
+		case "F", "4~":
+			return "end", nil
+		case "5~":
+			return "pgup", nil
+		case "6~":
+			return "pgdown", nil
+		default:
+			return "esc", nil
+		}
+	case '\r', '\n':
+		return "enter", nil
+	case 127, '\b':
+		return "backspace", nil
+	case 3:
+		return "ctrl-c", nil
+	case 4:
+		return "ctrl-d", nil
+	}
+	if err := r.UnreadByte(); err != nil {
+		return "", err
+	}
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	return string(c), nil
+}
@@ -0,0 +1,75 @@
+package rw
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/rw/color"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader(
+		"j\x1b[A\x1b[6~\r\x7fя\x03\x04\x1bq",
+	))
+	var act []string
+	for {
+		k, err := readKey(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		act = append(act, k)
+	}
+	exp := []string{
+		"j", "up", "pgdown", "enter", "backspace", "я", "ctrl-c", "ctrl-d", "esc", "q",
+	}
+	if !cmp.Equal(act, exp) {
+		t.Fatalf("unexpected keys:\n%s", cmp.Diff(exp, act))
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		in    string
+		width int
+		exp   string
+	}{
+		{
+			name:  "short",
+			in:    "abc",
+			width: 5,
+			exp:   "abc",
+		},
+		{
+			name:  "long",
+			in:    "abcdef",
+			width: 3,
+			exp:   "abc",
+		},
+		{
+			name:  "tab",
+			in:    "\tabc",
+			width: 6,
+			exp:   "    ab",
+		},
+		{
+			name:  "colored",
+			in:    color.Sprint(color.Red, "abcdef"),
+			width: 2,
+			exp:   "ab",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			act := string(truncateLine([]byte(test.in), test.width))
+			if filtered := color.FilterString(act); filtered != test.exp {
+				t.Fatalf("unexpected line: %q; want %q", filtered, test.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package vcs
+
+import (
+	"context"
+	"sync"
+)
+
+// CommentCache fetches review comments in background once and holds them
+// grouped by file name. Zero value is ready to use.
+type CommentCache struct {
+	once   sync.Once
+	done   chan struct{}
+	err    error
+	m      map[string][]Comment
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (c *CommentCache) init() (first bool) {
+	c.once.Do(func() {
+		first = true
+		c.done = make(chan struct{})
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+	})
+	return
+}
+
+// Fetch starts fetching comments in background. Only the first call has an
+// effect; subsequent calls are no-op. The fetch function returns comments
+// grouped by file name.
+func (c *CommentCache) Fetch(fetch func(ctx context.Context) (map[string][]Comment, error)) {
+	if !c.init() {
+		return
+	}
+	go func() {
+		defer close(c.done)
+		c.m, c.err = fetch(c.ctx)
+	}()
+}
+
+// File waits for fetching to complete and returns comments of the file. It
+// returns error if fetching failed.
+func (c *CommentCache) File(ctx context.Context, file string) ([]Comment, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		return c.m[file], c.err
+	}
+}
+
+// Remove deletes comment x of the file from the fetched comments. It must be
+// called only after File() returned.
+func (c *CommentCache) Remove(file string, x Comment) {
+	cs := c.m[file]
+	for i, y := range cs {
+		if y == x {
+			c.m[file] = append(cs[:i:i], cs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close cancels fetching, if any, and waits for it to complete.
+func (c *CommentCache) Close() error {
+	var dummy bool
+	c.once.Do(func() {
+		dummy = true
+	})
+	if !dummy {
+		c.cancel()
+		<-c.done
+	}
+	return nil
+}
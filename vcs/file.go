@@ -0,0 +1,69 @@
+package vcs
+
+// FileStatus describes how a file was changed by a review.
+type FileStatus uint8
+
+const (
+	FileStatusModified FileStatus = iota
+	FileStatusAdded
+	FileStatusDeleted
+	FileStatusRenamed
+	FileStatusCopied
+)
+
+func (s FileStatus) String() string {
+	switch s {
+	case FileStatusModified:
+		return "modified"
+	case FileStatusAdded:
+		return "added"
+	case FileStatusDeleted:
+		return "deleted"
+	case FileStatusRenamed:
+		return "renamed"
+	case FileStatusCopied:
+		return "copied"
+	default:
+		return "<unknown>"
+	}
+}
+
+// ChangedFile describes a file changed by a review.
+type ChangedFile struct {
+	// Name is the path of the file at head. Deleted file has its base path.
+	Name string
+
+	// OldName is the path of the file at base if it was renamed or copied.
+	OldName string
+
+	Status FileStatus
+	Binary bool
+
+	// Added and Deleted hold numbers of added and deleted lines. They are
+	// zero for binary files.
+	Added   int
+	Deleted int
+
+	// BaseSize and HeadSize hold sizes of the base and head versions in
+	// bytes. They are zero if the version doesn't exist or the size is not
+	// known in advance.
+	BaseSize int64
+	HeadSize int64
+}
+
+// BaseName returns the path of the file at base.
+func (f ChangedFile) BaseName() string {
+	if f.OldName != "" {
+		return f.OldName
+	}
+	return f.Name
+}
+
+// FileNames returns head paths of the files.
+func FileNames(fs []ChangedFile) []string {
+	ret := make([]string, len(fs))
+	for i, f := range fs {
+		ret[i] = f.Name
+	}
+	return ret
+}
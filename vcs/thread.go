@@ -28,6 +28,15 @@ func (t Thread) Side() Side {
 	return t[0].Side()
 }
 
+// Resolved reports whether the thread is resolved.
+func (t Thread) Resolved() bool {
+	if len(t) == 0 {
+		return false
+	}
+	r, ok := t[0].(ResolvableComment)
+	return ok && r.Resolved()
+}
+
 func BuildThreads(cs []Comment) (ts []Thread) {
 	index := make(map[string]Thread)
 	for _, c := range cs {
@@ -21,7 +21,7 @@ type ReviewItem interface {
 type Review interface {
 	ReviewItem
 
-	ChangedFiles(context.Context) ([]string, error)
+	ChangedFiles(context.Context) ([]ChangedFile, error)
 	FileComments(context.Context, string) ([]Comment, error)
 
 	Checkout(context.Context) (workDir string, cleanup func() error, err error)
@@ -41,6 +41,41 @@ type Review interface {
 	Close() error
 }
 
+// PendingReview is an optional interface of Review which accumulates comments
+// into a pending review and publishes them all at once.
+type PendingReview interface {
+	// StartReview starts a pending review. Comments created after this call
+	// are not published until SubmitReview() is called. Comments which are
+	// not submitted when the review is closed stay unpublished.
+	StartReview(context.Context) error
+
+	// SubmitReview publishes pending comments along with the verdict and
+	// summary body.
+	SubmitReview(ctx context.Context, v Verdict, body string) error
+}
+
+// Verdict is a final decision of the review.
+type Verdict uint8
+
+const (
+	VerdictComment Verdict = iota
+	VerdictApprove
+	VerdictRequestChanges
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictComment:
+		return "comment"
+	case VerdictApprove:
+		return "approve"
+	case VerdictRequestChanges:
+		return "request changes"
+	default:
+		return "???"
+	}
+}
+
 type Side uint8
 
 const (
@@ -70,3 +105,93 @@ type Comment interface {
 	Parent() Comment
 	ID() string
 }
+
+// OutdatedComment is an optional interface of Comment which reports whether
+// the comment was made on a previous version of the review. Lines of such
+// comment are relocated onto the current version; zero lines mean that the
+// comment can't be relocated.
+type OutdatedComment interface {
+	Outdated() bool
+}
+
+// ResolvableComment is an optional interface of Comment which reports whether
+// the thread the comment belongs to is resolved.
+type ResolvableComment interface {
+	Resolved() bool
+}
+
+// ThreadResolver is an optional interface of Review which changes resolution
+// state of the thread the given comment belongs to.
+type ThreadResolver interface {
+	ResolveThread(context.Context, Comment) error
+	UnresolveThread(context.Context, Comment) error
+}
+
+// QuotedReplies is an optional interface of Review which reports that the
+// review has no comment threads. Replies are posted as separate comments
+// quoting their parent.
+type QuotedReplies interface {
+	QuotedReplies() bool
+}
+
+// CommentEditor is an optional interface of Review which changes or deletes
+// comments of the authenticated user.
+type CommentEditor interface {
+	// UserLogin returns login of the authenticated user.
+	UserLogin(context.Context) (string, error)
+
+	UpdateComment(ctx context.Context, c Comment, body string) (Comment, error)
+	DeleteComment(ctx context.Context, c Comment) error
+}
+
+// SuggestionApplier is an optional interface of Review which commits changes
+// suggested in comments.
+type SuggestionApplier interface {
+	// ApplySuggestion replaces the commented lines of the head version of
+	// the file with text and commits the change. It returns name of the
+	// created commit.
+	ApplySuggestion(ctx context.Context, file string, c Comment, text []byte) (string, error)
+}
+
+// HeadRevision is an optional interface of Review which reports the commit
+// hash of the head version under review when HeadName() is not a hash.
+type HeadRevision interface {
+	HeadRevision(context.Context) (string, error)
+}
+
+// FileViewer is an optional interface of Review which tracks files marked as
+// viewed by the authenticated user.
+type FileViewer interface {
+	// ViewedFiles returns files currently marked as viewed.
+	ViewedFiles(context.Context) ([]string, error)
+
+	SetViewed(ctx context.Context, file string, viewed bool) error
+}
+
+// Interdiffer is an optional interface of Review which shows changes made
+// to the review since the given head revision.
+type Interdiffer interface {
+	Interdiff(ctx context.Context, rev string) (Interdiff, error)
+}
+
+// Interdiff holds changes made to the review since a previous head revision.
+// Changes brought by updates of the base are excluded: base version of a file
+// is its previous head version rebased onto the current base.
+type Interdiff interface {
+	ChangedFiles(context.Context) ([]ChangedFile, error)
+	BaseFile(context.Context, string) (io.ReadCloser, error)
+	BaseName() string
+}
+
+// CommitLister is an optional interface of Review which splits it into reviews
+// of its individual commits. Comments made on a commit review are published
+// on the original review at the corresponding lines of its head version.
+type CommitLister interface {
+	Commits(context.Context) ([]Review, error)
+}
+
+// IsOutdated reports whether c is an outdated comment.
+func IsOutdated(c Comment) bool {
+	o, ok := c.(OutdatedComment)
+	return ok && o.Outdated()
+}